@@ -0,0 +1,241 @@
+/*
+Package captest provides utilities to assert the lifecycle events of a
+supervision tree in tests, without relying on time delays.
+
+Example:
+
+	func TestMyTree(t *testing.T) {
+	  events := captest.NewEventCollector(t, 1*time.Second)
+
+	  sup, err := cap.NewSupervisorSpec(
+	    "root",
+	    cap.WithNodes(myWorker),
+	    cap.WithNotifier(events.Notify),
+	  ).Start(context.TODO())
+	  if err != nil {
+	    t.Fatal(err)
+	  }
+
+	  events.AssertEventsMatch(
+	    captest.WorkerStarted("root/my-worker"),
+	    captest.SupervisorStarted("root"),
+	  )
+	}
+*/
+package captest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/internal/s"
+	"github.com/capatazlib/go-capataz/internal/stest"
+	"github.com/capatazlib/go-capataz/smtest"
+)
+
+// EventPredicate represents a predicate function that asserts properties of an
+// Event signaled by the supervision system
+type EventPredicate = smtest.EventP[cap.Event]
+
+// EventCollector accumulates the events of a supervision tree, and allows tests
+// to wait until the accumulated events match a sequence of predicates.
+//
+// Use NewEventCollector to create values of this type.
+type EventCollector struct {
+	t       testing.TB
+	timeout time.Duration
+
+	mu      sync.Mutex
+	events  []cap.Event
+	changed chan struct{}
+}
+
+// NewEventCollector creates an EventCollector that reports assertion failures
+// on the given test. The assertions wait at most the given timeout for the
+// expected events to happen.
+func NewEventCollector(t testing.TB, timeout time.Duration) *EventCollector {
+	return &EventCollector{
+		t:       t,
+		timeout: timeout,
+		changed: make(chan struct{}),
+	}
+}
+
+// Notify stores the given event; this method is a cap.EventNotifier, use it
+// with the cap.WithNotifier option.
+func (ec *EventCollector) Notify(ev cap.Event) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.events = append(ec.events, ev)
+	// wake up the goroutines waiting for new events
+	close(ec.changed)
+	ec.changed = make(chan struct{})
+}
+
+// Events returns the events collected so far
+func (ec *EventCollector) Events() []cap.Event {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	return append([]cap.Event{}, ec.events...)
+}
+
+// snapshot returns the events collected so far, and a channel that gets closed
+// when a new event is collected
+func (ec *EventCollector) snapshot() ([]cap.Event, <-chan struct{}) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	return append([]cap.Event{}, ec.events...), ec.changed
+}
+
+// AssertEventsMatch waits until the collected events match one to one (and in
+// order) the given predicates. The assertion fails when more events than
+// predicates are collected, or when the events do not match after the timeout
+// of the collector.
+//
+// It returns true when the events match.
+func (ec *EventCollector) AssertEventsMatch(preds ...EventPredicate) bool {
+	ec.t.Helper()
+
+	timer := time.NewTimer(ec.timeout)
+	defer timer.Stop()
+
+	for {
+		events, changed := ec.snapshot()
+		err := verifyMatch(preds, events)
+		if err == nil {
+			return true
+		}
+		// once we have more events than predicates, waiting is pointless
+		if len(events) >= len(preds) {
+			ec.t.Error(err)
+			return false
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			ec.t.Errorf("timed out after %v waiting for events: %v", ec.timeout, err)
+			return false
+		}
+	}
+}
+
+// WaitForFailure blocks until a node with the given spec name (e.g.
+// "my-worker") reports a failure on the given collector, and it returns the
+// error of the failure. The spec name matches the last segment of the runtime
+// name of the node, at any level of the supervision tree; a runtime name (e.g.
+// "root/my-worker") may be given as well to match a single node. Only the
+// failures collected after this call are considered. It returns the error of
+// the given context when the context is done before the node fails.
+//
+// The segments of runtime names are split with the default separator; when
+// the tree uses a different one (see cap.WithNameSeparator), the runtime name
+// of the node must be given.
+func WaitForFailure(
+	ctx context.Context,
+	collector *EventCollector,
+	specName string,
+) (error, error) {
+	events, changed := collector.snapshot()
+	seen := len(events)
+
+	for {
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		events, changed = collector.snapshot()
+		for _, ev := range events[seen:] {
+			if ev.GetTag() == cap.ProcessFailed && matchesName(ev, specName) {
+				return ev.Err(), nil
+			}
+		}
+		seen = len(events)
+	}
+}
+
+// matchesName indicates if the given event was emitted by a node with the
+// given spec name, or with the given runtime name
+func matchesName(ev cap.Event, name string) bool {
+	runtimeName := ev.GetProcessRuntimeName()
+	return runtimeName == name || strings.HasSuffix(runtimeName, s.NodeSepToken+name)
+}
+
+// verifyMatch checks the given predicates match one to one the given events
+func verifyMatch(preds []EventPredicate, events []cap.Event) error {
+	for i, pred := range preds {
+		if i >= len(events) {
+			return fmt.Errorf(
+				"expected %d events, got %d\nevents:\n%s",
+				len(preds), len(events), renderEvents(events),
+			)
+		}
+		if !pred.Call(events[i]) {
+			return fmt.Errorf(
+				"entry %d did not match\ncriteria: %s\nevent: %s\nevents:\n%s",
+				i, pred.String(), events[i].String(), renderEvents(events),
+			)
+		}
+	}
+	if len(events) > len(preds) {
+		return fmt.Errorf(
+			"expected %d events, got %d\nevents:\n%s",
+			len(preds), len(events), renderEvents(events),
+		)
+	}
+	return nil
+}
+
+func renderEvents(events []cap.Event) string {
+	var builder strings.Builder
+	for i, ev := range events {
+		builder.WriteString(fmt.Sprintf("  %3d: %s\n", i, ev))
+	}
+	return builder.String()
+}
+
+// SupervisorStarted is a predicate to assert an event represents a supervisor
+// that got started
+var SupervisorStarted = stest.SupervisorStarted
+
+// SupervisorFailed is a predicate to assert an event represents a supervisor
+// that failed
+var SupervisorFailed = stest.SupervisorFailed
+
+// SupervisorTerminated is a predicate to assert an event represents a
+// supervisor that got terminated
+var SupervisorTerminated = stest.SupervisorTerminated
+
+// SupervisorStartFailed is a predicate to assert an event represents a
+// supervisor that failed to start
+var SupervisorStartFailed = stest.SupervisorStartFailed
+
+// WorkerStarted is a predicate to assert an event represents a worker that got
+// started
+var WorkerStarted = stest.WorkerStarted
+
+// WorkerFailed is a predicate to assert an event represents a worker that
+// failed
+var WorkerFailed = stest.WorkerFailed
+
+// WorkerFailedWith is a predicate to assert an event represents a worker that
+// failed with the given error message
+var WorkerFailedWith = stest.WorkerFailedWith
+
+// WorkerCompleted is a predicate to assert an event represents a worker that
+// completed without errors
+var WorkerCompleted = stest.WorkerCompleted
+
+// WorkerTerminated is a predicate to assert an event represents a worker that
+// got terminated by its supervisor
+var WorkerTerminated = stest.WorkerTerminated
+
+// WorkerStartFailed is a predicate to assert an event represents a worker that
+// failed to start
+var WorkerStartFailed = stest.WorkerStartFailed
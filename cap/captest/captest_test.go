@@ -0,0 +1,192 @@
+package captest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/captest"
+)
+
+// recordT is a testing.TB that records the reported errors instead of failing
+// the test
+type recordT struct {
+	testing.TB
+	errors []string
+}
+
+func (rt *recordT) Helper() {}
+
+func (rt *recordT) Error(args ...interface{}) {
+	rt.errors = append(rt.errors, fmt.Sprint(args...))
+}
+
+func (rt *recordT) Errorf(format string, args ...interface{}) {
+	rt.errors = append(rt.errors, fmt.Sprintf(format, args...))
+}
+
+func waitDoneWorker(name string) cap.Node {
+	return cap.NewWorker(name, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestAssertEventsMatch(t *testing.T) {
+	events := captest.NewEventCollector(t, time.Second)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(waitDoneWorker("child1"), waitDoneWorker("child2")),
+		cap.WithNotifier(events.Notify),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// the assertion waits for the termination events
+	go func() {
+		_ = sup.Terminate()
+	}()
+
+	assert.True(t, events.AssertEventsMatch(
+		captest.WorkerStarted("root/child1"),
+		captest.WorkerStarted("root/child2"),
+		captest.SupervisorStarted("root"),
+		captest.WorkerTerminated("root/child2"),
+		captest.WorkerTerminated("root/child1"),
+		captest.SupervisorTerminated("root"),
+	))
+}
+
+func TestAssertEventsMatchTimeout(t *testing.T) {
+	rt := &recordT{TB: t}
+	events := captest.NewEventCollector(rt, 10*time.Millisecond)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(waitDoneWorker("child1")),
+		cap.WithNotifier(events.Notify),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	defer sup.Terminate()
+
+	assert.False(t, events.AssertEventsMatch(
+		captest.WorkerStarted("root/child1"),
+		captest.SupervisorStarted("root"),
+		captest.WorkerFailed("root/child1"),
+	))
+	if assert.Len(t, rt.errors, 1) {
+		assert.Contains(t, rt.errors[0], "timed out after 10ms waiting for events")
+	}
+}
+
+func TestAssertEventsMatchMismatch(t *testing.T) {
+	rt := &recordT{TB: t}
+	events := captest.NewEventCollector(rt, time.Second)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(waitDoneWorker("child1")),
+		cap.WithNotifier(events.Notify),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	defer sup.Terminate()
+
+	// the assertion fails without waiting for the timeout
+	assert.False(t, events.AssertEventsMatch(
+		captest.WorkerStarted("root/child2"),
+		captest.SupervisorStarted("root"),
+	))
+	if assert.Len(t, rt.errors, 1) {
+		assert.Contains(t, rt.errors[0], "entry 0 did not match")
+	}
+}
+
+func TestWaitForFailure(t *testing.T) {
+	events := captest.NewEventCollector(t, time.Second)
+	failCh := make(chan struct{})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(
+			waitDoneWorker("child1"),
+			cap.NewWorker("child2", func(ctx context.Context) error {
+				select {
+				case <-failCh:
+					return errors.New("child2 failed")
+				case <-ctx.Done():
+					return nil
+				}
+			}),
+		),
+		cap.WithNotifier(events.Notify),
+		cap.WithRestartTolerance(100, time.Minute),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	defer sup.Terminate()
+
+	// child2 fails until the wait is over, so that a failure happens after
+	// the wait starts
+	keepFailing := func() func() {
+		waitDone := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case failCh <- struct{}{}:
+				case <-waitDone:
+					return
+				}
+			}
+		}()
+		return func() { close(waitDone) }
+	}
+
+	stopFailing := keepFailing()
+	childErr, err := captest.WaitForFailure(context.TODO(), events, "child2")
+	stopFailing()
+	assert.NoError(t, err)
+	assert.EqualError(t, childErr, "child2 failed")
+
+	// the runtime name of the node matches as well
+	stopFailing = keepFailing()
+	childErr, err = captest.WaitForFailure(context.TODO(), events, "root/child2")
+	stopFailing()
+	assert.NoError(t, err)
+	assert.EqualError(t, childErr, "child2 failed")
+
+	// the context expires, as child1 doesn't fail
+	ctx, cancelFn := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancelFn()
+	childErr, err = captest.WaitForFailure(ctx, events, "child1")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.NoError(t, childErr)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := captest.NewFakeClock(start)
+
+	afterCh := clock.After(time.Minute)
+	sleepDone := make(chan struct{})
+	go func() {
+		clock.Sleep(2 * time.Minute)
+		close(sleepDone)
+	}()
+	clock.BlockUntil(2)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), <-afterCh)
+	select {
+	case <-sleepDone:
+		t.Fatal("sleep finished before its duration elapsed")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	<-sleepDone
+	assert.Equal(t, start.Add(2*time.Minute), clock.Now())
+}
@@ -0,0 +1,104 @@
+package captest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/capatazlib/go-capataz/cap"
+)
+
+// fakeTimer is a pending After or Sleep call of a FakeClock
+type fakeTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// FakeClock is a cap.Clock which time only moves forward when the Advance
+// method is called. Use it with the cap.WithClock option to test the time
+// based logic of a supervisor (e.g. the restart tolerance window)
+// deterministically.
+//
+// Use NewFakeClock to create values of this type.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	changed chan struct{}
+}
+
+var _ cap.Clock = &FakeClock{}
+
+// NewFakeClock creates a FakeClock that starts at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the current time of the clock
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// After returns a channel that receives the current time of the clock once it
+// was advanced by the given duration
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- fc.now
+		return ch
+	}
+
+	fc.timers = append(fc.timers, fakeTimer{deadline: fc.now.Add(d), ch: ch})
+	// wake up the goroutines waiting for new timers
+	close(fc.changed)
+	fc.changed = make(chan struct{})
+	return ch
+}
+
+// Sleep blocks the current goroutine until the clock is advanced by the given
+// duration
+func (fc *FakeClock) Sleep(d time.Duration) {
+	<-fc.After(d)
+}
+
+// Advance moves the time of the clock forward, and fires the After and Sleep
+// calls which duration elapsed
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.now = fc.now.Add(d)
+
+	pending := fc.timers[:0]
+	for _, timer := range fc.timers {
+		if timer.deadline.After(fc.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- fc.now
+	}
+	fc.timers = pending
+}
+
+// BlockUntil blocks until there are at least n pending After or Sleep calls on
+// the clock. It is useful to make sure a supervisor is waiting on the clock
+// before it gets advanced.
+func (fc *FakeClock) BlockUntil(n int) {
+	for {
+		fc.mu.Lock()
+		count, changed := len(fc.timers), fc.changed
+		fc.mu.Unlock()
+
+		if count >= n {
+			return
+		}
+		<-changed
+	}
+}
@@ -1,6 +1,9 @@
 package cap
 
-import "github.com/capatazlib/go-capataz/internal/s"
+import (
+	"github.com/capatazlib/go-capataz/internal/c"
+	"github.com/capatazlib/go-capataz/internal/s"
+)
 
 // ErrKVs is an utility interface used to get key-values out of Capataz errors
 //
@@ -21,6 +24,13 @@ type SupervisorTerminationError = s.SupervisorTerminationError
 // Since: 0.0.0
 type SupervisorBuildError = s.SupervisorBuildError
 
+// SupervisorSetupError wraps errors returned from the client provided setup
+// function of a supervisor (see WithSetup), enhancing it with supervisor
+// information
+//
+// Since: 0.4.0
+type SupervisorSetupError = s.SupervisorSetupError
+
 // SupervisorStartError wraps an error reported on the initialization of a child
 // node, enhancing it with supervisor information and possible termination errors
 // on other siblings
@@ -48,3 +58,111 @@ type RestartToleranceReached = s.RestartToleranceReached
 //
 // Since: 0.1.0
 var ExplainError = s.ExplainError
+
+// ErrNodeNotFound is reported when a supervisor is asked to operate on a child
+// node that it is not running. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrNodeNotFound = s.ErrNodeNotFound
+
+// ErrSupervisorUnreachable is reported when a request cannot be delivered to a
+// supervisor. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrSupervisorUnreachable = s.ErrSupervisorUnreachable
+
+// ErrSupervisorTerminated is reported when a request is sent to a supervisor
+// that is not running anymore. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrSupervisorTerminated = s.ErrSupervisorTerminated
+
+// ErrForcedTermination is reported by RunWithSignals when a second signal
+// arrives before the supervision tree finished its graceful termination. Use
+// errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrForcedTermination = s.ErrForcedTermination
+
+// ErrSpawnRateLimited is reported when the context of a spawn call is done
+// before the spawn rate limit of the supervisor allows it (see
+// WithSpawnRateLimit). Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrSpawnRateLimited = s.ErrSpawnRateLimited
+
+// ErrUnexpectedCleanExit is reported when a supervisor escalates the clean exit
+// of a Transient child (see WithUnexpectedCleanExit). Use errors.Is to check
+// for it.
+//
+// Since: 0.4.0
+var ErrUnexpectedCleanExit = s.ErrUnexpectedCleanExit
+
+// ErrSwapInProgress is reported when a supervisor is asked to swap a child
+// that is being swapped already (see Supervisor.SwapChild). Use errors.Is to
+// check for it.
+//
+// Since: 0.4.0
+var ErrSwapInProgress = s.ErrSwapInProgress
+
+// ErrInvalidSpecDoc is reported by LoadSpec when the given document is not a
+// valid supervision tree spec. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrInvalidSpecDoc = s.ErrInvalidSpecDoc
+
+// ErrShutdownTimeout is reported when a child doesn't terminate before its
+// Shutdown timeout expires. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrShutdownTimeout = c.ErrShutdownTimeout
+
+// ErrStartTimeout is reported when a worker doesn't notify its start before
+// its start timeout expires (see WithStartTimeout). Use errors.Is to check for
+// it.
+//
+// Since: 0.4.0
+var ErrStartTimeout = c.ErrStartTimeout
+
+// ErrAbandoned is reported when a child doesn't terminate before the deadline
+// given to Supervisor.TerminateWithTimeout. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrAbandoned = c.ErrAbandoned
+
+// ErrOnTerminateFailed is reported when the finalizer of a worker (see
+// WithOnTerminate) returns an error
+//
+// Since: 0.4.0
+var ErrOnTerminateFailed = c.ErrOnTerminateFailed
+
+// ErrExitedBeforeStart is reported when the start function of a worker
+// returns without errors before it notifies its start
+//
+// Since: 0.4.0
+var ErrExitedBeforeStart = c.ErrExitedBeforeStart
+
+// ErrNotPausable is reported when Supervisor.PauseChild is called on a worker
+// that was not created with NewPausableWorker. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrNotPausable = c.ErrNotPausable
+
+// ErrPauseStateUnchanged is reported when a worker is paused (or resumed)
+// while it is already in that state. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrPauseStateUnchanged = c.ErrPauseStateUnchanged
+
+// ErrPauseSignalPending is reported when a worker is paused (or resumed) before
+// it has read its previous pause signal. Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrPauseSignalPending = c.ErrPauseSignalPending
+
+// ErrInvalidChildSpec is reported when a supervisor builds a child with invalid
+// settings (e.g. a nil start function, a negative timeout or a name that is
+// already used by a sibling). Use errors.Is to check for it.
+//
+// Since: 0.4.0
+var ErrInvalidChildSpec = c.ErrInvalidChildSpec
@@ -37,6 +37,224 @@ var ProcessFailed = s.ProcessFailed
 // Since: 0.0.0
 var ProcessCompleted = s.ProcessCompleted
 
+// ProcessPaused is an Event that indicates a process was paused by its parent
+// supervisor
+//
+// Since: 0.4.0
+var ProcessPaused = s.ProcessPaused
+
+// ProcessResumed is an Event that indicates a paused process was resumed by its
+// parent supervisor
+//
+// Since: 0.4.0
+var ProcessResumed = s.ProcessResumed
+
+// ProcessUnhealthy is an Event that indicates the health probe of a process
+// reported an error (see Supervisor.HealthCheck)
+//
+// Since: 0.4.0
+var ProcessUnhealthy = s.ProcessUnhealthy
+
+// ProcessRetired is an Event that indicates a process finished after its
+// retirement time (see WithRetireAfter), and it is not going to be restarted
+//
+// Since: 0.4.0
+var ProcessRetired = s.ProcessRetired
+
+// DynChildrenDiscarded is an Event that indicates a supervisor restart
+// terminated children that were spawned dynamically, and that are not going to
+// be started again. Use Event.GetDiscardedChildren to get their runtime names.
+//
+// Since: 0.4.0
+var DynChildrenDiscarded = s.DynChildrenDiscarded
+
+// ChildRestartedManually is an Event that indicates a process was restarted on
+// request of a client (see Supervisor.RestartChild), rather than because of a
+// failure
+//
+// Since: 0.4.0
+var ChildRestartedManually = s.ChildRestartedManually
+
+// ChildRestartedPeriodically is an Event that indicates a process was restarted
+// because its periodic restart interval expired (see WithPeriodicRestart),
+// rather than because of a failure
+//
+// Since: 0.4.0
+var ChildRestartedPeriodically = s.ChildRestartedPeriodically
+
+// ChildCircuitOpened is an Event that indicates a process failed too many times
+// in a row, and it is not going to be restarted until the cooldown period of
+// its circuit breaker is over (see WithCircuitBreaker)
+//
+// Since: 0.4.0
+var ChildCircuitOpened = s.ChildCircuitOpened
+
+// ChildCircuitHalfOpened is an Event that indicates the cooldown period of the
+// circuit breaker of a process is over, and the process is being started as a
+// trial
+//
+// Since: 0.4.0
+var ChildCircuitHalfOpened = s.ChildCircuitHalfOpened
+
+// ChildCircuitClosed is an Event that indicates a process ran without failures
+// on the trial of its circuit breaker, and it is restarted on failures again
+//
+// Since: 0.4.0
+var ChildCircuitClosed = s.ChildCircuitClosed
+
+// ChildSwitchedToFallback is an Event that indicates a process exhausted the
+// restart tolerance of its supervisor, and it is restarted with its fallback
+// start function (see WithFallback)
+//
+// Since: 0.4.0
+var ChildSwitchedToFallback = s.ChildSwitchedToFallback
+
+// ChildRestartRequested is an Event that indicates a process was restarted
+// because it requested its own restart (see RequestRestart), rather than
+// because of a failure
+//
+// Since: 0.4.0
+var ChildRestartRequested = s.ChildRestartRequested
+
+// GroupRestarted is an Event that indicates a supervisor with a OneForAll
+// strategy restarted all its children because one of them finished. Use
+// Event.GetTriggeringChild and Event.GetRestartedChildren to get the runtime
+// names of the children involved.
+//
+// Since: 0.4.0
+var GroupRestarted = s.GroupRestarted
+
+// ChildExitedUnexpectedly is an Event that indicates a Transient process
+// finished without an error, and its supervisor treats it as an anomaly (see
+// WithUnexpectedCleanExit)
+//
+// Since: 0.4.0
+var ChildExitedUnexpectedly = s.ChildExitedUnexpectedly
+
+// SubtreeEscalated is an Event that indicates a sub-tree gave up on its
+// children, and its supervisor applied an EscalationPolicy (see
+// WithEscalationPolicy)
+//
+// Since: 0.4.0
+var SubtreeEscalated = s.SubtreeEscalated
+
+// ChildSwapStarted is an Event that indicates the new version of a child that
+// is being swapped started, and it runs next to the old version (see
+// Supervisor.SwapChild)
+//
+// Since: 0.4.0
+var ChildSwapStarted = s.ChildSwapStarted
+
+// ChildSwapCompleted is an Event that indicates the old version of a child that
+// was being swapped got terminated, and the new version took its name
+//
+// Since: 0.4.0
+var ChildSwapCompleted = s.ChildSwapCompleted
+
+// ChildAbandonedOnShutdown is an Event that indicates a process didn't finish
+// before its shutdown timeout expired, and its goroutine was left running (see
+// Supervisor.AbandonedChildren)
+//
+// Since: 0.4.0
+var ChildAbandonedOnShutdown = s.ChildAbandonedOnShutdown
+
+// ChildEnteredBackoff is an Event that indicates a process finished and it is
+// waiting for the restart dampening window of its parent supervisor to be over
+// before it gets restarted (see WithRestartDampening).
+//
+// Since: 0.4.0
+var ChildEnteredBackoff = s.ChildEnteredBackoff
+
+// ChildExitedBackoff is an Event that indicates a process is no longer waiting
+// for the restart dampening window of its parent supervisor, either because the
+// window is over, or because the supervisor is terminating. Every
+// ChildEnteredBackoff event is followed by a ChildExitedBackoff event.
+//
+// Since: 0.4.0
+var ChildExitedBackoff = s.ChildExitedBackoff
+
+// ReasonCode is a machine-readable code that specifies why a failure Event was
+// reported. Use Event.ReasonCode to get it.
+//
+// Since: 0.4.0
+type ReasonCode = s.ReasonCode
+
+// ReasonNone is the ReasonCode of the Events that do not represent a failure
+//
+// Since: 0.4.0
+var ReasonNone = s.ReasonNone
+
+// ReasonChildError indicates a process returned an error
+//
+// Since: 0.4.0
+var ReasonChildError = s.ReasonChildError
+
+// ReasonChildPanic indicates a process that captures panics panicked
+//
+// Since: 0.4.0
+var ReasonChildPanic = s.ReasonChildPanic
+
+// ReasonStartError indicates a process failed to start
+//
+// Since: 0.4.0
+var ReasonStartError = s.ReasonStartError
+
+// ReasonShutdownError indicates a process failed to terminate, either because
+// it returned an error or because its shutdown timeout expired
+//
+// Since: 0.4.0
+var ReasonShutdownError = s.ReasonShutdownError
+
+// ReasonHealthCheckFailed indicates the health probe of a process reported an
+// error
+//
+// Since: 0.4.0
+var ReasonHealthCheckFailed = s.ReasonHealthCheckFailed
+
+// ReasonToleranceReached indicates a supervisor gave up restarting its
+// children because their failures surpassed its restart tolerance
+//
+// Since: 0.4.0
+var ReasonToleranceReached = s.ReasonToleranceReached
+
+// ReasonStartTimeout indicates a process did not notify its start before its
+// start timeout expired (see WithStartTimeout)
+//
+// Since: 0.4.0
+var ReasonStartTimeout = s.ReasonStartTimeout
+
+// ReasonPanicEscalation indicates a supervisor gave up restarting a worker that
+// panicked as many times as its panic escalation setting allows (see
+// WithPanicEscalation)
+//
+// Since: 0.4.0
+var ReasonPanicEscalation = s.ReasonPanicEscalation
+
+// ReasonTransientBudgetExhausted indicates a supervisor gave up restarting a
+// Transient worker that failed more times than its transient budget allows
+// (see WithTransientBudget)
+//
+// Since: 0.4.0
+var ReasonTransientBudgetExhausted = s.ReasonTransientBudgetExhausted
+
+// ReasonUnexpectedCleanExit indicates a supervisor escalated the clean exit of
+// a Transient worker (see WithUnexpectedCleanExit)
+//
+// Since: 0.4.0
+var ReasonUnexpectedCleanExit = s.ReasonUnexpectedCleanExit
+
+// ReasonEscalationPropagated indicates a supervisor propagated the escalation
+// of a sub-tree that gave up on its children (see WithEscalationPolicy)
+//
+// Since: 0.4.0
+var ReasonEscalationPropagated = s.ReasonEscalationPropagated
+
+// ReasonRestartFailed indicates a supervisor could not build or start its
+// children again on a full restart (see Supervisor.Restart)
+//
+// Since: 0.4.0
+var ReasonRestartFailed = s.ReasonRestartFailed
+
 // Event is a record emitted by the supervision system. The events are used for
 // multiple purposes, from testing to monitoring the healthiness of the
 // supervision system.
@@ -157,3 +375,39 @@ var EHasNameSuffix = n.EHasNameSuffix
 //
 // Since: 0.1.0
 var ApplyEventCriteria = n.ApplyEventCriteria
+
+// EIsTerminal returns true if the event represents a node that is not going to
+// be restarted anymore (e.g. a supervisor that surpassed its restart
+// tolerance, or a retired child)
+//
+// Since: 0.4.0
+var EIsTerminal = n.EIsTerminal
+
+// SamplingPolicy specifies how many events of each node a SamplingNotifier
+// forwards on every sampling window, and which events are always forwarded
+//
+// Since: 0.4.0
+type SamplingPolicy = n.SamplingPolicy
+
+// SamplingNotifier forwards the events of a supervision tree to a delegate
+// EventNotifier, keeping the first events of each node on every sampling window
+// and counting the rest. Use its Forwarded and Dropped methods to export the
+// counts.
+//
+// Since: 0.4.0
+type SamplingNotifier = n.SamplingNotifier
+
+// NewSamplingNotifier creates a SamplingNotifier that forwards events to the
+// given EventNotifier with the given SamplingPolicy; use its Notify method with
+// WithNotifier. Terminal events (see EIsTerminal) are always forwarded unless
+// the policy specifies otherwise.
+//
+// Since: 0.4.0
+var NewSamplingNotifier = n.NewSamplingNotifier
+
+// EventFilter specifies which events of a supervision tree are delivered to a
+// subscription created with Supervisor.Subscribe; it matches by runtime name
+// prefix, child tags and EventTag.
+//
+// Since: 0.4.0
+type EventFilter = s.EventFilter
@@ -0,0 +1,88 @@
+/*
+Package expvarpub publishes the state of a supervision tree as an expvar
+variable, so that it can be inspected from the /debug/vars endpoint.
+
+Example:
+
+	sup, err := spec.Start(ctx)
+	if err != nil {
+	  return err
+	}
+	expvarpub.Publish("capataz", &sup)
+*/
+package expvarpub
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"time"
+
+	"github.com/capatazlib/go-capataz/cap"
+)
+
+// snapshotTimeout is the time we wait for a supervision tree to report its
+// state on every read of the published variable.
+const snapshotTimeout = 1 * time.Second
+
+// treeVar is the value rendered by the published expvar.Var
+type treeVar struct {
+	Tree              *cap.TreeSnapshot `json:"tree,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	Failures          uint64            `json:"failures"`
+	CoalescedFailures uint64            `json:"coalesced_failures"`
+	Restarts          uint64            `json:"restarts"`
+	GroupRestarts     uint64            `json:"group_restarts"`
+	ChildrenInBackoff int64             `json:"children_in_backoff"`
+}
+
+// render builds the JSON representation of the supervision tree
+func render(sup *cap.Supervisor) string {
+	ctx, cancelFn := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancelFn()
+
+	report := sup.RestartAmplification()
+	value := treeVar{
+		Failures:          report.GetFailures(),
+		CoalescedFailures: report.GetCoalescedFailures(),
+		Restarts:          report.GetRestarts(),
+		GroupRestarts:     report.GetGroupRestarts(),
+		ChildrenInBackoff: report.GetChildrenInBackoff(),
+	}
+
+	snapshot, err := sup.Snapshot(ctx)
+	if err != nil {
+		value.Error = err.Error()
+	} else {
+		value.Tree = &snapshot
+	}
+
+	output, err := json.Marshal(value)
+	if err != nil {
+		// this should never happen, all the values are serializable
+		return `{"error":"could not serialize supervision tree"}`
+	}
+	return string(output)
+}
+
+// Publish registers an expvar.Var with the given name that renders the running
+// supervision tree (see Supervisor.Snapshot) and the aggregate restart counts
+// of the tree (see Supervisor.RestartAmplification) as JSON.
+//
+// The supervision tree is queried every time the variable is read. When the
+// supervisor is not running, the rendered value contains an error message
+// instead of the tree.
+//
+// Like expvar.Publish, this function panics if the name is already registered.
+func Publish(name string, sup *cap.Supervisor) {
+	expvar.Publish(name, stringFunc(func() string { return render(sup) }))
+}
+
+// stringFunc implements expvar.Var for a function that returns the JSON value
+// of the variable.
+type stringFunc func() string
+
+// String returns the JSON value of the variable
+func (fn stringFunc) String() string {
+	return fn()
+}
@@ -0,0 +1,108 @@
+package expvarpub_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/expvarpub"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+type publishedNode struct {
+	Name        string          `json:"name"`
+	RuntimeName string          `json:"runtime_name"`
+	Tag         string          `json:"tag"`
+	Children    []publishedNode `json:"children"`
+}
+
+type publishedTree struct {
+	Tree              *publishedNode `json:"tree"`
+	Error             string         `json:"error"`
+	Failures          uint64         `json:"failures"`
+	Restarts          uint64         `json:"restarts"`
+	ChildrenInBackoff int64          `json:"children_in_backoff"`
+}
+
+// publishCount makes the names of the published variables unique, expvar
+// panics when a name is published twice (e.g. with go test -count=2)
+var publishCount int64
+
+// uniqueName returns a variable name that was not published by a previous run
+// of the given test
+func uniqueName(t *testing.T) string {
+	return fmt.Sprintf("capataz_%s_%d", t.Name(), atomic.AddInt64(&publishCount, 1))
+}
+
+func readPublished(t *testing.T, name string) publishedTree {
+	var result publishedTree
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &result))
+	return result
+}
+
+func TestPublish(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+	child2 := WaitDoneWorker("child2")
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, child2),
+		cap.WithStrategy(cap.OneForAll),
+	)
+
+	restartedCh := make(chan struct{}, 1)
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.ProcessStarted &&
+			ev.GetProcessRuntimeName() == "root/subtree/child2" {
+			select {
+			case restartedCh <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(cap.Subtree(subtree), WaitDoneWorker("child3")),
+		cap.WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	name := uniqueName(t)
+	expvarpub.Publish(name, &sup)
+
+	// drain the notification of the initial start
+	<-restartedCh
+
+	failWorker1(true /* done */)
+	<-restartedCh
+
+	published := readPublished(t, name)
+	assert.Empty(t, published.Error)
+	assert.Equal(t, uint64(1), published.Failures)
+	assert.Equal(t, uint64(2), published.Restarts)
+	assert.Equal(t, int64(0), published.ChildrenInBackoff)
+
+	if assert.NotNil(t, published.Tree) {
+		assert.Equal(t, "root", published.Tree.RuntimeName)
+		assert.Len(t, published.Tree.Children, 2)
+		subtreeNode := published.Tree.Children[0]
+		assert.Equal(t, "root/subtree", subtreeNode.RuntimeName)
+		assert.Equal(t, "Supervisor", subtreeNode.Tag)
+		assert.Len(t, subtreeNode.Children, 2)
+		assert.Equal(t, "root/subtree/child1", subtreeNode.Children[0].RuntimeName)
+		assert.Equal(t, "root/child3", published.Tree.Children[1].RuntimeName)
+	}
+
+	assert.NoError(t, sup.Terminate())
+
+	// a terminated supervisor renders an error
+	published = readPublished(t, name)
+	assert.Nil(t, published.Tree)
+	assert.NotEmpty(t, published.Error)
+}
@@ -1,6 +1,7 @@
 package cap
 
 import (
+	"github.com/capatazlib/go-capataz/internal/c"
 	"github.com/capatazlib/go-capataz/internal/s"
 )
 
@@ -44,6 +45,12 @@ var OneForOne = s.OneForOne
 // Since: 0.2.0
 var OneForAll = s.OneForAll
 
+// RestForOne is an Strategy that tells the Supervisor to restart the failed
+// child process and all the siblings that were started after it
+//
+// Since: 0.4.0
+var RestForOne = s.RestForOne
+
 // CleanupResourcesFn is a function that cleans up resources that were
 // allocated in a BuildNodesFn function.
 //
@@ -176,6 +183,41 @@ type SupervisorSpec = s.SupervisorSpec
 // Since: 0.0.0
 var NewSupervisorSpec = s.NewSupervisorSpec
 
+// NewSupervisorSpecWithDefaults behaves like NewSupervisorSpec, with the
+// difference that the given default WorkerOpt values (e.g. WithRestart or
+// WithShutdown) are applied to every node returned by the given buildNodes
+// function, including sub-trees. This reduces boilerplate on supervisors where
+// every child shares the same settings.
+//
+// The options given to each node take precedence over the defaults when both
+// set the same setting.
+//
+// Since: 0.4.0
+var NewSupervisorSpecWithDefaults = s.NewSupervisorSpecWithDefaults
+
+// Run starts a supervision tree with the given spec, and blocks the current
+// goroutine until the tree terminates; it is the common pattern of a program's
+// main function.
+//
+// Cancelling the given context terminates the tree gracefully (children stop in
+// the reverse order of their start order) and Run returns nil. Run returns an
+// error when the tree fails to start, surpasses its restart tolerance, or when
+// the termination of a child fails.
+//
+// Since: 0.4.0
+var Run = s.Run
+
+// RunWithSignals behaves like Run, with the difference that the supervision
+// tree terminates gracefully when the program receives any of the given OS
+// signals (os.Interrupt and syscall.SIGTERM when none is given).
+//
+// A second signal forces the termination: the remaining children are
+// abandoned, and RunWithSignals returns an error that matches
+// ErrForcedTermination without waiting for them.
+//
+// Since: 0.4.0
+var RunWithSignals = s.RunWithSignals
+
 // Opt is a type used to configure a SupervisorSpec
 //
 // Since: 0.0.0
@@ -207,15 +249,42 @@ var WithOrder = WithStartOrder
 //
 // * OneForOne -- Only restart the failing child
 //
-// * OneForAll (Not Implemented Yet) -- Restart the failing child and all its
-// siblings[*]
+// * OneForAll -- Restart the failing child and all its siblings[*]
+//
+// * RestForOne -- Restart the failing child and all the siblings that were
+// started after it[**]
 //
 // [*] This option may come handy when all the other siblings depend on one another
 // to work correctly.
 //
+// [**] This option may come handy when a sibling depends on the siblings that
+// were started before it (e.g. stages of a pipeline).
+//
 // Since: 0.0.0
 var WithStrategy = s.WithStrategy
 
+// RestartStrategy decides which children of a supervisor are restarted when
+// one of them fails; it allows the implementation of restart logic that is not
+// covered by the built-in Strategy values, which implement this interface too.
+//
+// Since: 0.4.0
+type RestartStrategy = s.RestartStrategy
+
+// ChildInfo describes a child of a supervisor to a RestartStrategy: its name,
+// tags, dependencies and position in the start order of the supervisor.
+//
+// Since: 0.4.0
+type ChildInfo = s.ChildInfo
+
+// WithRestartStrategy is an Opt that specifies a custom RestartStrategy. The
+// children the strategy returns are terminated in the reverse of their start
+// order, and then started again in start order, like it happens with the
+// built-in strategies. The siblings that depend on the failed child (see
+// WithDependsOn) are always restarted with it.
+//
+// Since: 0.4.0
+var WithRestartStrategy = s.WithRestartStrategy
+
 // WithNotifier is an Opt that specifies a callback that gets called whenever
 // the supervision system reports an Event
 //
@@ -233,6 +302,20 @@ var WithNotifier = s.WithNotifier
 // Check the documentation of NewSupervisorSpec for more details and examples.
 var WithNodes = s.WithNodes
 
+// WithSubtreeState creates a BuildNodesFn that carries a state value across
+// the restarts of a supervisor. The init function builds the state on the
+// first start, the onRestart function derives the state of every later start
+// from the previous one, and buildNodes receives the current state.
+//
+// Since: 0.4.0
+func WithSubtreeState[S any](
+	init func() (S, error),
+	onRestart func(old S) S,
+	buildNodes func(S) ([]Node, CleanupResourcesFn, error),
+) BuildNodesFn {
+	return s.WithSubtreeState(init, onRestart, buildNodes)
+}
+
 // WithRestartTolerance is a Opt that specifies how many errors the supervisor
 // should be willing to tolerate before giving up restarting and fail.
 //
@@ -251,6 +334,217 @@ var WithNodes = s.WithNodes
 // Since: 0.1.0
 var WithRestartTolerance = s.WithRestartTolerance
 
+// WithStartupConcurrency is an Opt that specifies how many children of a
+// supervisor may be started at the same time. This option is useful on
+// supervisors with a big number of children, where a sequential start adds
+// noticeable latency.
+//
+// Children are still dispatched in start order, and the supervisor waits for
+// all of them to notify their start before reporting itself as started. If any
+// child fails to start, the already started siblings are terminated in reverse
+// start order, like it happens with a sequential start.
+//
+// By default children start sequentially (a concurrency of 1). This function
+// panics when the given number is less than 1.
+//
+// Since: 0.4.0
+var WithStartupConcurrency = s.WithStartupConcurrency
+
+// WithMaxConcurrentRestarts is an Opt that specifies how many of the children
+// restarted together by a supervisor (e.g. on a OneForAll restart) are started
+// at the same time; the rest of the children wait for their turn in start
+// order, and they are not restarted when the supervisor is terminated in the
+// meantime. It lowers the WithStartupConcurrency setting on restarts, never
+// going beyond it.
+//
+// Since: 0.4.0
+var WithMaxConcurrentRestarts = s.WithMaxConcurrentRestarts
+
+// WithHealDuration is an Opt that makes the supervisor forgive the restarts of
+// a child once the child has been running continuously for the given duration,
+// so that they stop accounting for the supervisor's restart tolerance before
+// the restart window is over. By default, restarts are only forgotten when the
+// restart window is over.
+//
+// Since: 0.4.0
+var WithHealDuration = s.WithHealDuration
+
+// WithRestartDampening is an Opt that makes the supervisor coalesce the failures
+// of its children that are pending when it restarts a child into that restart,
+// and the failures that happen within the given window after a restart into a
+// single restart. It is only effective with the OneForAll and RestForOne
+// strategies, and with custom restart strategies.
+//
+// Since: 0.4.0
+var WithRestartDampening = s.WithRestartDampening
+
+// RestartAmplificationReport contains the number of failures and restarts that
+// happened in a supervision tree. Use Supervisor.RestartAmplification to get
+// one.
+//
+// Since: 0.4.0
+type RestartAmplificationReport = s.RestartAmplificationReport
+
+// ResourceSpec represents a resource that a supervisor acquires before starting
+// its children, and releases after terminating them.
+//
+// Since: 0.4.0
+type ResourceSpec = s.ResourceSpec
+
+// NewResourceSpec creates a ResourceSpec. The acquire function is called when
+// the supervisor starts, and the release function is called when the
+// supervisor terminates. The release function receives a context that is done
+// once the given releaseTimeout expires; when the release function does not
+// finish on time, the supervisor reports a timeout error and moves on with the
+// release of the other resources.
+//
+// Since: 0.4.0
+var NewResourceSpec = s.NewResourceSpec
+
+// WithResources is an Opt that specifies resources the supervisor must acquire
+// before starting its children, and release after terminating them.
+//
+// Resources are acquired in the given order and released in reverse order,
+// each release waits at most the release timeout of its ResourceSpec. When a
+// resource fails to be acquired, the supervisor start is aborted and the
+// resources that were already acquired get released.
+//
+// Since: 0.4.0
+var WithResources = s.WithResources
+
+// WithSetup is an Opt that specifies a function that the supervisor calls
+// before it builds and starts its children. It is useful to allocate values
+// shared by the children (e.g. a database pool); the returned cleanup function
+// (which may be nil) is called once all the children have stopped.
+//
+// When the setup function fails, the supervisor doesn't start any children and
+// fails with a SupervisorSetupError. The setup function runs after the
+// resources declared with WithResources are acquired, and it runs again every
+// time the supervisor gets restarted.
+//
+// Since: 0.4.0
+var WithSetup = s.WithSetup
+
+// WithCleanup is an Opt that specifies a function that the supervisor calls
+// once all its children have stopped, both on a regular termination and when
+// the supervisor crashes because its restart tolerance was surpassed. It is
+// useful to release values shared by the children (e.g. a database pool).
+//
+// The cleanup function is called once every time the supervisor terminates,
+// before the resources declared with WithResources are released. A cleanup
+// error is reported on the supervisor termination error.
+//
+// Since: 0.4.0
+var WithCleanup = s.WithCleanup
+
+// Clock is the source of time of a supervisor. The restart tolerance window,
+// the restart dampening window and the time-based restart settings of
+// children are measured with it.
+//
+// Since: 0.4.0
+type Clock = s.Clock
+
+// WithClock is an Opt that specifies the Clock the supervisor uses to measure
+// time (e.g. the restart tolerance window). It is useful to control the time
+// of a supervisor in tests (see captest.FakeClock).
+//
+// Sub-trees inherit the Clock of their parent supervisor, unless they specify
+// one of their own.
+//
+// Since: 0.4.0
+var WithClock = s.WithClock
+
+// WithDeadLetter is an Opt that specifies a callback that the supervisor calls
+// once when it permanently gives up on a failing child: when the child
+// surpasses the restart tolerance of the supervisor, when it panics as many
+// times as its panic escalation setting allows, or when it exhausts its
+// transient budget. The callback receives the runtime name of the child and a
+// RestartToleranceReached error, which wraps the last error of the child and
+// offers the failure details via its KVs method.
+//
+// The callback is called from the supervisor goroutine, it must not block.
+//
+// Since: 0.4.0
+var WithDeadLetter = s.WithDeadLetter
+
+// Logger is the minimal logging interface the supervision tree provides to its
+// nodes. Messages are accompanied by key-value pairs, so that it can be adapted
+// to structured logging libraries (e.g. slog, zap or zerolog).
+//
+// Since: 0.4.0
+type Logger = c.Logger
+
+// WithLogger is an Opt that specifies the Logger of the supervision tree. Every
+// node started by this supervisor (and its sub-trees) gets a Logger derived from
+// the given one, which prefixes messages with the node's runtime name.
+//
+// Sub-trees inherit the Logger of their parent supervisor, unless they specify
+// one of their own.
+//
+// Since: 0.4.0
+var WithLogger = s.WithLogger
+
+// LoggerFromContext returns the Logger of the node that was started with the
+// given context (see WithLogger). When the supervision tree doesn't have a
+// Logger, a Logger that discards every message is returned.
+//
+// Since: 0.4.0
+var LoggerFromContext = c.LoggerFromContext
+
+// WithSeed is an Opt that specifies the seed of the random sources of the
+// supervision tree. Every node gets a source derived from the seed and its
+// runtime name (see RandFromContext); a failing test can be run again with the
+// same seed to reproduce the random values drawn by the tree.
+//
+// The real clock still introduces nondeterminism on the timing of restarts,
+// unless a fake Clock is also used (see WithClock). This option is only
+// honored on the root supervisor.
+//
+// Since: 0.4.0
+var WithSeed = s.WithSeed
+
+// RandFromContext returns a random source for the node that was started with
+// the given context. When the supervision tree has a seed (see WithSeed), the
+// source is derived from the seed and the runtime name of the node; otherwise,
+// it is seeded with the current time. The returned source is not safe for
+// concurrent use.
+//
+// Since: 0.4.0
+var RandFromContext = c.RandFromContext
+
+// SupervisorNameFromContext returns the runtime name of the supervisor of the
+// node that was started with the given context (e.g. "root/subtree1"), so that
+// workers can correlate their logs with their position in the supervision
+// tree. It returns false when the context doesn't belong to a node.
+//
+// Since: 0.4.0
+var SupervisorNameFromContext = c.SupervisorNameFromContext
+
+// WithPanicRecovery is an Opt that specifies if the panics raised on the
+// goroutines of this supervisor's children are recovered and handled as
+// regular child failures (defaults to true). The error reported by a recovered
+// child is a PanicError, which carries the panic value and the stack trace of
+// the goroutine.
+//
+// Recovery applies to every child, regardless of its WithCapturePanic
+// setting; when disabled, a panic on a child goroutine crashes the program.
+// Sub-trees inherit the setting of their parent supervisor, unless they
+// specify one of their own.
+//
+// Since: 0.4.0
+var WithPanicRecovery = s.WithPanicRecovery
+
+// WithNameSeparator is an Opt that specifies the token used to join the names
+// of supervisors and their children when building runtime names (defaults to
+// "/"). The separator affects every runtime name of the supervision tree, and
+// as such the names reported on events and loggers.
+//
+// This option is only honored on the root supervisor; sub-trees always inherit
+// the separator of the root supervisor. It panics when the separator is empty.
+//
+// Since: 0.4.0
+var WithNameSeparator = s.WithNameSeparator
+
 // Subtree transforms SupervisorSpec into a Node. This function allows you to
 // insert a black-box sub-system into a bigger supervised system.
 //
@@ -297,6 +591,7 @@ type DynSupervisor = s.DynSupervisor
 // As opposed to a Supervisor, a DynSupervisor:
 //
 // * Cannot receive node specifications to start them in an static fashion
+// (use NewDynSupervisorWithNodes for that)
 //
 // * It is able to spawn workers dynamically
 //
@@ -306,6 +601,14 @@ type DynSupervisor = s.DynSupervisor
 // Since: 0.0.0
 var NewDynSupervisor = s.NewDynSupervisor
 
+// NewDynSupervisorWithNodes creates a DynSupervisor that starts the nodes
+// returned by the given BuildNodesFn, as a regular Supervisor would, and that
+// is able to spawn more workers at runtime. On DynSupervisor.Restart, the nodes
+// given here are started again, while the spawned workers are discarded.
+//
+// Since: 0.4.0
+var NewDynSupervisorWithNodes = s.NewDynSupervisorWithNodes
+
 // Spawner is a builder type that can spawn other workers
 //
 // since: 0.2.0
@@ -335,3 +638,256 @@ var NewDynSubtreeWithNotifyStart = s.NewDynSubtreeWithNotifyStart
 //
 // Since: 0.0.0
 type Supervisor = s.Supervisor
+
+// PipelineStageFn is the function that runs the business logic of a pipeline
+// stage. It receives the channel from where it reads the values of the
+// previous stage, and the channel where it writes the values for the next
+// stage. Stages must not close these channels.
+//
+// Since: 0.4.0
+type PipelineStageFn = s.PipelineStageFn
+
+// PipelineStage represents a step of a pipeline built with NewPipeline.
+//
+// Since: 0.4.0
+type PipelineStage = s.PipelineStage
+
+// NewPipelineStage creates a PipelineStage that runs the given function on a
+// supervised worker goroutine with the given name.
+//
+// Since: 0.4.0
+var NewPipelineStage = s.NewPipelineStage
+
+// NewPipeline creates a sub-tree Node that runs the given stages as workers
+// connected through channels. The stages are supervised with the RestForOne
+// strategy, so when a stage fails, all the stages that come after it get
+// restarted as well.
+//
+// Since: 0.4.0
+var NewPipeline = s.NewPipeline
+
+// TreeSnapshot represents the state of a node of a running supervision tree at
+// some point in time. Use Supervisor.Snapshot to get one.
+//
+// Since: 0.4.0
+type TreeSnapshot = s.TreeSnapshot
+
+// TreeNode represents a node of the topology of a running supervision tree
+// (see Supervisor.Topology), with the restart and shutdown settings of the
+// node. It can be serialized with json.Marshal.
+//
+// Since: 0.4.0
+type TreeNode = s.TreeNode
+
+// ChildNotification reports the termination of a child of a supervision tree.
+// Use its Unwrap method to get the error of the child, and its RuntimeName
+// method to identify the child. Check Supervisor.Notifications for details.
+//
+// Since: 0.4.0
+type ChildNotification = c.ChildNotification
+
+// TerminationReason specifies why a child stopped running, use the Reason
+// method of a ChildNotification to get it
+//
+// Since: 0.4.0
+type TerminationReason = c.TerminationReason
+
+// TerminationCleanExit is a TerminationReason that indicates the child
+// finished without an error
+//
+// Since: 0.4.0
+var TerminationCleanExit = c.TerminationCleanExit
+
+// TerminationErrorExit is a TerminationReason that indicates the child
+// finished with an error
+//
+// Since: 0.4.0
+var TerminationErrorExit = c.TerminationErrorExit
+
+// TerminationPanic is a TerminationReason that indicates the child panicked
+//
+// Since: 0.4.0
+var TerminationPanic = c.TerminationPanic
+
+// TerminationBySupervisor is a TerminationReason that indicates the supervisor
+// of the child stopped it on purpose (e.g. on termination, or on a periodic
+// restart); it is not a failure of the child
+//
+// Since: 0.4.0
+var TerminationBySupervisor = c.TerminationBySupervisor
+
+// TerminationContextCancelled is a TerminationReason that indicates the child
+// finished with a context cancellation error that was not caused by its
+// supervisor
+//
+// Since: 0.4.0
+var TerminationContextCancelled = c.TerminationContextCancelled
+
+// NotificationOverflow specifies what happens with the notifications of
+// Supervisor.Notifications when the buffer of the channel is full. Check
+// WithNotificationBuffer for details.
+//
+// Since: 0.4.0
+type NotificationOverflow = s.NotificationOverflow
+
+// DropOldestNotification is a NotificationOverflow that discards the oldest
+// buffered notification to make room for the new one
+//
+// Since: 0.4.0
+var DropOldestNotification = s.DropOldestNotification
+
+// BlockNotification is a NotificationOverflow that makes the supervisor of a
+// failed child wait until there is room in the buffer of the channel
+//
+// Since: 0.4.0
+var BlockNotification = s.BlockNotification
+
+// WithNotificationBuffer is an Opt that specifies the buffer size of the
+// channel returned by Supervisor.Notifications, and what happens when the
+// buffer is full because its consumer is slow. It is only honored on the root
+// supervisor.
+//
+// Since: 0.4.0
+var WithNotificationBuffer = s.WithNotificationBuffer
+
+// WithShutdownOrder is an Opt that specifies the order in which the supervisor
+// stops its children, overriding the default of stopping them in the reverse
+// of their start order. The children that are not listed are stopped after the
+// listed ones, in the reverse of their start order. Unknown or repeated names
+// make the supervisor fail to build with a SupervisorBuildError.
+//
+// Since: 0.4.0
+var WithShutdownOrder = s.WithShutdownOrder
+
+// WithGroupRestartEventsOnly is an Opt that reports the restarts of a OneForAll
+// supervisor with a single GroupRestarted event, omitting the ProcessTerminated
+// and ProcessStarted events of the siblings that are restarted together with
+// the failed child. By default, both kinds of events are reported.
+//
+// Since: 0.4.0
+var WithGroupRestartEventsOnly = s.WithGroupRestartEventsOnly
+
+// WithEventHistory is an Opt that makes the supervisor retain the given number
+// of its most recent events, so that they can be fetched with
+// Supervisor.ReplayEvents. By default, no events are retained. It is only
+// honored on the root supervisor.
+//
+// Since: 0.4.0
+var WithEventHistory = s.WithEventHistory
+
+// WithWatchdog is an Opt that reports when an operation of the supervisor loop
+// takes longer than the given duration (e.g. an event notifier that blocks).
+// The onStall callback receives a label of the stalled operation: "notifier",
+// "child-start", "child-terminate" or "resource-cleanup". The watchdog is
+// diagnostic only, it doesn't interrupt the stalled operation.
+//
+// Since: 0.4.0
+var WithWatchdog = s.WithWatchdog
+
+// WithSpawnRateLimit is an Opt that bounds how fast children are spawned on a
+// DynSupervisor, or with the Spawner of a NewDynSubtree node (when given on its
+// spawnerOpts); the limit is expressed with the rate.Limit type of the
+// golang.org/x/time/rate package (e.g. rate.Every(time.Second)). Spawn calls
+// block until the limit allows them; when the context of the wait is done
+// first, they return an error that matches ErrSpawnRateLimited. By default,
+// spawns are not rate limited.
+//
+// Since: 0.4.0
+var WithSpawnRateLimit = s.WithSpawnRateLimit
+
+// CleanExitAction specifies what a supervisor does when one of its Transient
+// children finishes without an error (see WithUnexpectedCleanExit)
+//
+// Since: 0.4.0
+type CleanExitAction = s.CleanExitAction
+
+// CleanExitNotify is a CleanExitAction that reports a ChildExitedUnexpectedly
+// event, the child is not restarted
+//
+// Since: 0.4.0
+var CleanExitNotify = s.CleanExitNotify
+
+// CleanExitRestart is a CleanExitAction that reports a ChildExitedUnexpectedly
+// event, and restarts the child as if it was a Permanent child
+//
+// Since: 0.4.0
+var CleanExitRestart = s.CleanExitRestart
+
+// CleanExitEscalate is a CleanExitAction that reports a
+// ChildExitedUnexpectedly event, and makes the supervisor fail regardless of
+// its restart tolerance
+//
+// Since: 0.4.0
+var CleanExitEscalate = s.CleanExitEscalate
+
+// WithUnexpectedCleanExit is an Opt that makes the supervisor treat the clean
+// exit of a Transient child as an anomaly: it reports a ChildExitedUnexpectedly
+// event, and then takes the given CleanExitAction. The clean exits of Permanent
+// and Temporary children are not affected.
+//
+// Since: 0.4.0
+var WithUnexpectedCleanExit = s.WithUnexpectedCleanExit
+
+// EscalationPolicy specifies what a supervisor does when one of its sub-trees
+// gives up on its children (see WithEscalationPolicy)
+//
+// Since: 0.4.0
+type EscalationPolicy = s.EscalationPolicy
+
+// RestartSubtree is an EscalationPolicy that restarts the failed sub-tree as
+// any other failing child
+//
+// Since: 0.4.0
+var RestartSubtree = s.RestartSubtree
+
+// StopSubtree is an EscalationPolicy that leaves the failed sub-tree stopped,
+// as if it was a Temporary child
+//
+// Since: 0.4.0
+var StopSubtree = s.StopSubtree
+
+// Propagate is an EscalationPolicy that makes the supervisor fail when one of
+// its sub-trees fails, regardless of its restart tolerance
+//
+// Since: 0.4.0
+var Propagate = s.Propagate
+
+// WithEscalationPolicy is an Opt that specifies what the supervisor does when
+// one of its sub-trees gives up on its children; it reports a SubtreeEscalated
+// event with the applied EscalationPolicy.
+//
+// Since: 0.4.0
+var WithEscalationPolicy = s.WithEscalationPolicy
+
+// StartFn is the start function of a worker node (see NewWorkerWithNotifyStart)
+//
+// Since: 0.4.0
+type StartFn = s.StartFn
+
+// LoadSpec builds a SupervisorSpec from a YAML (or JSON) document that
+// describes the shape of a supervision tree (node names and tags) and its
+// policies (restart, shutdown, start phases, strategies, start orders and
+// restart tolerances). Start functions remain code: the workers of the
+// document reference them by name, and they are bound from the given registry.
+//
+// The returned error matches ErrInvalidSpecDoc when the document cannot be
+// parsed, or when it is invalid (e.g. it references a start function that is
+// not in the registry).
+//
+// Since: 0.4.0
+var LoadSpec = s.LoadSpec
+
+// StartMiddleware wraps the start function of a worker node with cross-cutting
+// logic (e.g. logging, timing metrics, context enrichment)
+//
+// Since: 0.4.0
+type StartMiddleware = s.StartMiddleware
+
+// WithStartMiddleware is an Opt that wraps the start function of every worker
+// of the supervisor (including the ones spawned dynamically) with the given
+// middleware. The middleware are composed outermost-first: the first one given
+// is the first one to run when a worker starts. Sub-trees are not affected, use
+// this option on their own spec instead.
+//
+// Since: 0.4.0
+var WithStartMiddleware = s.WithStartMiddleware
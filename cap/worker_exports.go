@@ -1,6 +1,8 @@
 package cap
 
 import (
+	"context"
+
 	"github.com/capatazlib/go-capataz/internal/c"
 	"github.com/capatazlib/go-capataz/internal/s"
 )
@@ -117,15 +119,171 @@ var WithRestart = c.WithRestart
 // * Timeout(time.Duration) -- Wait for a duration of time before giving up
 // shuting down this worker goroutine
 //
+// When the worker is terminated with a Timeout setting, its context reports a
+// deadline (see context.Context.Deadline) at the end of the timeout, so that
+// the worker can budget its cleanup.
+//
 // Since: 0.0.0
 var WithShutdown = c.WithShutdown
 
 // WithCapturePanic is a WorkerOpt that specifies if panics raised by
 // this worker should be treated as errors.
 //
+// Panics are recovered unless the supervisor disables it with
+// WithPanicRecovery, regardless of this setting.
+//
 // Since: 0.0.0
 var WithCapturePanic = c.WithCapturePanic
 
+// WithPanicEscalation is a WorkerOpt that specifies the number of panics after
+// which the parent supervisor gives up restarting the worker and escalates the
+// failure, regardless of the supervisor's restart tolerance.
+//
+// Panics are usually a symptom of a bug rather than a transient fault, so
+// restarting the worker is not likely to help. Other errors reported by the
+// worker are not considered in this count.
+//
+// Since: 0.4.0
+var WithPanicEscalation = c.WithPanicEscalation
+
+// WithHealthCheck is a WorkerOpt that registers a probe function that reports
+// if the worker is healthy. The probe gets invoked by Supervisor.HealthCheck,
+// workers without a probe are always reported as healthy.
+//
+// Since: 0.4.0
+var WithHealthCheck = c.WithHealthCheck
+
+// WithRetireAfter is a WorkerOpt that specifies that the parent supervisor must
+// not restart the worker when it fails or completes after the given time; a
+// ProcessRetired event is reported instead. Before that time, the worker is
+// restarted according to its Restart setting.
+//
+// Since: 0.4.0
+var WithRetireAfter = c.WithRetireAfter
+
+// WithTags is a WorkerOpt that attaches the given labels to the worker (e.g.
+// team=payments, tier=critical). The tags are reported on the events of the
+// worker (see Event.GetTags) and on the supervision tree topology (see
+// Supervisor.Topology), so that they can be promoted to metric labels or log
+// attributes.
+//
+// The tags are copied, changes on the given map after this call do not affect
+// the worker.
+//
+// Since: 0.4.0
+var WithTags = c.WithTags
+
+// WithPeriodicRestart is a WorkerOpt that specifies that the parent supervisor
+// must restart the worker every time the given interval expires, regardless of
+// failures (e.g. to recycle workers that accumulate memory). The worker's
+// context is cancelled with the deadline of its shutdown setting, and the
+// worker is started again once it finishes; a ChildRestartedPeriodically event
+// is reported after the restart.
+//
+// Periodic restarts are not accounted on the restart tolerance of the parent
+// supervisor, and they only restart the worker, regardless of the supervisor
+// strategy. The interval starts over on every restart of the worker, either
+// periodic or because of a failure.
+//
+// Since: 0.4.0
+var WithPeriodicRestart = c.WithPeriodicRestart
+
+// WithFallback is a WorkerOpt that specifies the start function the parent
+// supervisor uses to restart the worker once the restart tolerance of the
+// supervisor is exhausted because of the worker's failures (e.g. to serve a
+// static error page when a backend is down). Instead of escalating the
+// failure, the supervisor reports a ChildSwitchedToFallback event and restarts
+// the worker with the fallback, which gets a new restart tolerance window.
+//
+// From then on, the worker runs the fallback with Permanent semantics; when
+// the fallback exhausts the restart tolerance, the supervisor fails as usual.
+//
+// Since: 0.4.0
+var WithFallback = c.WithFallback
+
+// WithCircuitBreaker is a WorkerOpt that specifies that the parent supervisor
+// must stop restarting the worker after it fails failureThreshold consecutive
+// times. While the circuit is open, the worker is not running; once the given
+// cooldown is over, the circuit is half-open and the worker is started once as
+// a trial. When the trial worker runs for a whole cooldown period without
+// failing the circuit is closed again, otherwise the circuit opens again.
+//
+// The state transitions are reported with the ChildCircuitOpened,
+// ChildCircuitHalfOpened and ChildCircuitClosed events. A worker that runs for
+// a whole cooldown period without failing resets its consecutive failure
+// count.
+//
+// The failures of the worker are accounted by its circuit breaker rather than
+// by the restart tolerance of the parent supervisor.
+//
+// Since: 0.4.0
+var WithCircuitBreaker = c.WithCircuitBreaker
+
+// WithStartTimeout is a WorkerOpt that specifies that the parent supervisor
+// must give up on the worker when it doesn't notify its start within the given
+// duration. The worker's context is cancelled, and the worker gets its
+// shutdown grace period (see WithShutdown) to finish before the start failure
+// is reported. By default, supervisors wait indefinitely for the start of their
+// workers.
+//
+// Since: 0.4.0
+var WithStartTimeout = c.WithStartTimeout
+
+// WithLazyStart is a WorkerOpt that specifies that the parent supervisor must
+// not start the worker until the channel returned by the given trigger fires
+// (it is closed or it receives a value). Until then, the worker is registered
+// on the supervisor but it is not running; Supervisor.Snapshot reports it with
+// TreeSnapshot.IsLazyPending, and it is neither probed by
+// Supervisor.HealthCheck nor accounted by Supervisor.ActiveChildCount.
+//
+// Once the trigger fires, the worker is started and supervised as any other
+// worker (e.g. it is restarted when it fails). The context given to the
+// trigger is done when the supervisor terminates the worker before it starts.
+//
+// Since: 0.4.0
+var WithLazyStart = c.WithLazyStart
+
+// WithStartPhase is a WorkerOpt that specifies the phase in which the parent
+// supervisor starts the worker. All the children of a phase are started before
+// any child of the next phase, and phases are started in ascending order; the
+// start order of the supervisor (see WithStartOrder) applies within a phase.
+// On shutdown, phases are terminated in the reverse order.
+//
+// When combined with WithStartupConcurrency, the children of a phase are
+// started concurrently, but phases are started one after the other.
+//
+// Since: 0.4.0
+var WithStartPhase = c.WithStartPhase
+
+// WithStartPriority is a WorkerOpt that specifies the priority of the worker
+// among the children of its start phase. Within a phase, children with a
+// higher priority are started first, and terminated last; the start order of
+// the supervisor (see WithStartOrder) applies to children with the same
+// priority. Unlike phases, priorities are a hint on the order, not a barrier.
+//
+// When combined with WithStartupConcurrency, priorities determine the order in
+// which children are dispatched, but children with different priorities may
+// still be starting at the same time.
+//
+// Since: 0.4.0
+var WithStartPriority = c.WithStartPriority
+
+// WithTransientBudget is a WorkerOpt that specifies that a Transient worker may
+// fail at most n times within the given window. Once the budget is exceeded,
+// the parent supervisor stops restarting the worker (as if it was Temporary)
+// instead of escalating the failure. Completions without an error do not count
+// against the budget.
+//
+// Since: 0.4.0
+var WithTransientBudget = c.WithTransientBudget
+
+// PanicError is the error reported by a worker that panicked while its
+// supervisor recovers panics (see WithPanicRecovery). When the panic value is an error, it can be
+// extracted with errors.Unwrap.
+//
+// Since: 0.4.0
+type PanicError = c.PanicError
+
 // WithTag is a WorkerOpt that sets the given NodeTag on Worker.
 //
 // Do not use this function if you are not extending capataz' API.
@@ -205,5 +363,147 @@ var NewWorker = s.NewWorker
 // NotifyStartFn function with the impending error as a parameter. This will
 // cause the whole supervision system start procedure to abort.
 //
+// A startFn function that returns before calling the NotifyStartFn callback
+// fails to start with the returned error, or with an error that matches
+// ErrExitedBeforeStart when it returns nil.
+//
 // Since: 0.0.0
 var NewWorkerWithNotifyStart = s.NewWorkerWithNotifyStart
+
+// NewTypedWorker accomplishes the same goal as NewWorkerWithNotifyStart with
+// the addition of passing the given deps value to the startFn function
+// parameter, so that the dependencies of a worker are given explicitly and
+// type-checked, rather than captured in a closure.
+//
+// Since: 0.4.0
+func NewTypedWorker[T any](
+	name string,
+	deps T,
+	startFn func(context.Context, T, NotifyStartFn) error,
+	opts ...WorkerOpt,
+) Node {
+	return s.NewTypedWorker(name, deps, startFn, opts...)
+}
+
+// PauseSignal is a value that a supervisor sends to a pausable worker to pause
+// or resume its processing.
+//
+// # See the documentation of NewPausableWorker for more details
+//
+// Since: 0.4.0
+type PauseSignal = s.PauseSignal
+
+// PauseRequested indicates a worker must stop its processing (without releasing
+// its resources) until it gets a ResumeRequested signal
+//
+// Since: 0.4.0
+const PauseRequested = s.PauseRequested
+
+// ResumeRequested indicates a paused worker must resume its processing
+//
+// Since: 0.4.0
+const ResumeRequested = s.ResumeRequested
+
+// NewPausableWorker accomplishes the same goal as NewWorker with the addition
+// of passing a channel to the startFn function parameter, from where the
+// worker receives the signals sent with Supervisor.PauseChild and
+// Supervisor.ResumeChild.
+//
+// # The pause channel argument
+//
+// When the worker receives a PauseRequested signal, it should stop its
+// processing, keeping the resources it has allocated, until it receives a
+// ResumeRequested signal. A paused worker must keep honoring the given
+// context.Context, so that it can be terminated while it is paused.
+//
+// Since: 0.4.0
+var NewPausableWorker = s.NewPausableWorker
+
+// WithDependsOn is a WorkerOpt that specifies the names of the siblings the
+// worker depends on. The worker starts after its dependencies, and whatever
+// the restart strategy of the supervisor, it is restarted (after them) every
+// time one of its dependencies is restarted, even when the dependency is
+// transitive.
+//
+// When one of the names doesn't belong to a sibling of the worker, when it
+// belongs to a sibling that starts in a later phase (see WithStartPhase), or
+// when the dependencies form a cycle, the parent supervisor fails to start
+// with a SupervisorBuildError.
+//
+// Since: 0.4.0
+var WithDependsOn = c.WithDependsOn
+
+// NewWorkerPool returns the given number of worker nodes, named with the given
+// prefix and the index of the worker (e.g. prefix-0, prefix-1, etc.). The build
+// function receives the index of each worker, the name of the node it returns
+// is replaced with the pool member name.
+//
+// The size of the pool may be changed at runtime with Supervisor.ResizePool.
+// When the given size is zero, a single node that doesn't start any worker is
+// returned, so that the pool is known to the supervisor and it can grow later.
+//
+// Since: 0.4.0
+var NewWorkerPool = s.NewWorkerPool
+
+// RequestRestart asks the parent supervisor of the worker that was started with
+// the given context to restart it, e.g. when the worker detects it is in a bad
+// internal state. The worker's context is cancelled with the deadline of its
+// shutdown setting, and the worker is started again once it finishes; a
+// ChildRestartRequested event is reported after the restart.
+//
+// Requested restarts are not failures: they are not accounted on the restart
+// tolerance of the parent supervisor, the error returned by the worker is
+// ignored, and they only restart the worker, regardless of the supervisor
+// strategy. It returns false when the context doesn't belong to a worker.
+//
+// Since: 0.4.0
+var RequestRestart = c.RequestRestart
+
+// WithToleranceExempt is a WorkerOpt that specifies that the failures of the
+// worker must not be accounted on the restart tolerance of its supervisor (e.g.
+// for workers that are known to be flaky). The failures and restarts of the
+// worker are still reported on events and restart metrics.
+//
+// An exempt worker is restarted as any other worker: with the OneForAll
+// strategy, its failures restart its siblings as well, but they never make the
+// supervisor give up and escalate the failure to its parent. Errors on the
+// restart of the siblings are still accounted on the restart tolerance.
+//
+// Since: 0.4.0
+var WithToleranceExempt = c.WithToleranceExempt
+
+// WithMinRuntime is a WorkerOpt that specifies the time the worker must run
+// before its failures count as a single restart on the restart tolerance of its
+// supervisor. The failures that happen earlier are treated as crashes on boot,
+// and they count as weight restarts instead, which makes the supervisor give
+// up sooner on a worker that fails right after it starts.
+//
+// With a weight greater than the maximum restarts of the supervisor (see
+// WithRestartTolerance), a crash on boot makes the supervisor give up
+// immediately. Errors on the restart of the worker are crashes on boot as well.
+//
+// Since: 0.4.0
+var WithMinRuntime = c.WithMinRuntime
+
+// WithRestartDecider is a WorkerOpt that specifies a predicate that decides if
+// an error returned by the worker warrants a restart (e.g. a context.Canceled
+// error doesn't, but a network error does). The predicate overrides the restart
+// semantics of the worker's Restart setting for errors; clean exits are still
+// handled according to the Restart setting. When the predicate returns true,
+// the worker is restarted as any other failed worker (the failure is accounted
+// on the restart tolerance of its supervisor).
+//
+// Since: 0.4.0
+var WithRestartDecider = c.WithRestartDecider
+
+// WithOnTerminate is a WorkerOpt that specifies a finalizer (e.g. to flush a
+// buffer) that the parent supervisor calls when it terminates the worker. The
+// finalizer is called after the worker's context is cancelled, while the
+// supervisor waits for the worker; both the finalizer and the worker must
+// finish within the worker's Shutdown setting, otherwise the termination fails
+// with ErrShutdownTimeout. An error returned by the
+// finalizer is reported on the SupervisorTerminationError of the supervisor,
+// and it matches ErrOnTerminateFailed.
+//
+// Since: 0.4.0
+var WithOnTerminate = c.WithOnTerminate
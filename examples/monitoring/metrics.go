@@ -15,7 +15,21 @@ var (
 		prometheus.GaugeOpts{
 			Name: "supervisor_event_gauge",
 		},
-		[]string{"type", "process_name"},
+		[]string{"type", "process_name", "tier"},
+	)
+
+	backoffGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "supervisor_children_in_backoff",
+		},
+		[]string{"process_name"},
+	)
+
+	groupRestartCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "supervisor_group_restarts_total",
+		},
+		[]string{"process_name"},
 	)
 )
 
@@ -23,7 +37,23 @@ var (
 
 // This is an cap.EventNotifier that registers capataz' Events to prometheus
 func promEventNotifier(ev cap.Event) {
-	gauge := eventGauge.WithLabelValues(ev.GetTag().String(), ev.GetProcessRuntimeName())
+	switch ev.GetTag() {
+	case cap.ChildEnteredBackoff:
+		backoffGauge.WithLabelValues(ev.GetProcessRuntimeName()).Inc()
+		return
+	case cap.ChildExitedBackoff:
+		backoffGauge.WithLabelValues(ev.GetProcessRuntimeName()).Dec()
+		return
+	case cap.GroupRestarted:
+		// the process name is the supervisor that restarted its children
+		groupRestartCounter.WithLabelValues(ev.GetProcessRuntimeName()).Inc()
+		return
+	}
+
+	// the tier tag of the workers (see cap.WithTags) is promoted to a label
+	gauge := eventGauge.WithLabelValues(
+		ev.GetTag().String(), ev.GetProcessRuntimeName(), ev.GetTags()["tier"],
+	)
 	if ev.GetTag() == cap.ProcessStarted {
 		gauge.Inc()
 	} else {
@@ -4,6 +4,8 @@ require (
 	github.com/prometheus/client_golang v1.11.1
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/time v0.3.0
+	gopkg.in/yaml.v2 v2.3.0
 )
 
 require (
@@ -19,7 +21,6 @@ require (
 	github.com/prometheus/procfs v0.6.0 // indirect
 	golang.org/x/sys v0.1.0 // indirect
 	google.golang.org/protobuf v1.26.0-rc.1 // indirect
-	gopkg.in/yaml.v2 v2.3.0 // indirect
 )
 
 go 1.19
@@ -0,0 +1,169 @@
+package c
+
+// This file contains the bookkeeping of the children that have a circuit
+// breaker (see WithCircuitBreaker)
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker of a child
+type CircuitState uint32
+
+const (
+	// CircuitClosed indicates the child is restarted by its supervisor when it
+	// fails
+	CircuitClosed CircuitState = iota
+	// CircuitOpen indicates the child failed too many times in a row, and it
+	// is not running until its cooldown period is over
+	CircuitOpen
+	// CircuitHalfOpen indicates the child is running as a trial after its
+	// cooldown period; it fails over to CircuitOpen on the next failure
+	CircuitHalfOpen
+)
+
+func (cs CircuitState) String() string {
+	switch cs {
+	case CircuitClosed:
+		return "Closed"
+	case CircuitOpen:
+		return "Open"
+	case CircuitHalfOpen:
+		return "HalfOpen"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// circuitBreaker keeps track of the consecutive failures of a child with a
+// circuit breaker
+type circuitBreaker struct {
+	state    CircuitState
+	failures uint32
+	trials   uint32
+	openedAt time.Time
+}
+
+// GetCircuitState returns the state of the circuit breaker of this child;
+// children without a circuit breaker are always CircuitClosed
+func (c Child) GetCircuitState() CircuitState {
+	return c.circuit.state
+}
+
+// GetCircuitTrialCount returns the number of times the circuit breaker of this
+// child has been half-open
+func (c Child) GetCircuitTrialCount() uint32 {
+	return c.circuit.trials
+}
+
+// RegisterCircuitFailure returns a copy of this Child that accounts the
+// failure that happened at the given time on its circuit breaker; the circuit
+// is opened when the child fails on a trial, or when the child reaches its
+// consecutive failures threshold. It does nothing when the child has no
+// circuit breaker.
+func (c Child) RegisterCircuitFailure(now time.Time) Child {
+	if !c.spec.HasCircuitBreaker() {
+		return c
+	}
+
+	if c.circuit.state != CircuitHalfOpen {
+		// a child that was running for a whole cooldown period did not fail
+		// in a row
+		if now.Sub(c.createdAt) >= c.spec.circuitBreakerCooldown {
+			c.circuit.failures = 0
+		}
+		c.circuit.failures++
+		if c.circuit.failures < c.spec.circuitBreakerThreshold {
+			return c
+		}
+	}
+
+	c.circuit.state = CircuitOpen
+	c.circuit.failures = 0
+	c.circuit.openedAt = now
+	return c
+}
+
+// HalfOpenCircuit returns a copy of this Child with a half-open circuit
+// breaker; the next start of the child is a trial.
+func (c Child) HalfOpenCircuit() Child {
+	c.circuit.state = CircuitHalfOpen
+	c.circuit.trials++
+	return c
+}
+
+// CloseCircuit returns a copy of this Child with a closed circuit breaker and
+// no failures accounted.
+func (c Child) CloseCircuit() Child {
+	c.circuit.state = CircuitClosed
+	c.circuit.failures = 0
+	return c
+}
+
+// IsCircuitCooldownExpired indicates if the child that emitted this
+// notification was waiting for the cooldown period of its open circuit breaker
+// and it is ready to be started as a trial.
+func (ce ChildNotification) IsCircuitCooldownExpired() bool {
+	return ce.cooldownExpired
+}
+
+// doCooldown spawns a goroutine that stands for a child with an open circuit
+// breaker. The child's Start function is not called; instead, the goroutine
+// waits for the remaining cooldown period and then notifies the supervisor.
+//
+// When the returned Child is terminated before the cooldown is over, the
+// goroutine finishes without a notification, as the child was never running.
+func (chSpec ChildSpec) doCooldown(
+	startCtx context.Context,
+	supName string,
+	supNotifyChan chan<- ChildNotification,
+	prevCh Child,
+) Child {
+	chRuntimeName := strings.Join(
+		[]string{supName, chSpec.GetName()},
+		GetNodeSeparator(startCtx),
+	)
+
+	ctx, cancelFn := withShutdownDeadline(WithoutCancel(startCtx))
+	terminateCh := make(chan ChildNotification)
+
+	cooldown := prevCh.circuit.openedAt.Add(chSpec.circuitBreakerCooldown).Sub(clockNow(startCtx))
+	cooldownDone := clockAfter(startCtx, cooldown)
+
+	go func() {
+		defer close(terminateCh)
+		defer cancelFn(time.Time{})
+
+		select {
+		case <-cooldownDone:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case supNotifyChan <- ChildNotification{
+			name:            chSpec.GetName(),
+			tag:             chSpec.GetTag(),
+			runtimeName:     chRuntimeName,
+			restartCount:    prevCh.restartCount,
+			cooldownExpired: true,
+		}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return Child{
+		runtimeName:  chRuntimeName,
+		createdAt:    clockNow(startCtx),
+		restartCount: prevCh.restartCount,
+		panicCount:   prevCh.panicCount,
+		budget:       prevCh.budget,
+		circuit:      prevCh.circuit,
+		fallback:     prevCh.fallback,
+		spec:         chSpec,
+		cancel:       cancelFn,
+		wait:         waitTimeout(terminateCh),
+	}
+}
@@ -1,5 +1,11 @@
 package c
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // GetName returns the specified name for a Child Spec
@@ -11,7 +17,114 @@ func (chSpec ChildSpec) GetName() string {
 // The first return value is false if the worker is already terminated. The
 // second return value is non-nil when the child fails to terminate. If the
 // first return value is true, the second return value will always be nil.
+//
+// The context of the child reports a deadline (see context.Context.Deadline)
+// when its Shutdown setting is a Timeout, so that the child can plan its
+// cleanup accordingly.
+//
+// When the child has a finalizer (see WithOnTerminate), it is called after the
+// context of the child is cancelled, while the child is waited for; both must
+// finish within the Shutdown setting of the child.
 func (ch Child) Terminate() (bool, error) {
-	ch.cancel()
-	return ch.wait(ch.spec.Shutdown)
+	shutdown := ch.spec.Shutdown
+	deadline := shutdownDeadline(shutdown, time.Now())
+	ch.cancel(deadline)
+	return ch.waitWithFinalizer(shutdown, deadline)
+}
+
+// TerminateBefore behaves like Terminate, with the difference that it doesn't
+// wait for the child after the given deadline, even when the Shutdown setting
+// of the child allows it. When the deadline is reached, the returned error
+// matches ErrAbandoned.
+func (ch Child) TerminateBefore(deadline time.Time) (bool, error) {
+	shutdown := ch.spec.Shutdown
+	if shutdown.tag == timeoutT && shutdown.duration <= remainingTime(deadline) {
+		// the child Shutdown setting expires before the deadline
+		return ch.Terminate()
+	}
+
+	ch.cancel(deadline)
+
+	ok, err := ch.waitWithFinalizer(Timeout(remainingTime(deadline)), deadline)
+	if err == ErrShutdownTimeout {
+		return ok, WrapSentinel(ErrAbandoned, err, "child abandoned after termination deadline")
+	}
+	return ok, err
+}
+
+// remainingTime returns the duration until the given deadline, or zero when
+// the deadline already passed
+func remainingTime(deadline time.Time) time.Duration {
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// newOnTerminate returns the function a Child uses to call the given finalizer
+// when it gets terminated; the finalizer is called at most once, with a
+// context that reports the given shutdown deadline (if any).
+func newOnTerminate(
+	ctx context.Context,
+	finalizer func(context.Context) error,
+) func(time.Time) error {
+	if finalizer == nil {
+		return nil
+	}
+	var once sync.Once
+	return func(deadline time.Time) error {
+		var err error
+		once.Do(func() {
+			finalizerCtx := ctx
+			if !deadline.IsZero() {
+				var cancelFn func()
+				finalizerCtx, cancelFn = context.WithDeadline(ctx, deadline)
+				defer cancelFn()
+			}
+			err = finalizer(finalizerCtx)
+		})
+		return err
+	}
+}
+
+// waitWithFinalizer calls the finalizer of the child, if it has one, while it
+// waits for the child with the given Shutdown setting; the finalizer must
+// finish before the given deadline (if any), otherwise the returned error is
+// ErrShutdownTimeout.
+func (ch Child) waitWithFinalizer(shutdown Shutdown, deadline time.Time) (bool, error) {
+	if ch.onTerminate == nil {
+		return ch.wait(shutdown)
+	}
+
+	finalizerCh := make(chan error, 1)
+	go func() {
+		finalizerCh <- ch.onTerminate(deadline)
+	}()
+
+	ok, err := ch.wait(shutdown)
+	if err == ErrShutdownTimeout {
+		return ok, err
+	}
+
+	var finalizerErr error
+	if deadline.IsZero() {
+		finalizerErr = <-finalizerCh
+	} else {
+		select {
+		case finalizerErr = <-finalizerCh:
+		case <-time.After(remainingTime(deadline)):
+			return ok, ErrShutdownTimeout
+		}
+	}
+	return ok, withFinalizerErr(err, finalizerErr)
+}
+
+// withFinalizerErr merges the error of a child's finalizer into the given
+// termination error; the termination error of the child takes precedence.
+func withFinalizerErr(err, finalizerErr error) error {
+	if err != nil || finalizerErr == nil {
+		return err
+	}
+	return WrapSentinel(ErrOnTerminateFailed, finalizerErr, "child finalizer failed: %v", finalizerErr)
 }
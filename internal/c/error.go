@@ -0,0 +1,104 @@
+package c
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrShutdownTimeout is reported when a child doesn't terminate before its
+	// Shutdown timeout expires
+	ErrShutdownTimeout = errors.New("child shutdown timeout")
+	// ErrStartTimeout is reported when a child doesn't notify its start before
+	// its start timeout expires
+	ErrStartTimeout = errors.New("child start timeout")
+	// ErrAbandoned is reported when a child doesn't terminate before the
+	// termination deadline of its supervision tree
+	ErrAbandoned = errors.New("child abandoned after termination deadline")
+	// ErrNotPausable is reported when a pause signal is sent to a child that
+	// cannot be paused
+	ErrNotPausable = errors.New("worker cannot be paused")
+	// ErrPauseStateUnchanged is reported when a pause signal is sent to a child
+	// that is already in the requested state
+	ErrPauseStateUnchanged = errors.New("worker already received pause signal")
+	// ErrPauseSignalPending is reported when a pause signal is sent to a child
+	// that has not read the previous signal yet
+	ErrPauseSignalPending = errors.New("worker has not read its previous pause signal")
+	// ErrInvalidChildSpec is reported when a child spec has invalid settings
+	// (e.g. an empty name or a nil start function)
+	ErrInvalidChildSpec = errors.New("invalid child spec")
+	// ErrOnTerminateFailed is reported when the finalizer of a child (see
+	// WithOnTerminate) returns an error
+	ErrOnTerminateFailed = errors.New("child finalizer failed")
+	// ErrExitedBeforeStart is reported when the start function of a child
+	// returns without errors before it notifies its start
+	ErrExitedBeforeStart = errors.New("child exited before notifying its start")
+)
+
+// sentinelError is an error with a human-readable message that matches a
+// sentinel error when using errors.Is, and that wraps an optional cause.
+type sentinelError struct {
+	msg      string
+	sentinel error
+	cause    error
+}
+
+// WrapSentinel returns an error with the given message that matches the given
+// sentinel error (via errors.Is); the given cause, which may be nil, is
+// returned by the Unwrap method of the error.
+func WrapSentinel(sentinel, cause error, format string, args ...interface{}) error {
+	return &sentinelError{
+		msg:      fmt.Sprintf(format, args...),
+		sentinel: sentinel,
+		cause:    cause,
+	}
+}
+
+// Error returns the message of the error
+func (err *sentinelError) Error() string {
+	return err.msg
+}
+
+// Is indicates if the given target is the sentinel error of this error
+func (err *sentinelError) Is(target error) bool {
+	return target == err.sentinel
+}
+
+// Unwrap returns the cause of this error, if any
+func (err *sentinelError) Unwrap() error {
+	return err.cause
+}
+
+// exitedBeforeStartError is the start error of a child that returned before it
+// notified its start; it behaves like the error it wraps.
+type exitedBeforeStartError struct {
+	cause error
+}
+
+// Error returns the message of the wrapped error
+func (err *exitedBeforeStartError) Error() string {
+	return err.cause.Error()
+}
+
+// Unwrap returns the error the child returned, or an ErrExitedBeforeStart
+// error when it returned nil
+func (err *exitedBeforeStartError) Unwrap() error {
+	return err.cause
+}
+
+// IsExitedBeforeStartError indicates if the given error was reported by a
+// child that returned before it notified its start. These children don't
+// report their termination to the supervisor, as they never started.
+func IsExitedBeforeStartError(err error) bool {
+	_, ok := err.(*exitedBeforeStartError)
+	return ok
+}
+
+// IsStartTimeoutError indicates if the given error was reported by a child
+// that did not notify its start on time. Errors coming from nested supervisors
+// are not considered, even when they were originated by a start timeout deeper
+// in the tree.
+func IsStartTimeoutError(err error) bool {
+	sentinelErr, ok := err.(*sentinelError)
+	return ok && sentinelErr.sentinel == ErrStartTimeout
+}
@@ -0,0 +1,93 @@
+package c
+
+// This file contains the bookkeeping of the children that are started on
+// demand (see WithLazyStart)
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// lazyStart keeps track of a child that waits for its lazy start trigger
+type lazyStart struct {
+	pending   bool
+	triggered bool
+}
+
+// IsLazyPending indicates if this child is registered on its supervisor, but
+// it is not running because its lazy start trigger didn't fire yet
+func (c Child) IsLazyPending() bool {
+	return c.lazy.pending
+}
+
+// TriggerLazyStart returns a copy of this Child that is started for the first
+// time on its next restart. It does nothing when the child is not waiting for
+// its lazy start trigger.
+func (c Child) TriggerLazyStart() Child {
+	if c.lazy.pending {
+		c.lazy.triggered = true
+	}
+	return c
+}
+
+// IsLazyStartTriggered indicates if the child that emitted this notification
+// was waiting for its lazy start trigger, and it is ready to be started.
+func (ce ChildNotification) IsLazyStartTriggered() bool {
+	return ce.lazyTriggered
+}
+
+// doAwaitTrigger spawns a goroutine that stands for a child that waits for its
+// lazy start trigger. The child's Start function is not called; instead, the
+// goroutine waits for the trigger to fire and then notifies the supervisor.
+//
+// When the returned Child is terminated before the trigger fires, the
+// goroutine finishes without a notification, as the child was never running.
+func (chSpec ChildSpec) doAwaitTrigger(
+	startCtx context.Context,
+	supName string,
+	supNotifyChan chan<- ChildNotification,
+	prevCh Child,
+) Child {
+	chRuntimeName := strings.Join(
+		[]string{supName, chSpec.GetName()},
+		GetNodeSeparator(startCtx),
+	)
+
+	ctx, cancelFn := withShutdownDeadline(
+		setSupervisorName(setNodeName(WithoutCancel(startCtx), chRuntimeName), supName),
+	)
+	terminateCh := make(chan ChildNotification)
+
+	go func() {
+		defer close(terminateCh)
+		defer cancelFn(time.Time{})
+
+		select {
+		case <-chSpec.lazyStart(ctx):
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case supNotifyChan <- ChildNotification{
+			name:          chSpec.GetName(),
+			tag:           chSpec.GetTag(),
+			runtimeName:   chRuntimeName,
+			restartCount:  prevCh.restartCount,
+			lazyTriggered: true,
+		}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return Child{
+		runtimeName:  chRuntimeName,
+		createdAt:    clockNow(startCtx),
+		restartCount: prevCh.restartCount,
+		lazy:         lazyStart{pending: true},
+		spec:         chSpec,
+		cancel:       cancelFn,
+		wait:         waitTimeout(terminateCh),
+	}
+}
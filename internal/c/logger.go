@@ -0,0 +1,88 @@
+package c
+
+import (
+	"context"
+	"strings"
+)
+
+// Logger is the minimal logging interface the supervision tree provides to its
+// children. The messages are accompanied by key-value pairs, so that it can be
+// adapted to structured logging libraries (e.g. slog, zap or zerolog).
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// rootLoggerKey is the key used to store the Logger of a supervision tree in
+// the supervisor context
+var rootLoggerKey capatazKey = "__capataz.supervisor.logger__"
+
+// nodeLoggerKey is the key used to store the Logger of a child in the child
+// context
+var nodeLoggerKey capatazKey = "__capataz.node.logger__"
+
+// WithRootLogger sets the Logger from which the loggers of the children
+// started with the returned context are derived.
+func WithRootLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, rootLoggerKey, logger)
+}
+
+// setNodeLogger sets a Logger scoped to the given runtime name in the given
+// context, if the context has a root logger
+func setNodeLogger(ctx context.Context, runtimeName string) context.Context {
+	logger, ok := ctx.Value(rootLoggerKey).(Logger)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, nodeLoggerKey, scopedLogger{logger: logger, prefix: runtimeName})
+}
+
+// LoggerFromContext returns the Logger of the node that was started with the
+// given context; the messages of this logger are prefixed with the runtime
+// name of the node. When the supervision tree doesn't have a logger, a Logger
+// that discards every message is returned.
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(nodeLoggerKey).(Logger); ok {
+		return logger
+	}
+	return nopLogger{}
+}
+
+// scopedLogger is a Logger that prefixes every message with the runtime name
+// of a node
+type scopedLogger struct {
+	logger Logger
+	prefix string
+}
+
+func (sl scopedLogger) scope(msg string) string {
+	return strings.Join([]string{sl.prefix, msg}, ": ")
+}
+
+// Debug logs a debug message prefixed with the node's runtime name
+func (sl scopedLogger) Debug(msg string, keyvals ...interface{}) {
+	sl.logger.Debug(sl.scope(msg), keyvals...)
+}
+
+// Info logs an info message prefixed with the node's runtime name
+func (sl scopedLogger) Info(msg string, keyvals ...interface{}) {
+	sl.logger.Info(sl.scope(msg), keyvals...)
+}
+
+// Error logs an error message prefixed with the node's runtime name
+func (sl scopedLogger) Error(msg string, keyvals ...interface{}) {
+	sl.logger.Error(sl.scope(msg), keyvals...)
+}
+
+// nopLogger is a Logger that discards every message
+type nopLogger struct{}
+
+// Debug discards the given message
+func (nopLogger) Debug(string, ...interface{}) {}
+
+// Info discards the given message
+func (nopLogger) Info(string, ...interface{}) {}
+
+// Error discards the given message
+func (nopLogger) Error(string, ...interface{}) {}
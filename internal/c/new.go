@@ -60,7 +60,34 @@ func NewWithNotifyStart(
 	startFn func(context.Context, NotifyStartFn) error,
 	opts ...Opt,
 ) ChildSpec {
-	spec := ChildSpec{
+	if name == "" {
+		panic("Child cannot have empty name")
+	}
+
+	if startFn == nil {
+		panic(fmt.Sprintf("Child %s cannot have empty start function\n", name))
+	}
+
+	spec := newBaseSpec(name, startFn)
+
+	// apply options
+	for _, optFn := range opts {
+		optFn(&spec)
+	}
+	spec.Start = startFn
+	spec.opts = opts
+
+	// return spec
+	return spec
+}
+
+// newBaseSpec returns a ChildSpec with the given name and start function, and
+// the default settings of a child; no options are applied to it.
+func newBaseSpec(name string, startFn func(context.Context, NotifyStartFn) error) ChildSpec {
+	return ChildSpec{
+		Name:  name,
+		Start: startFn,
+
 		// Child workers by default will have 5 seconds to terminate before
 		// reporting a timeout error as specified on the Erlang OTP documentation.
 		// http://erlang.org/doc/design_principles/sup_princ.html#tuning-the-intensity-and-period
@@ -76,22 +103,32 @@ func NewWithNotifyStart(
 		// All panics are going to be supervised by default
 		CapturePanic: true,
 	}
+}
 
-	if name == "" {
-		panic("Child cannot have empty name")
-	}
-	spec.Name = name
-
-	if startFn == nil {
-		panic(fmt.Sprintf("Child %s cannot have empty start function\n", name))
-	}
-
-	// apply options
-	for _, optFn := range opts {
-		optFn(&spec)
-	}
-	spec.Start = startFn
-
-	// return spec
+// NewWithPause accomplishes the same goal as `New` with the addition of passing
+// a channel to the `start` parameter, from where the worker receives the pause
+// and resume signals sent by its parent supervisor.
+//
+// ### The pause channel argument
+//
+// When the worker receives a `PauseRequested` signal, it should stop its
+// processing, keeping the resources it has allocated, until it receives a
+// `ResumeRequested` signal. The worker must keep honoring the given
+// `context.Context` while it is paused.
+func NewWithPause(
+	name string,
+	startFn func(context.Context, <-chan PauseSignal) error,
+	opts ...Opt,
+) ChildSpec {
+	// the buffer allows the supervisor to send a signal without blocking
+	pauseCh := make(chan PauseSignal, 1)
+	spec := New(
+		name,
+		func(ctx context.Context) error {
+			return startFn(ctx, pauseCh)
+		},
+		opts...,
+	)
+	spec.pauseCh = pauseCh
 	return spec
 }
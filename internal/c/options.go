@@ -1,6 +1,9 @@
 package c
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // WithRestart specifies how the parent supervisor should restart this worker
 // after an error is encountered.
@@ -12,12 +15,205 @@ func WithRestart(r Restart) Opt {
 
 // WithCapturePanic specifies if panics raised by this worker should be treated
 // as errors. restartable errors.
+//
+// Panics are recovered unless the supervisor disables the panic recovery of
+// its children, regardless of this setting.
 func WithCapturePanic(capture bool) Opt {
 	return func(spec *ChildSpec) {
 		spec.CapturePanic = capture
 	}
 }
 
+// WithPanicEscalation specifies that the parent supervisor must give up
+// restarting this worker once it has panicked the given number of times,
+// regardless of the supervisor's restart tolerance. This setting requires the
+// worker to capture panics.
+func WithPanicEscalation(afterPanics uint32) Opt {
+	return func(spec *ChildSpec) {
+		spec.panicEscalation = afterPanics
+	}
+}
+
+// WithHealthCheck registers a probe function that reports if this worker is
+// healthy. The probe is invoked by the supervisor HealthCheck method, and it
+// must return an error when the worker is not healthy.
+func WithHealthCheck(probe func(context.Context) error) Opt {
+	return func(spec *ChildSpec) {
+		spec.healthCheck = probe
+	}
+}
+
+// WithTags attaches the given labels to this worker (e.g. team=payments). The
+// tags are copied, so changes on the given map after this call do not affect
+// the worker.
+func WithTags(tags map[string]string) Opt {
+	tags = copyTags(tags)
+	return func(spec *ChildSpec) {
+		spec.tags = tags
+	}
+}
+
+// WithPeriodicRestart specifies that the parent supervisor must restart this
+// worker every time the given interval expires, regardless of failures. The
+// worker's context is cancelled (with the deadline of its shutdown setting),
+// and the worker is started again once it finishes. The interval starts over
+// on every restart of the worker.
+func WithPeriodicRestart(interval time.Duration) Opt {
+	return func(spec *ChildSpec) {
+		spec.periodicRestart = interval
+	}
+}
+
+// WithToleranceExempt specifies that the failures of this worker must not be
+// accounted on the restart tolerance of the parent supervisor. The worker is
+// still restarted (as well as its siblings, depending on the supervisor
+// strategy) when it fails.
+func WithToleranceExempt() Opt {
+	return func(spec *ChildSpec) {
+		spec.toleranceExempt = true
+	}
+}
+
+// WithMinRuntime specifies that the failures that happen before this worker
+// runs for the given duration account for the given number of restarts
+// (weight) on the restart tolerance of the parent supervisor, rather than for
+// one. It makes the supervisor give up sooner on workers that crash on boot.
+func WithMinRuntime(d time.Duration, weight uint32) Opt {
+	return func(spec *ChildSpec) {
+		spec.minRuntime = d
+		spec.minRuntimeWeight = weight
+	}
+}
+
+// WithRestartDecider specifies a predicate that decides if an error returned
+// by this worker warrants a restart, overriding the restart semantics of the
+// worker's Restart setting for errors (e.g. a Permanent worker is not restarted
+// when the predicate returns false). Clean exits are still handled according
+// to the Restart setting.
+func WithRestartDecider(decider func(error) bool) Opt {
+	return func(spec *ChildSpec) {
+		spec.restartDecider = decider
+	}
+}
+
+// WithOnTerminate specifies a finalizer (e.g. to flush a buffer) that the
+// parent supervisor calls when it terminates this worker. The finalizer is
+// called once the worker's context is cancelled, and the supervisor waits for
+// both the finalizer and the worker within the worker's Shutdown setting, a
+// finalizer that overruns it is reported as a shutdown timeout; the context
+// given to the finalizer reports the shutdown deadline.
+func WithOnTerminate(finalizer func(context.Context) error) Opt {
+	return func(spec *ChildSpec) {
+		spec.onTerminate = finalizer
+	}
+}
+
+// WithFallback specifies the start function the parent supervisor uses to
+// restart this worker once the restart tolerance of the supervisor is
+// exhausted because of the worker's failures. From then on, the worker runs
+// the fallback with Permanent semantics.
+func WithFallback(fallback func(context.Context, NotifyStartFn) error) Opt {
+	return func(spec *ChildSpec) {
+		spec.fallback = fallback
+	}
+}
+
+// WithCircuitBreaker specifies that the parent supervisor must stop restarting
+// this worker after it fails failureThreshold consecutive times. Once the
+// circuit is open, the worker is not running for the given cooldown; after
+// that, the circuit is half-open and the worker is started once as a trial.
+// When the trial worker runs for a whole cooldown period without failing, the
+// circuit is closed again; when it fails, the circuit opens again.
+//
+// A worker that runs for a whole cooldown period without failing resets its
+// consecutive failure count. The failures of the worker are accounted by the
+// circuit breaker, and not by the restart tolerance of the parent supervisor.
+func WithCircuitBreaker(failureThreshold uint32, cooldown time.Duration) Opt {
+	return func(spec *ChildSpec) {
+		spec.circuitBreakerThreshold = failureThreshold
+		spec.circuitBreakerCooldown = cooldown
+	}
+}
+
+// WithStartPhase specifies the phase in which the parent supervisor starts this
+// worker. The supervisor starts its children in ascending phase order, and all
+// the children of a phase are started before any child of the next phase; the
+// start order of the supervisor applies within a phase. Children are
+// terminated in the reverse order. The default phase is zero.
+func WithStartPhase(n int) Opt {
+	return func(spec *ChildSpec) {
+		spec.startPhase = n
+	}
+}
+
+// WithStartPriority specifies the priority of this worker among the children
+// of its start phase. Within a phase, children with a higher priority are
+// started first (and terminated last); the start order of the supervisor
+// applies to children with the same priority. The default priority is zero.
+func WithStartPriority(p int) Opt {
+	return func(spec *ChildSpec) {
+		spec.startPriority = p
+	}
+}
+
+// WithStartTimeout specifies that the parent supervisor must give up on this
+// worker when it doesn't notify its start within the given duration. The
+// worker's context is cancelled, and the worker gets its shutdown grace period
+// (see WithShutdown) to finish before the start failure is reported.
+func WithStartTimeout(d time.Duration) Opt {
+	return func(spec *ChildSpec) {
+		spec.startTimeout = d
+	}
+}
+
+// WithLazyStart specifies that the parent supervisor must not start this
+// worker until the channel returned by the given trigger fires (it is closed
+// or it receives a value). Until then, the worker is registered on the
+// supervisor, but it is not running. Once started, the worker is supervised
+// (and restarted) as any other worker.
+//
+// The trigger is called every time the worker waits for its first start (e.g.
+// when the supervisor restarts its siblings before the trigger fired), the
+// given context is done when the supervisor terminates the waiting worker.
+func WithLazyStart(trigger func(context.Context) <-chan struct{}) Opt {
+	return func(spec *ChildSpec) {
+		spec.lazyStart = trigger
+	}
+}
+
+// WithRetireAfter specifies that the parent supervisor must not restart this
+// worker when it fails or completes after the given time; before that time the
+// worker is restarted according to its Restart setting.
+func WithRetireAfter(t time.Time) Opt {
+	return func(spec *ChildSpec) {
+		spec.retireAfter = t
+	}
+}
+
+// WithTransientBudget specifies that a Transient worker may fail at most n
+// times within the given window; once the budget is exceeded, the parent
+// supervisor stops restarting the worker (as if it was Temporary) rather than
+// escalating the failure. Completions without an error do not count against
+// the budget.
+func WithTransientBudget(n uint32, window time.Duration) Opt {
+	return func(spec *ChildSpec) {
+		spec.transientBudget = n
+		spec.transientBudgetWindow = window
+	}
+}
+
+// WithDependsOn specifies the names of the siblings this worker depends on.
+// The worker starts after its dependencies, and it is restarted after them
+// when the parent supervisor restarts one of them, whatever its strategy.
+// Every name must belong to a sibling of the worker that does not start in a
+// later phase (see WithStartPhase), and the dependencies must not form a cycle,
+// otherwise the parent supervisor fails to build.
+func WithDependsOn(names ...string) Opt {
+	return func(spec *ChildSpec) {
+		spec.dependsOn = append(spec.dependsOn, names...)
+	}
+}
+
 // WithShutdown specifies how the shutdown of the worker is going to be handled.
 // Read `Indefinitely` and `Timeout` shutdown values documentation for details.
 func WithShutdown(s Shutdown) Opt {
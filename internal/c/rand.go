@@ -0,0 +1,44 @@
+package c
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// rootSeedKey is the key used to store the seed of a supervision tree in the
+// supervisor context
+var rootSeedKey capatazKey = "__capataz.supervisor.seed__"
+
+// WithRootSeed sets the seed from which the random sources of the nodes
+// started with the returned context are derived.
+func WithRootSeed(ctx context.Context, seed int64) context.Context {
+	return context.WithValue(ctx, rootSeedKey, seed)
+}
+
+// RandFromContext returns a random source for the node that was started with
+// the given context. When the supervision tree has a seed, the source is
+// derived from the seed and the runtime name of the node, so every start of
+// the node gets the same sequence of values, and different nodes get different
+// sequences. Otherwise, the source is seeded with the current time.
+//
+// The returned source is not shared with other nodes, and it is not safe for
+// concurrent use.
+func RandFromContext(ctx context.Context) *rand.Rand {
+	seed, ok := ctx.Value(rootSeedKey).(int64)
+	if !ok {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	nodeName, _ := GetNodeName(ctx)
+	return rand.New(rand.NewSource(deriveSeed(seed, nodeName)))
+}
+
+// deriveSeed combines the given seed with the given runtime name, so that each
+// node of a supervision tree draws from its own source
+func deriveSeed(seed int64, runtimeName string) int64 {
+	h := fnv.New64a()
+	// writes on a hash.Hash never fail
+	_, _ = h.Write([]byte(runtimeName))
+	return seed ^ int64(h.Sum64())
+}
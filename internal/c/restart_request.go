@@ -0,0 +1,41 @@
+package c
+
+// This file contains the logic that allows a child to request its own restart
+// (see RequestRestart)
+
+import (
+	"context"
+)
+
+// restartRequesterKey is the key used to store the function that requests the
+// restart of a child in the child context
+var restartRequesterKey capatazKey = "__capataz.node.restart_requester__"
+
+// setRestartRequester adds the function that requests the restart of a child
+// to a context
+func setRestartRequester(ctx context.Context, requester func()) context.Context {
+	return context.WithValue(ctx, restartRequesterKey, requester)
+}
+
+// RequestRestart asks the parent supervisor of the child that was started with
+// the given context to restart it. The context of the child is cancelled with
+// the deadline of its shutdown setting, and once the child finishes, the
+// supervisor starts it again without accounting the restart on its restart
+// tolerance; the error returned by the child, if any, is ignored.
+//
+// It returns false when the context doesn't belong to a child of a supervision
+// tree.
+func RequestRestart(ctx context.Context) bool {
+	requester, ok := ctx.Value(restartRequesterKey).(func())
+	if !ok {
+		return false
+	}
+	requester()
+	return true
+}
+
+// IsRestartRequested indicates if the child that emitted this notification
+// finished because it requested its own restart (see RequestRestart)
+func (ce ChildNotification) IsRestartRequested() bool {
+	return ce.restartRequested
+}
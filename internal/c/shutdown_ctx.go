@@ -0,0 +1,60 @@
+package c
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// shutdownCtx is a context that reports the deadline a child has to finish
+// once its parent supervisor starts terminating it. The deadline is not set
+// until the child gets terminated.
+type shutdownCtx struct {
+	context.Context
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// withShutdownDeadline returns a context that reports a shutdown deadline, and
+// a function that cancels the context after setting the given deadline; a
+// zero deadline cancels the context without a deadline.
+func withShutdownDeadline(ctx context.Context) (context.Context, func(time.Time)) {
+	sctx := &shutdownCtx{Context: ctx}
+	cancelCtx, cancelFn := context.WithCancel(sctx)
+	return cancelCtx, func(deadline time.Time) {
+		sctx.setDeadline(deadline)
+		cancelFn()
+	}
+}
+
+func (sctx *shutdownCtx) setDeadline(deadline time.Time) {
+	sctx.mu.Lock()
+	defer sctx.mu.Unlock()
+	// the first termination sets the deadline
+	if sctx.deadline.IsZero() {
+		sctx.deadline = deadline
+	}
+}
+
+// Deadline returns the shutdown deadline of the child, if it is being
+// terminated, or the deadline of the parent context otherwise
+func (sctx *shutdownCtx) Deadline() (time.Time, bool) {
+	sctx.mu.Lock()
+	deadline := sctx.deadline
+	sctx.mu.Unlock()
+	if !deadline.IsZero() {
+		return deadline, true
+	}
+	return sctx.Context.Deadline()
+}
+
+// shutdownDeadline returns the time a child with the given Shutdown setting
+// has to finish when it gets terminated at the given time; the zero value is
+// returned when the child can take indefinitely.
+func shutdownDeadline(shutdown Shutdown, now time.Time) time.Time {
+	if timeout, ok := shutdown.Timeout(); ok {
+		return now.Add(timeout)
+	}
+	return time.Time{}
+}
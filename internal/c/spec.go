@@ -2,6 +2,8 @@ package c
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -100,6 +102,34 @@ func Timeout(d time.Duration) Shutdown {
 	}
 }
 
+// String returns a string representation of the Shutdown value
+func (s Shutdown) String() string {
+	switch s.tag {
+	case indefinitelyT:
+		return "Indefinitely"
+	case timeoutT:
+		return fmt.Sprintf("Timeout(%v)", s.duration)
+	default:
+		return "<Unknown>"
+	}
+}
+
+// IsIndefinitely indicates if the parent supervisor waits indefinitely for the
+// child goroutine to stop executing
+func (s Shutdown) IsIndefinitely() bool {
+	return s.tag == indefinitelyT
+}
+
+// Timeout returns the duration the parent supervisor waits for the child
+// goroutine to stop executing; the returned bool is false when the supervisor
+// waits indefinitely.
+func (s Shutdown) Timeout() (time.Duration, bool) {
+	if s.tag != timeoutT {
+		return 0, false
+	}
+	return s.duration, true
+}
+
 // startError is the error reported back to a Supervisor when the start of a
 // Child fails
 type startError = error
@@ -113,6 +143,29 @@ type startError = error
 // error value different than nil.
 type NotifyStartFn = func(startError)
 
+// PauseSignal is a value that a supervisor sends to a pausable worker to pause
+// or resume its processing.
+type PauseSignal uint32
+
+const (
+	// PauseRequested indicates a worker must stop its processing (without
+	// releasing its resources) until it gets a ResumeRequested signal
+	PauseRequested PauseSignal = iota
+	// ResumeRequested indicates a paused worker must resume its processing
+	ResumeRequested
+)
+
+func (ps PauseSignal) String() string {
+	switch ps {
+	case PauseRequested:
+		return "PauseRequested"
+	case ResumeRequested:
+		return "ResumeRequested"
+	default:
+		return "<Unknown>"
+	}
+}
+
 // ChildSpec represents a Child specification; it serves as a template for the
 // construction of a goroutine. The ChildSpec record is used in conjunction with
 // the supervisor's SupervisorSpec.
@@ -133,7 +186,107 @@ type ChildSpec struct {
 	Restart      Restart
 	CapturePanic bool
 
+	// panicEscalation is the number of panics after which the parent
+	// supervisor gives up restarting this child, zero disables the setting
+	panicEscalation uint32
+
+	// healthCheck is an optional probe that reports if the child is healthy,
+	// children without a probe are considered healthy
+	healthCheck func(context.Context) error
+
+	// subtreeCtrl is used by the parent supervisor to talk to the supervisor
+	// running on this child; it is nil on workers
+	subtreeCtrl interface{}
+
+	// pauseCh is used by the parent supervisor to send pause and resume signals
+	// to the worker; it is nil on workers that cannot be paused
+	pauseCh chan PauseSignal
+
+	// retireAfter is the time after which the parent supervisor stops
+	// restarting this child, the zero value disables the setting
+	retireAfter time.Time
+
+	// dependsOn contains the names of the siblings this child depends on
+	dependsOn []string
+
+	// transientBudget is the number of failures a Transient child may have
+	// within the transientBudgetWindow before the parent supervisor stops
+	// restarting it, zero disables the setting
+	transientBudget       uint32
+	transientBudgetWindow time.Duration
+
+	// tags are arbitrary labels attached to the child (e.g. team=payments),
+	// they are reported on the events of the child; use WithTags to set them
+	tags map[string]string
+
+	// periodicRestart is the interval after which the parent supervisor
+	// restarts this child, regardless of failures; zero disables the setting
+	periodicRestart time.Duration
+
+	// fallback is the start function the parent supervisor uses to restart
+	// this child once the restart tolerance is exhausted because of its
+	// failures; it is nil when the child has no fallback
+	fallback func(context.Context, NotifyStartFn) error
+
+	// circuitBreakerThreshold is the number of consecutive failures after
+	// which the parent supervisor stops restarting this child for the
+	// circuitBreakerCooldown period, zero disables the setting
+	circuitBreakerThreshold uint32
+	circuitBreakerCooldown  time.Duration
+
+	// toleranceExempt indicates the failures of this child are not accounted
+	// on the restart tolerance of the parent supervisor
+	toleranceExempt bool
+
+	// minRuntime is the time this child must run before a failure counts as a
+	// single restart on the restart tolerance of the parent supervisor; the
+	// failures that happen before count as minRuntimeWeight restarts. Zero
+	// disables the setting
+	minRuntime       time.Duration
+	minRuntimeWeight uint32
+
+	// restartDecider decides if an error of this child warrants a restart,
+	// overriding the Restart setting of the child for errors
+	restartDecider func(error) bool
+
+	// onTerminate is a finalizer the parent supervisor calls when it
+	// terminates this child, after the child's context is cancelled; it is
+	// nil when the child has no finalizer
+	onTerminate func(context.Context) error
+
+	// startPhase is the phase in which the parent supervisor starts this
+	// child; all the children of a phase are started before the children of
+	// the next phase
+	startPhase int
+
+	// startPriority orders this child among the children of its start phase;
+	// children with a higher priority are started first
+	startPriority int
+
+	// startTimeout is the time the parent supervisor waits for this child to
+	// notify its start before it gives up on it, zero waits indefinitely
+	startTimeout time.Duration
+
+	// lazyStart returns a channel that fires when this child must be started
+	// for the first time; it is nil when the child is started with its
+	// siblings (see WithLazyStart)
+	lazyStart func(context.Context) <-chan struct{}
+
+	// spawned indicates the child was started on-demand (e.g. via a
+	// DynSupervisor), and it cannot be rebuilt from the supervisor spec
+	spawned bool
+
+	// poolName is the name prefix of the worker pool this child belongs to, it
+	// is empty when the child is not part of a pool
+	poolName string
+	// poolIndex is the index of this child in its worker pool
+	poolIndex int
+
 	Start func(context.Context, NotifyStartFn) error
+
+	// opts are the options this ChildSpec was built with, they are re-applied
+	// on top of the defaults given to ApplyDefaults
+	opts []Opt
 }
 
 // GetTag returns the ChildTag of this ChildSpec
@@ -146,12 +299,307 @@ func (chSpec ChildSpec) IsWorker() bool {
 	return chSpec.Tag == Worker
 }
 
+// AttachSubtreeCtrl returns a copy of this ChildSpec that the parent supervisor
+// may use to talk to the supervisor running on the child
+func (chSpec ChildSpec) AttachSubtreeCtrl(ctrl interface{}) ChildSpec {
+	chSpec.subtreeCtrl = ctrl
+	return chSpec
+}
+
+// GetSubtreeCtrl returns the value the parent supervisor uses to talk to the
+// supervisor running on this child, it is nil on workers
+func (chSpec ChildSpec) GetSubtreeCtrl() interface{} {
+	return chSpec.subtreeCtrl
+}
+
+// WrapStart returns a copy of this ChildSpec with its start function, and its
+// fallback start function when it has one, wrapped with the given function
+func (chSpec ChildSpec) WrapStart(
+	wrap func(func(context.Context, NotifyStartFn) error) func(context.Context, NotifyStartFn) error,
+) ChildSpec {
+	chSpec.Start = wrap(chSpec.Start)
+	if chSpec.fallback != nil {
+		chSpec.fallback = wrap(chSpec.fallback)
+	}
+	return chSpec
+}
+
+// AttachHealthCheck returns a copy of this ChildSpec that uses the given
+// health probe, replacing the probe the child was built with
+func (chSpec ChildSpec) AttachHealthCheck(probe func(context.Context) error) ChildSpec {
+	chSpec.healthCheck = probe
+	return chSpec
+}
+
+// AsSpawned returns a copy of this ChildSpec that is marked as started
+// on-demand (e.g. via a DynSupervisor)
+func (chSpec ChildSpec) AsSpawned() ChildSpec {
+	chSpec.spawned = true
+	return chSpec
+}
+
+// IsSpawned indicates if this child was started on-demand, in which case it
+// cannot be rebuilt from the supervisor spec
+func (chSpec ChildSpec) IsSpawned() bool {
+	return chSpec.spawned
+}
+
+// InPool returns a copy of this ChildSpec that belongs to the worker pool with
+// the given name prefix, at the given index
+func (chSpec ChildSpec) InPool(poolName string, poolIndex int) ChildSpec {
+	chSpec.poolName = poolName
+	chSpec.poolIndex = poolIndex
+	return chSpec
+}
+
+// GetPoolName returns the name prefix of the worker pool this child belongs
+// to, it is empty when the child is not part of a pool
+func (chSpec ChildSpec) GetPoolName() string {
+	return chSpec.poolName
+}
+
+// GetPoolIndex returns the index of this child in its worker pool
+func (chSpec ChildSpec) GetPoolIndex() int {
+	return chSpec.poolIndex
+}
+
 // GetRestart returns the Restart setting for this ChildSpec
 func (chSpec ChildSpec) GetRestart() Restart {
 	return chSpec.Restart
 }
 
+// GetStartPhase returns the phase in which the parent supervisor starts this
+// child (see WithStartPhase)
+func (chSpec ChildSpec) GetStartPhase() int {
+	return chSpec.startPhase
+}
+
+// GetStartPriority returns the priority of this child among the children of
+// its start phase (see WithStartPriority)
+func (chSpec ChildSpec) GetStartPriority() int {
+	return chSpec.startPriority
+}
+
+// GetShutdown returns the Shutdown setting for this ChildSpec
+func (chSpec ChildSpec) GetShutdown() Shutdown {
+	return chSpec.Shutdown
+}
+
+// Validate checks the settings of this ChildSpec, it returns an error that
+// matches ErrInvalidChildSpec listing every invalid setting found.
+func (chSpec ChildSpec) Validate() error {
+	var problems []string
+
+	if chSpec.Name == "" {
+		problems = append(problems, "empty name")
+	}
+	if chSpec.Start == nil {
+		problems = append(problems, "nil start function")
+	}
+	if d, ok := chSpec.Shutdown.Timeout(); ok && d < 0 {
+		problems = append(problems, fmt.Sprintf("negative shutdown timeout %v", d))
+	}
+	if chSpec.startTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("negative start timeout %v", chSpec.startTimeout))
+	}
+	if chSpec.periodicRestart < 0 {
+		problems = append(
+			problems, fmt.Sprintf("negative periodic restart %v", chSpec.periodicRestart),
+		)
+	}
+	if chSpec.transientBudgetWindow < 0 {
+		problems = append(
+			problems,
+			fmt.Sprintf("negative transient budget window %v", chSpec.transientBudgetWindow),
+		)
+	}
+	if chSpec.minRuntime < 0 {
+		problems = append(problems, fmt.Sprintf("negative min runtime %v", chSpec.minRuntime))
+	}
+	if chSpec.minRuntime > 0 && chSpec.minRuntimeWeight == 0 {
+		problems = append(problems, "zero min runtime weight")
+	}
+	if chSpec.circuitBreakerCooldown < 0 {
+		problems = append(
+			problems,
+			fmt.Sprintf("negative circuit breaker cooldown %v", chSpec.circuitBreakerCooldown),
+		)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return WrapSentinel(
+		ErrInvalidChildSpec,
+		nil,
+		"child '%s' is invalid: %s",
+		chSpec.Name,
+		strings.Join(problems, ", "),
+	)
+}
+
+// ApplyDefaults returns a copy of this ChildSpec with the given options
+// applied; the options the ChildSpec was built with take precedence over the
+// given defaults when both set the same setting.
+//
+// The copy is built from scratch with the given defaults first, and the
+// options of this ChildSpec after them, so that options that accumulate
+// values (e.g. WithDependsOn) are not applied twice.
+func (chSpec ChildSpec) ApplyDefaults(defaults []Opt) ChildSpec {
+	if len(defaults) == 0 {
+		return chSpec
+	}
+	result := newBaseSpec(chSpec.Name, chSpec.Start)
+	for _, optFn := range defaults {
+		optFn(&result)
+	}
+	for _, optFn := range chSpec.opts {
+		optFn(&result)
+	}
+	// the settings that are not given through options are kept as they are
+	result.Start = chSpec.Start
+	result.subtreeCtrl = chSpec.subtreeCtrl
+	result.pauseCh = chSpec.pauseCh
+	result.spawned = chSpec.spawned
+	result.poolName = chSpec.poolName
+	result.poolIndex = chSpec.poolIndex
+	result.opts = chSpec.opts
+	return result
+}
+
+// GetTags returns a copy of the tags attached to this ChildSpec
+func (chSpec ChildSpec) GetTags() map[string]string {
+	return copyTags(chSpec.tags)
+}
+
+// copyTags returns a copy of the given tags, nil when there are no tags
+func copyTags(tags map[string]string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
+
 // DoesCapturePanic indicates if this child handles panics
 func (chSpec ChildSpec) DoesCapturePanic() bool {
 	return chSpec.CapturePanic
 }
+
+// IsRetired indicates if the given time is past the retirement time of this
+// child; a retired child is not restarted by its parent supervisor.
+func (chSpec ChildSpec) IsRetired(now time.Time) bool {
+	return !chSpec.retireAfter.IsZero() && now.After(chSpec.retireAfter)
+}
+
+// HasTransientBudget indicates if the failures of this child are accounted
+// against a transient budget (see WithTransientBudget)
+func (chSpec ChildSpec) HasTransientBudget() bool {
+	return chSpec.Restart == Transient && chSpec.transientBudgetWindow > 0
+}
+
+// GetTransientBudget returns the number of failures this child may have
+// within the returned window before its parent supervisor stops restarting it
+// (see WithTransientBudget)
+func (chSpec ChildSpec) GetTransientBudget() (uint32, time.Duration) {
+	return chSpec.transientBudget, chSpec.transientBudgetWindow
+}
+
+// HasFallback indicates if this child has a fallback start function (see
+// WithFallback)
+func (chSpec ChildSpec) HasFallback() bool {
+	return chSpec.fallback != nil
+}
+
+// toFallback returns a copy of this ChildSpec that runs the fallback start
+// function with Permanent semantics; it returns this ChildSpec as is when it
+// has no fallback.
+func (chSpec ChildSpec) toFallback() ChildSpec {
+	if !chSpec.HasFallback() {
+		return chSpec
+	}
+	chSpec.Start = chSpec.fallback
+	chSpec.Restart = Permanent
+	chSpec.fallback = nil
+	return chSpec
+}
+
+// IsLazy indicates if this child is not started until its lazy start trigger
+// fires (see WithLazyStart)
+func (chSpec ChildSpec) IsLazy() bool {
+	return chSpec.lazyStart != nil
+}
+
+// IsToleranceExempt indicates if the failures of this child are excluded from
+// the restart tolerance of the parent supervisor (see WithToleranceExempt)
+func (chSpec ChildSpec) IsToleranceExempt() bool {
+	return chSpec.toleranceExempt
+}
+
+// ToleranceWeight returns the number of restarts a failure of this child
+// accounts for on the restart tolerance of the parent supervisor, given the
+// time the child was running before it failed (see WithMinRuntime)
+func (chSpec ChildSpec) ToleranceWeight(runtime time.Duration) uint32 {
+	if chSpec.minRuntime > 0 && runtime < chSpec.minRuntime {
+		return chSpec.minRuntimeWeight
+	}
+	return 1
+}
+
+// ShouldRestart indicates if the parent supervisor must restart this child
+// after it finished with the given error (which may be nil). Errors are given
+// to the RestartDecider of the child when it has one (see WithRestartDecider);
+// otherwise, and for clean exits, the Restart setting of the child is used.
+func (chSpec ChildSpec) ShouldRestart(err error) bool {
+	if err != nil && chSpec.restartDecider != nil {
+		return chSpec.restartDecider(err)
+	}
+	switch chSpec.Restart {
+	case Permanent:
+		return true
+	case Transient:
+		return err != nil
+	default: /* Temporary */
+		return false
+	}
+}
+
+// HasCircuitBreaker indicates if the restarts of this child are guarded by a
+// circuit breaker (see WithCircuitBreaker)
+func (chSpec ChildSpec) HasCircuitBreaker() bool {
+	return chSpec.circuitBreakerThreshold > 0
+}
+
+// GetCircuitBreakerCooldown returns the time the parent supervisor waits
+// before it restarts this child once its circuit breaker opens (see
+// WithCircuitBreaker)
+func (chSpec ChildSpec) GetCircuitBreakerCooldown() time.Duration {
+	return chSpec.circuitBreakerCooldown
+}
+
+// IsPausable indicates if this child accepts pause and resume signals
+func (chSpec ChildSpec) IsPausable() bool {
+	return chSpec.pauseCh != nil
+}
+
+// GetDependsOn returns a copy of the names of the siblings this child depends
+// on
+func (chSpec ChildSpec) GetDependsOn() []string {
+	return append([]string(nil), chSpec.dependsOn...)
+}
+
+// GetHealthCheck returns the health probe of this child, nil if the child
+// doesn't have one
+func (chSpec ChildSpec) GetHealthCheck() func(context.Context) error {
+	return chSpec.healthCheck
+}
+
+// GetPanicEscalation returns the number of panics this child may have before
+// its parent supervisor escalates the failure; zero means panics are handled
+// as any other error.
+func (chSpec ChildSpec) GetPanicEscalation() uint32 {
+	return chSpec.panicEscalation
+}
@@ -2,10 +2,9 @@ package c
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"runtime/debug"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,6 +29,109 @@ func setNodeName(ctx context.Context, name string) context.Context {
 	return context.WithValue(ctx, nodeNameKey, name)
 }
 
+// supervisorNameKey is the key used to store the runtime name of the parent
+// supervisor of a node in the node context
+var supervisorNameKey capatazKey = "__capataz.node.supervisor_name__"
+
+// SupervisorNameFromContext returns the runtime name of the supervisor of the
+// node that was started with the given context (e.g. "root/subtree1"); it
+// returns false when the context doesn't belong to a node of a supervision
+// tree.
+func SupervisorNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(supervisorNameKey).(string)
+	return name, ok
+}
+
+// setSupervisorName adds the runtime name of the parent supervisor of a node to
+// a context
+func setSupervisorName(ctx context.Context, supName string) context.Context {
+	return context.WithValue(ctx, supervisorNameKey, supName)
+}
+
+// nodeSepKey is the key used to store the token that separates the names of
+// the nodes of a supervision tree in their runtime names
+var nodeSepKey capatazKey = "__capataz.supervisor.node_separator__"
+
+// defaultNodeSep is the token used to build runtime names when the supervision
+// tree doesn't specify one
+const defaultNodeSep = "/"
+
+// WithNodeSeparator sets the token used to join the name of a supervisor and
+// the names of its children when building their runtime names.
+func WithNodeSeparator(ctx context.Context, sep string) context.Context {
+	return context.WithValue(ctx, nodeSepKey, sep)
+}
+
+// GetNodeSeparator returns the token used to build the runtime names of the
+// nodes started with the given context.
+func GetNodeSeparator(ctx context.Context) string {
+	if sep, ok := ctx.Value(nodeSepKey).(string); ok {
+		return sep
+	}
+	return defaultNodeSep
+}
+
+// panicRecoveryKey is the key used to store if the supervisor of a child
+// recovers the panics of its children
+var panicRecoveryKey capatazKey = "__capataz.supervisor.panic_recovery__"
+
+// WithPanicRecovery sets if the children started with the returned context
+// recover from panics on their goroutine; this setting takes precedence over
+// the CapturePanic setting of the children.
+func WithPanicRecovery(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, panicRecoveryKey, enabled)
+}
+
+// isPanicRecoveryEnabled indicates if the children started with the given
+// context recover from panics; it is enabled by default.
+func isPanicRecoveryEnabled(ctx context.Context) bool {
+	if enabled, ok := ctx.Value(panicRecoveryKey).(bool); ok {
+		return enabled
+	}
+	return true
+}
+
+// clockNowKey is the key used to store the function that returns the current
+// time of the supervisor of a child
+var clockNowKey capatazKey = "__capataz.supervisor.clock_now__"
+
+// WithClockNow sets the function the children started with the returned
+// context use to get the current time (e.g. to register their creation time).
+func WithClockNow(ctx context.Context, now func() time.Time) context.Context {
+	return context.WithValue(ctx, clockNowKey, now)
+}
+
+// clockNow returns the current time of the supervisor of the children started
+// with the given context; it defaults to time.Now.
+func clockNow(ctx context.Context) time.Time {
+	if now, ok := ctx.Value(clockNowKey).(func() time.Time); ok {
+		return now()
+	}
+	return time.Now()
+}
+
+// clockAfterKey is the key used to store the function that waits for a
+// duration with the clock of the supervisor of a child
+var clockAfterKey capatazKey = "__capataz.supervisor.clock_after__"
+
+// WithClockAfter sets the function the children started with the returned
+// context use to wait for a duration (e.g. the cooldown of a circuit breaker).
+func WithClockAfter(
+	ctx context.Context, after func(time.Duration) <-chan time.Time,
+) context.Context {
+	return context.WithValue(ctx, clockAfterKey, after)
+}
+
+// clockAfter returns a channel that receives a value once the given duration
+// elapsed on the clock of the supervisor of the children started with the
+// given context; it defaults to time.After.
+func clockAfter(ctx context.Context, d time.Duration) <-chan time.Time {
+	if after, ok := ctx.Value(clockAfterKey).(func(time.Duration) <-chan time.Time); ok {
+		return after(d)
+	}
+	return time.After(d)
+}
+
 // waitTimeout is the internal function used by Child to wait for the execution
 // of it's thread to stop.
 func waitTimeout(
@@ -55,7 +157,7 @@ func waitTimeout(
 				// A child may have terminated with an error
 				return true, childNotification.Unwrap()
 			case <-time.After(shutdown.duration):
-				return true, errors.New("child shutdown timeout")
+				return true, ErrShutdownTimeout
 			}
 		default:
 			// This should never happen if we use the already defined Shutdown types
@@ -66,18 +168,34 @@ func waitTimeout(
 
 // sendNotificationToSup creates a ChildNotification record and sends it to the
 // assigned supervisor for this child.
+//
+// When the start of the child timed out, the supervisor is no longer waiting
+// for the child on the `supNotifyChan`, and the notification is only sent over
+// the `terminateCh` until the given `abandonedCh` is closed.
 func sendNotificationToSup(
 	err error,
 	chSpec ChildSpec,
 	chRuntimeName string,
+	restartCount uint32,
 	supNotifyChan chan<- ChildNotification,
 	terminateCh chan<- ChildNotification,
+	startTimedOutCh <-chan struct{},
+	abandonedCh <-chan struct{},
+	periodicRestart bool,
+	restartRequested bool,
+	terminated bool,
 ) {
 	chNotification := ChildNotification{
-		name:        chSpec.GetName(),
-		tag:         chSpec.GetTag(),
-		runtimeName: chRuntimeName,
-		err:         err,
+		name:             chSpec.GetName(),
+		tag:              chSpec.GetTag(),
+		runtimeName:      chRuntimeName,
+		restartCount:     restartCount,
+		err:              err,
+		periodicRestart:  periodicRestart,
+		restartRequested: restartRequested,
+		reason: terminationReason(
+			err, terminated || periodicRestart || restartRequested,
+		),
 	}
 
 	// We send the chNotification that got created to our parent supervisor.
@@ -93,11 +211,19 @@ func sendNotificationToSup(
 	// function, which calls the `child.Terminate` method for each of the supervised
 	// internally, this function reads the `terminateCh`.
 	//
+	select {
+	case <-startTimedOutCh:
+		// a nil channel is never selected
+		supNotifyChan = nil
+	default:
+	}
+
 	select {
 	// (1)
 	case supNotifyChan <- chNotification:
 	// (2)
 	case terminateCh <- chNotification:
+	case <-abandonedCh:
 	}
 }
 
@@ -105,6 +231,9 @@ func sendNotificationToSup(
 // ChildSpec, this function will block until the spawned goroutine notifies it
 // has been initialized.
 //
+// When the ChildSpec has a lazy start trigger, the Start function is not
+// called; the returned Child waits for the trigger instead (see WithLazyStart).
+//
 // ### The supNotifyChan value
 //
 // Messages sent to this channel notify the supervisor that the child's
@@ -116,16 +245,42 @@ func (chSpec ChildSpec) DoStart(
 	supName string,
 	supNotifyChan chan<- ChildNotification,
 ) (Child, error) {
+	if chSpec.IsLazy() {
+		// the child is not started until its lazy start trigger fires
+		return chSpec.doAwaitTrigger(startCtx, supName, supNotifyChan, Child{}), nil
+	}
+	return chSpec.doStart(startCtx, supName, supNotifyChan, 0)
+}
+
+// doStart contains the implementation of DoStart, the given restartCount is
+// reported on the child notifications.
+func (chSpec ChildSpec) doStart(
+	startCtx context.Context,
+	supName string,
+	supNotifyChan chan<- ChildNotification,
+	restartCount uint32,
+) (Child, error) {
 
-	chRuntimeName := strings.Join([]string{supName, chSpec.GetName()}, "/")
+	chRuntimeName := strings.Join(
+		[]string{supName, chSpec.GetName()},
+		GetNodeSeparator(startCtx),
+	)
 
 	// we remove the cancel from the context received on the start call so that we
 	// don't end up canceling the children at a non-appropiate time
 	ctx := WithoutCancel(startCtx)
 
-	// we allow a node to know it's name so as to allow subtrees to report
-	// events with it's full name
-	childCtx, cancelFn := context.WithCancel(setNodeName(ctx, chRuntimeName))
+	// we allow a node to know it's name (and the name of its supervisor) so as
+	// to allow subtrees to report events with it's full name
+	//
+	// the child context reports the shutdown deadline once the child is
+	// terminated
+	childCtx, cancelFn := withShutdownDeadline(
+		setNodeLogger(
+			setSupervisorName(setNodeName(ctx, chRuntimeName), supName),
+			chRuntimeName,
+		),
+	)
 
 	// startCh holds the start error, which may be nil
 	startCh := make(chan startError)
@@ -135,6 +290,31 @@ func (chSpec ChildSpec) DoStart(
 
 	terminateCh := make(chan ChildNotification)
 
+	// panics are recovered unless the supervisor disabled it, regardless of
+	// the CapturePanic setting of the child
+	capturePanic := isPanicRecoveryEnabled(startCtx)
+
+	// startTimedOutCh is closed when the child doesn't notify its start on
+	// time, and abandonedCh is closed when the child doesn't finish within its
+	// shutdown grace period after that
+	startTimedOutCh := make(chan struct{})
+	abandonedCh := make(chan struct{})
+
+	// periodicRestart is set when the child was cancelled because its periodic
+	// restart interval expired
+	var periodicRestart int32
+
+	// restartRequested is set when the child requested its own restart (see
+	// RequestRestart)
+	var restartRequested int32
+
+	// terminated is set when the parent supervisor terminates the child
+	var terminated int32
+	startFnCtx := setRestartRequester(childCtx, func() {
+		atomic.StoreInt32(&restartRequested, 1)
+		cancelFn(shutdownDeadline(chSpec.Shutdown, time.Now()))
+	})
+
 	// Child Goroutine is bootstraped
 	go func() {
 		// we tell the spawner this child thread has stopped. We want to
@@ -144,10 +324,10 @@ func (chSpec ChildSpec) DoStart(
 		defer close(terminateCh)
 
 		// we cancel the childCtx on regular termination
-		defer cancelFn()
+		defer cancelFn(time.Time{})
 
 		defer func() {
-			if chSpec.DoesCapturePanic() {
+			if capturePanic {
 				panicVal := recover()
 				// if there is a panicVal in the recover, we should handle this as an
 				// error
@@ -155,10 +335,7 @@ func (chSpec ChildSpec) DoStart(
 					return
 				}
 
-				panicErr, ok := panicVal.(error)
-				if !ok {
-					panicErr = fmt.Errorf("panic error: %v\n%s", panicVal, debug.Stack())
-				}
+				panicErr := &PanicError{panicVal: panicVal, stack: debug.Stack()}
 
 				select {
 				case startCh <- panicErr:
@@ -169,8 +346,14 @@ func (chSpec ChildSpec) DoStart(
 					panicErr,
 					chSpec,
 					chRuntimeName,
+					restartCount,
 					supNotifyChan,
 					terminateCh,
+					startTimedOutCh,
+					abandonedCh,
+					atomic.LoadInt32(&periodicRestart) == 1,
+					atomic.LoadInt32(&restartRequested) == 1,
+					atomic.LoadInt32(&terminated) == 1,
 				)
 			}
 		}()
@@ -178,7 +361,7 @@ func (chSpec ChildSpec) DoStart(
 		// client logic starts here, despite the call here being a "start", we will
 		// block and wait here until an error (or lack of) is reported from the
 		// client code
-		err := chSpec.Start(childCtx, func(err error) {
+		err := chSpec.Start(startFnCtx, func(err error) {
 			// we tell the spawner this child thread has started running. err may be
 			// nil
 
@@ -188,27 +371,146 @@ func (chSpec ChildSpec) DoStart(
 			}
 		})
 
+		// a child that returns before notifying its start fails to start with
+		// the returned error; the spawner is not going to supervise it, so the
+		// supervisor is not notified (see IsExitedBeforeStartError)
+		startErr := err
+		if startErr == nil {
+			startErr = WrapSentinel(
+				ErrExitedBeforeStart,
+				nil,
+				"child %s exited before notifying its start",
+				chRuntimeName,
+			)
+		}
+		select {
+		case startCh <- &exitedBeforeStartError{cause: startErr}:
+			return
+		case <-startedCh:
+		}
+
 		sendNotificationToSup(
 			err,
 			chSpec,
 			chRuntimeName,
+			restartCount,
 			supNotifyChan,
 			terminateCh,
+			startTimedOutCh,
+			abandonedCh,
+			atomic.LoadInt32(&periodicRestart) == 1,
+			atomic.LoadInt32(&restartRequested) == 1,
+			atomic.LoadInt32(&terminated) == 1,
 		)
 	}()
 
-	// Wait until child thread notifies it has started or failed with an error
-	err := <-startCh
-	close(startedCh)
+	// Wait until child thread notifies it has started or failed with an error;
+	// the start timeout is measured with the clock of the supervisor
+	var startTimeout <-chan time.Time
+	if chSpec.startTimeout > 0 {
+		startTimeout = clockAfter(startCtx, chSpec.startTimeout)
+	}
+
+	var err error
+	select {
+	case err = <-startCh:
+		close(startedCh)
+	case <-startTimeout:
+		close(startedCh)
+		close(startTimedOutCh)
+		cancelFn(shutdownDeadline(chSpec.Shutdown, time.Now()))
+		// the child gets its shutdown grace period to finish
+		_, terminationErr := waitTimeout(terminateCh)(chSpec.Shutdown)
+		close(abandonedCh)
+		return Child{}, WrapSentinel(
+			ErrStartTimeout,
+			terminationErr,
+			"child %s did not notify its start after %v",
+			chRuntimeName,
+			chSpec.startTimeout,
+		)
+	}
 	if err != nil {
 		return Child{}, err
 	}
 
+	if chSpec.periodicRestart > 0 {
+		// the timer starts once the child has started, and it is discarded
+		// when the child finishes for other reasons; the interval is measured
+		// with the clock of the supervisor
+		go func() {
+			select {
+			case <-clockAfter(startCtx, chSpec.periodicRestart):
+				atomic.StoreInt32(&periodicRestart, 1)
+				cancelFn(shutdownDeadline(chSpec.Shutdown, time.Now()))
+			case <-childCtx.Done():
+			}
+		}()
+	}
+
 	return Child{
-		runtimeName: chRuntimeName,
-		createdAt:   time.Now(),
-		spec:        chSpec,
-		cancel:      cancelFn,
+		runtimeName:  chRuntimeName,
+		createdAt:    clockNow(startCtx),
+		restartCount: restartCount,
+		spec:         chSpec,
+		cancel: func(deadline time.Time) {
+			atomic.StoreInt32(&terminated, 1)
+			cancelFn(deadline)
+		},
 		wait:        waitTimeout(terminateCh),
+		onTerminate: newOnTerminate(ctx, chSpec.onTerminate),
 	}, nil
 }
+
+// DoRestart spawns a new goroutine for a ChildSpec that was previously running
+// as the given Child. It behaves exactly like DoStart, with the difference that
+// the returned Child keeps the bookkeeping of the previous one (e.g. the number
+// of panics), and its restart count is increased.
+//
+// When the circuit breaker of the previous Child is open, the Start function
+// is not called; the returned Child waits for the cooldown period of the
+// circuit breaker instead (see WithCircuitBreaker). When the previous Child was
+// switched to its fallback, the fallback start function is used instead (see
+// WithFallback).
+//
+// When the previous Child was waiting for its lazy start trigger, the returned
+// Child waits for it again, unless the trigger fired already; in that case the
+// Child is started for the first time (see WithLazyStart).
+func (chSpec ChildSpec) DoRestart(
+	startCtx context.Context,
+	supName string,
+	supNotifyChan chan<- ChildNotification,
+	prevCh Child,
+) (Child, error) {
+	if prevCh.circuit.state == CircuitOpen {
+		// the child is not started again until the cooldown of its circuit
+		// breaker is over
+		return chSpec.doCooldown(startCtx, supName, supNotifyChan, prevCh), nil
+	}
+	if prevCh.lazy.pending {
+		if !prevCh.lazy.triggered {
+			return chSpec.doAwaitTrigger(startCtx, supName, supNotifyChan, prevCh), nil
+		}
+		return chSpec.doStart(startCtx, supName, supNotifyChan, prevCh.restartCount)
+	}
+	if prevCh.fallback {
+		chSpec = chSpec.toFallback()
+	}
+	if chSpec.IsPausable() {
+		// a restarted child is not paused, discard any signal the previous
+		// goroutine didn't get to read
+		select {
+		case <-chSpec.pauseCh:
+		default:
+		}
+	}
+	ch, err := chSpec.doStart(startCtx, supName, supNotifyChan, prevCh.restartCount+1)
+	if err != nil {
+		return ch, err
+	}
+	ch.panicCount = prevCh.panicCount
+	ch.budget = prevCh.budget
+	ch.circuit = prevCh.circuit
+	ch.fallback = prevCh.fallback
+	return ch, nil
+}
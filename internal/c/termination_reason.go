@@ -0,0 +1,71 @@
+package c
+
+// This file contains the classification of the reasons a child stops running
+
+import (
+	"context"
+	"errors"
+)
+
+// TerminationReason specifies why a child stopped running
+type TerminationReason uint32
+
+const (
+	// TerminationCleanExit indicates the child finished without an error
+	TerminationCleanExit TerminationReason = iota
+	// TerminationErrorExit indicates the child finished with an error
+	TerminationErrorExit
+	// TerminationPanic indicates the child panicked, and the panic was
+	// captured (see WithCapturePanic)
+	TerminationPanic
+	// TerminationBySupervisor indicates the parent supervisor stopped the
+	// child, either because it was terminating the child, or because the child
+	// was restarted on purpose (e.g. a periodic restart, or a restart the
+	// child requested)
+	TerminationBySupervisor
+	// TerminationContextCancelled indicates the child finished with a context
+	// cancellation error (context.Canceled or context.DeadlineExceeded) that
+	// was not caused by its parent supervisor
+	TerminationContextCancelled
+)
+
+// String returns a string representation of the TerminationReason
+func (tr TerminationReason) String() string {
+	switch tr {
+	case TerminationCleanExit:
+		return "CleanExit"
+	case TerminationErrorExit:
+		return "ErrorExit"
+	case TerminationPanic:
+		return "Panic"
+	case TerminationBySupervisor:
+		return "BySupervisor"
+	case TerminationContextCancelled:
+		return "ContextCancelled"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// terminationReason classifies the termination of a child that finished with
+// the given error (which may be nil); bySupervisor is true when the parent
+// supervisor cancelled the context of the child.
+func terminationReason(err error, bySupervisor bool) TerminationReason {
+	switch {
+	case IsPanicError(err):
+		return TerminationPanic
+	case bySupervisor:
+		return TerminationBySupervisor
+	case err == nil:
+		return TerminationCleanExit
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return TerminationContextCancelled
+	default:
+		return TerminationErrorExit
+	}
+}
+
+// Reason returns why the child that emitted this notification stopped running
+func (ce ChildNotification) Reason() TerminationReason {
+	return ce.reason
+}
@@ -1,6 +1,8 @@
 package c
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -9,8 +11,21 @@ type Child struct {
 	runtimeName  string
 	spec         ChildSpec
 	createdAt    time.Time
-	cancel       func()
+	restartCount uint32
+	panicCount   uint32
+	budget       transientBudget
+	circuit      circuitBreaker
+	fallback     bool
+	paused       bool
+	lazy         lazyStart
+	cancel       func(time.Time)
 	wait         func(Shutdown) (bool, error)
+	onTerminate  func(time.Time) error
+
+	// reason is why the child stopped running, it is only valid when
+	// hasReason is true
+	reason    TerminationReason
+	hasReason bool
 }
 
 // GetRuntimeName returns the name of this child (once started). It will have a
@@ -29,6 +44,15 @@ func (c Child) GetSpec() ChildSpec {
 	return c.spec
 }
 
+// Rename returns a copy of this child with the given spec name, the runtime
+// name of the copy is updated accordingly. It is used by supervisors that swap
+// the spec of a running child.
+func (c Child) Rename(name string) Child {
+	c.runtimeName = strings.TrimSuffix(c.runtimeName, c.spec.GetName()) + name
+	c.spec.Name = name
+	return c
+}
+
 // IsWorker indicates if this child is a worker
 func (c Child) IsWorker() bool {
 	return c.spec.IsWorker()
@@ -39,13 +63,137 @@ func (c Child) GetTag() ChildTag {
 	return c.spec.GetTag()
 }
 
+// GetCreatedAt returns the time this child was (re)started
+func (c Child) GetCreatedAt() time.Time {
+	return c.createdAt
+}
+
+// GetRestartCount returns the number of times this child has been restarted
+// since it was first started by its supervisor
+func (c Child) GetRestartCount() uint32 {
+	return c.restartCount
+}
+
+// GetPanicCount returns the number of times this child has panicked since it
+// was first started by its supervisor
+func (c Child) GetPanicCount() uint32 {
+	return c.panicCount
+}
+
+// WithTerminationReason returns a copy of this Child that registers why it
+// stopped running; supervisors use it to report the prior termination of a
+// child when they restart it
+func (c Child) WithTerminationReason(reason TerminationReason) Child {
+	c.reason = reason
+	c.hasReason = true
+	return c
+}
+
+// GetTerminationReason returns why this child stopped running; the second
+// value is false when the child didn't report it (e.g. it is still running,
+// or its supervisor terminated it)
+func (c Child) GetTerminationReason() (TerminationReason, bool) {
+	return c.reason, c.hasReason
+}
+
+// RegisterPanic returns a copy of this Child with an increased panic count;
+// supervisors use it to keep track of children that panic repeatedly
+func (c Child) RegisterPanic() Child {
+	c.panicCount++
+	return c
+}
+
+// transientBudget keeps track of the failures of a child with a transient
+// budget in the current window
+type transientBudget struct {
+	windowStart time.Time
+	failures    uint32
+}
+
+// RegisterTransientFailure returns a copy of this Child that accounts the
+// failure that happened at the given time against its transient budget. It
+// does nothing when the child has no transient budget.
+func (c Child) RegisterTransientFailure(now time.Time) Child {
+	if !c.spec.HasTransientBudget() {
+		return c
+	}
+	if c.budget.windowStart.IsZero() ||
+		now.Sub(c.budget.windowStart) > c.spec.transientBudgetWindow {
+		c.budget = transientBudget{windowStart: now}
+	}
+	c.budget.failures++
+	return c
+}
+
+// IsTransientBudgetExceeded indicates if this child failed more times than
+// its transient budget allows within the budget window
+func (c Child) IsTransientBudgetExceeded() bool {
+	return c.spec.HasTransientBudget() && c.budget.failures > c.spec.transientBudget
+}
+
+// IsFallback indicates if this child runs the fallback start function of its
+// spec (see WithFallback)
+func (c Child) IsFallback() bool {
+	return c.fallback
+}
+
+// SwitchToFallback returns a copy of this Child that is restarted with the
+// fallback start function of its spec from now on.
+func (c Child) SwitchToFallback() Child {
+	c.fallback = true
+	return c
+}
+
+// IsPaused indicates if this child was paused by its supervisor
+func (c Child) IsPaused() bool {
+	return c.paused
+}
+
+// SendPauseSignal delivers the given signal to a pausable child, and returns a
+// copy of this Child that keeps track of the new paused state. It fails if the
+// child is not pausable, if the child is already in the requested state, or if
+// the child has not read a previous signal yet.
+func (c Child) SendPauseSignal(signal PauseSignal) (Child, error) {
+	if !c.spec.IsPausable() {
+		return c, WrapSentinel(ErrNotPausable, nil, "worker %s cannot be paused", c.GetName())
+	}
+
+	paused := signal == PauseRequested
+	if c.paused == paused {
+		return c, WrapSentinel(
+			ErrPauseStateUnchanged, nil,
+			"worker %s already received signal %s", c.GetName(), signal,
+		)
+	}
+
+	select {
+	case c.spec.pauseCh <- signal:
+	default:
+		return c, WrapSentinel(
+			ErrPauseSignalPending, nil,
+			"worker %s has not read its previous pause signal", c.GetName(),
+		)
+	}
+
+	c.paused = paused
+	return c, nil
+}
+
 // ChildNotification reports when a child has terminated; if it terminated with
 // an error, it is set in the err field, otherwise, err will be nil.
 type ChildNotification struct {
-	name        string
-	tag         ChildTag
-	runtimeName string
-	err         error
+	name         string
+	tag          ChildTag
+	runtimeName  string
+	restartCount uint32
+	err          error
+
+	periodicRestart  bool
+	restartRequested bool
+	cooldownExpired  bool
+	lazyTriggered    bool
+
+	reason TerminationReason
 }
 
 // GetName returns the spec name of the child that emitted this notification
@@ -59,7 +207,64 @@ func (ce ChildNotification) RuntimeName() string {
 	return ce.runtimeName
 }
 
+// RestartCount returns the number of times the child that emitted this
+// notification had been restarted when it terminated; the notification of a
+// child that was never restarted reports zero.
+func (ce ChildNotification) RestartCount() uint32 {
+	return ce.restartCount
+}
+
+// IsPeriodicRestart indicates if the child that emitted this notification
+// finished because its periodic restart interval expired (see
+// WithPeriodicRestart)
+func (ce ChildNotification) IsPeriodicRestart() bool {
+	return ce.periodicRestart
+}
+
 // Unwrap returns the error reported by ChildNotification, if any.
 func (ce ChildNotification) Unwrap() error {
 	return ce.err
 }
+
+// PanicError is the error reported by a child when its goroutine panics and
+// its supervisor recovers panics (see WithPanicRecovery).
+type PanicError struct {
+	panicVal interface{}
+	stack    []byte
+}
+
+// Error returns an error message; when the panic value is an error, its
+// message is used as is.
+func (err *PanicError) Error() string {
+	if panicErr, ok := err.panicVal.(error); ok {
+		return panicErr.Error()
+	}
+	return fmt.Sprintf("panic error: %v\n%s", err.panicVal, err.stack)
+}
+
+// Unwrap returns the panic value when it is an error, nil otherwise.
+func (err *PanicError) Unwrap() error {
+	if panicErr, ok := err.panicVal.(error); ok {
+		return panicErr
+	}
+	return nil
+}
+
+// GetValue returns the value given to panic
+func (err *PanicError) GetValue() interface{} {
+	return err.panicVal
+}
+
+// GetStack returns the stack trace of the goroutine that panicked, as it was
+// at the moment the panic was recovered
+func (err *PanicError) GetStack() []byte {
+	return err.stack
+}
+
+// IsPanicError indicates if the given error was reported by a child goroutine
+// that panicked. Errors coming from nested supervisors are not considered,
+// even when they were originated by a panic deeper in the tree.
+func IsPanicError(err error) bool {
+	_, ok := err.(*PanicError)
+	return ok
+}
@@ -2,8 +2,10 @@ package c_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -54,3 +56,172 @@ func TestWorkerDoubleTermination(t *testing.T) {
 
 	})
 }
+
+func TestChildNotificationRestartCount(t *testing.T) {
+	wspec := c.New("worker", func(context.Context) error {
+		return fmt.Errorf("failing worker")
+	})
+
+	// buffered to receive the notification without a supervisor loop
+	supNotifyChan := make(chan c.ChildNotification, 1)
+
+	ch, err := wspec.DoStart(context.Background(), "test", supNotifyChan)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), ch.GetRestartCount())
+	notification := <-supNotifyChan
+	// the first failure reports the count before the restart
+	assert.Equal(t, uint32(0), notification.RestartCount())
+
+	ch, err = wspec.DoRestart(context.Background(), "test", supNotifyChan, ch)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), ch.GetRestartCount())
+	notification = <-supNotifyChan
+	assert.Equal(t, uint32(1), notification.RestartCount())
+
+	ch, err = wspec.DoRestart(context.Background(), "test", supNotifyChan, ch)
+	assert.NoError(t, err)
+	notification = <-supNotifyChan
+	assert.Equal(t, uint32(2), notification.RestartCount())
+}
+
+func TestShutdownAccessors(t *testing.T) {
+	assert.True(t, c.Indefinitely.IsIndefinitely())
+	_, ok := c.Indefinitely.Timeout()
+	assert.False(t, ok)
+
+	shutdown := c.Timeout(5 * time.Second)
+	assert.False(t, shutdown.IsIndefinitely())
+	timeout, ok := shutdown.Timeout()
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	wspec := c.New(
+		"worker",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		c.WithShutdown(shutdown),
+	)
+	assert.Equal(t, "worker", wspec.GetName())
+	assert.Equal(t, shutdown, wspec.GetShutdown())
+	assert.Equal(t, c.Permanent, wspec.GetRestart())
+}
+
+func TestChildSpecValidate(t *testing.T) {
+	wspec := c.New(
+		"worker",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	)
+	assert.NoError(t, wspec.Validate())
+
+	invalidSpec := c.New(
+		"worker",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		c.WithStartTimeout(-1*time.Second),
+	)
+	invalidSpec.Start = nil
+	invalidSpec.Shutdown = c.Timeout(-1 * time.Second)
+
+	err := invalidSpec.Validate()
+	assert.True(t, errors.Is(err, c.ErrInvalidChildSpec))
+	assert.Equal(
+		t,
+		"child 'worker' is invalid: nil start function, "+
+			"negative shutdown timeout -1s, negative start timeout -1s",
+		err.Error(),
+	)
+
+	minRuntimeSpec := wspec
+	c.WithMinRuntime(time.Minute, 0)(&minRuntimeSpec)
+	err = minRuntimeSpec.Validate()
+	assert.True(t, errors.Is(err, c.ErrInvalidChildSpec))
+	assert.Equal(t, "child 'worker' is invalid: zero min runtime weight", err.Error())
+
+	err = c.ChildSpec{Start: wspec.Start}.Validate()
+	assert.True(t, errors.Is(err, c.ErrInvalidChildSpec))
+	assert.Equal(t, "child '' is invalid: empty name", err.Error())
+}
+
+func TestChildNotificationReason(t *testing.T) {
+	errFailing := errors.New("failing worker")
+
+	tests := []struct {
+		name     string
+		startFn  func(context.Context) error
+		expected c.TerminationReason
+	}{
+		{
+			name:     "clean exit",
+			startFn:  func(context.Context) error { return nil },
+			expected: c.TerminationCleanExit,
+		},
+		{
+			name:     "error exit",
+			startFn:  func(context.Context) error { return errFailing },
+			expected: c.TerminationErrorExit,
+		},
+		{
+			name:     "panic",
+			startFn:  func(context.Context) error { panic(errFailing) },
+			expected: c.TerminationPanic,
+		},
+		{
+			name: "context cancellation",
+			startFn: func(ctx context.Context) error {
+				ctx, cancel := context.WithCancel(ctx)
+				cancel()
+				return ctx.Err()
+			},
+			expected: c.TerminationContextCancelled,
+		},
+		{
+			name: "cancelled by supervisor",
+			startFn: func(ctx context.Context) error {
+				c.RequestRestart(ctx)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			expected: c.TerminationBySupervisor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// buffered to receive the notification without a supervisor loop
+			supNotifyChan := make(chan c.ChildNotification, 1)
+
+			_, err := c.New("worker", tt.startFn).DoStart(
+				context.Background(), "test", supNotifyChan,
+			)
+			assert.NoError(t, err)
+			notification := <-supNotifyChan
+			assert.Equal(t, tt.expected, notification.Reason())
+		})
+	}
+}
+
+func TestChildSpecShouldRestart(t *testing.T) {
+	errBoom := errors.New("boom")
+	start := func(ctx context.Context) error { return nil }
+
+	transient := c.New("worker", start, c.WithRestart(c.Transient))
+	assert.True(t, transient.ShouldRestart(errBoom))
+	assert.False(t, transient.ShouldRestart(nil))
+
+	temporary := c.New(
+		"worker", start,
+		c.WithRestart(c.Temporary),
+		c.WithRestartDecider(func(err error) bool { return err == errBoom }),
+	)
+	// the decider overrides the restart setting on errors only
+	assert.True(t, temporary.ShouldRestart(errBoom))
+	assert.False(t, temporary.ShouldRestart(context.Canceled))
+	assert.False(t, temporary.ShouldRestart(nil))
+}
@@ -0,0 +1,130 @@
+package n
+
+import (
+	"sync"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+	"github.com/capatazlib/go-capataz/internal/s"
+)
+
+const defaultSamplingWindow = time.Second
+
+// SamplingPolicy specifies which events a SamplingNotifier forwards to its
+// delegate EventNotifier
+type SamplingPolicy struct {
+	// Limit is the number of events of a node that are forwarded on every
+	// sampling window; the rest of the events of the node are dropped.
+	Limit int
+	// Window is the duration of a sampling window, it defaults to one second.
+	Window time.Duration
+	// KeepAll matches the events that are always forwarded, regardless of the
+	// Limit. It defaults to EIsTerminal.
+	KeepAll EventCriteria
+}
+
+// EIsTerminal returns true if the event represents a node that is not going to
+// be restarted anymore: the failure or termination of a supervisor, the
+// retirement of a child, or the escalation of a child to its circuit breaker
+// or fallback.
+var EIsTerminal EventCriteria = func(ev s.Event) bool {
+	switch ev.GetTag() {
+	case s.ProcessFailed, s.ProcessStartFailed, s.ProcessTerminated:
+		return ev.GetNodeTag() == c.Supervisor
+	case s.ProcessRetired, s.ChildCircuitOpened, s.ChildSwitchedToFallback:
+		return true
+	default:
+		return false
+	}
+}
+
+// samplingWindow keeps track of the events of a node on the current sampling
+// window
+type samplingWindow struct {
+	start time.Time
+	count int
+}
+
+// SamplingNotifier forwards the events of a supervision tree to a delegate
+// EventNotifier, keeping the first events of each node on every sampling
+// window and counting the rest. It is useful to keep a failure storm from
+// flooding a monitoring system.
+type SamplingNotifier struct {
+	delegate s.EventNotifier
+	policy   SamplingPolicy
+
+	mu        sync.Mutex
+	windows   map[string]*samplingWindow
+	forwarded uint64
+	dropped   uint64
+}
+
+// NewSamplingNotifier creates a SamplingNotifier that forwards events to the
+// given EventNotifier with the given SamplingPolicy. Use the Notify method as
+// the EventNotifier of a supervisor (see WithNotifier).
+func NewSamplingNotifier(delegate s.EventNotifier, policy SamplingPolicy) *SamplingNotifier {
+	if policy.Window <= 0 {
+		policy.Window = defaultSamplingWindow
+	}
+	if policy.KeepAll == nil {
+		policy.KeepAll = EIsTerminal
+	}
+	return &SamplingNotifier{
+		delegate: delegate,
+		policy:   policy,
+		windows:  make(map[string]*samplingWindow),
+	}
+}
+
+// Notify forwards the given event to the delegate EventNotifier when the
+// SamplingPolicy allows it. The time of the event is the one it was created
+// at.
+func (sn *SamplingNotifier) Notify(ev s.Event) {
+	if !sn.sample(ev) {
+		return
+	}
+	sn.delegate(ev)
+}
+
+// sample registers the given event, and returns true if it must be forwarded
+func (sn *SamplingNotifier) sample(ev s.Event) bool {
+	keep := sn.policy.KeepAll(ev)
+
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	if !keep {
+		name := ev.GetProcessRuntimeName()
+		created := ev.GetCreated()
+		window, ok := sn.windows[name]
+		if !ok || created.Sub(window.start) >= sn.policy.Window {
+			window = &samplingWindow{start: created}
+			sn.windows[name] = window
+		}
+		window.count++
+		keep = window.count <= sn.policy.Limit
+	}
+
+	if keep {
+		sn.forwarded++
+	} else {
+		sn.dropped++
+	}
+	return keep
+}
+
+// Forwarded returns the number of events that were given to the delegate
+// EventNotifier
+func (sn *SamplingNotifier) Forwarded() uint64 {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	return sn.forwarded
+}
+
+// Dropped returns the number of events that were not given to the delegate
+// EventNotifier
+func (sn *SamplingNotifier) Dropped() uint64 {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	return sn.dropped
+}
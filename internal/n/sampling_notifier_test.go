@@ -0,0 +1,63 @@
+package n_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/internal/n"
+	"github.com/capatazlib/go-capataz/internal/s"
+)
+
+func TestSamplingNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var received []s.Event
+	delegate := func(ev s.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, ev)
+	}
+
+	sn := n.NewSamplingNotifier(
+		delegate,
+		n.SamplingPolicy{Limit: 2, Window: time.Minute},
+	)
+
+	child1 := cap.NewWorker("child1", func(context.Context) error {
+		return errors.New("boom")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1),
+		cap.WithRestartTolerance(5, time.Minute),
+		cap.WithNotifier(sn.Notify),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.Error(t, sup.Wait())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	childEvents := 0
+	for _, ev := range received {
+		if ev.GetProcessRuntimeName() == "root/child1" {
+			childEvents++
+		}
+	}
+	// only the first events of the failing child are forwarded
+	assert.Equal(t, 2, childEvents)
+
+	// the supervisor failure is a terminal event, it is always forwarded
+	last := received[len(received)-1]
+	assert.Equal(t, cap.ProcessFailed, last.GetTag())
+	assert.Equal(t, "root", last.GetProcessRuntimeName())
+
+	assert.Equal(t, uint64(len(received)), sn.Forwarded())
+	assert.True(t, sn.Dropped() > 0)
+}
@@ -0,0 +1,79 @@
+package s
+
+// This file contains the logic to keep track of the children that were
+// abandoned because they didn't honor their shutdown timeout
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// abandonedChildren keeps track of the children of a whole supervision tree
+// that didn't finish before their shutdown timeout expired. A single value is
+// shared by the root supervisor and all its sub-trees.
+type abandonedChildren struct {
+	mu    sync.Mutex
+	names []string
+}
+
+var abandonedChildrenKey capatazSupKey = "__capataz.supervisor.abandoned_children__"
+
+// withAbandonedChildren sets the abandonedChildren in the context that is
+// thread-through the supervision tree
+func withAbandonedChildren(ctx context.Context, ac *abandonedChildren) context.Context {
+	return context.WithValue(ctx, abandonedChildrenKey, ac)
+}
+
+// getAbandonedChildren returns the abandonedChildren of the supervision tree,
+// nil if there is none
+func getAbandonedChildren(ctx context.Context) *abandonedChildren {
+	if ac, ok := ctx.Value(abandonedChildrenKey).(*abandonedChildren); ok {
+		return ac
+	}
+	return nil
+}
+
+// register records the runtime name of an abandoned child
+func (ac *abandonedChildren) register(runtimeName string) {
+	if ac == nil {
+		return
+	}
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.names = append(ac.names, runtimeName)
+}
+
+// list returns the runtime names of the abandoned children
+func (ac *abandonedChildren) list() []string {
+	if ac == nil {
+		return nil
+	}
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return append([]string(nil), ac.names...)
+}
+
+// isAbandonment indicates if the given termination error reports a child that
+// did not finish on time. The failures of nested children of a sub-tree are
+// reported by the supervisor of the sub-tree instead.
+func isAbandonment(err error) bool {
+	var terminationErr *SupervisorTerminationError
+	if errors.As(err, &terminationErr) {
+		return false
+	}
+	return errors.Is(err, c.ErrShutdownTimeout) || errors.Is(err, c.ErrAbandoned)
+}
+
+// AbandonedChildren returns the runtime names of the children of the
+// supervision tree that didn't finish before their shutdown timeout expired,
+// in the order they were abandoned. Go cannot stop a goroutine by force, so
+// the goroutines of these children may still be running.
+//
+// A child that is abandoned more than once (e.g. after it got restarted) is
+// listed once per abandonment.
+func (sup Supervisor) AbandonedChildren() []string {
+	return sup.abandonedChildren.list()
+}
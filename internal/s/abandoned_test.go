@@ -0,0 +1,46 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestAbandonedChildren(t *testing.T) {
+	t.Run("children that ignore their shutdown timeout", func(t *testing.T) {
+		subtree1 := cap.NewSupervisorSpec(
+			"subtree1",
+			cap.WithNodes(WaitDoneWorker("child1"), NeverTerminateWorker("child2")),
+		)
+
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(NeverTerminateWorker("child0"), cap.Subtree(subtree1)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+		assert.Empty(t, sup.AbandonedChildren())
+
+		assert.Error(t, sup.Terminate())
+		// the sub-tree itself is not abandoned, only its child
+		assert.Equal(
+			t,
+			[]string{"root/subtree1/child2", "root/child0"},
+			sup.AbandonedChildren(),
+		)
+	})
+
+	t.Run("children that honor their shutdown timeout", func(t *testing.T) {
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1")),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		assert.NoError(t, sup.Terminate())
+		assert.Empty(t, sup.AbandonedChildren())
+	})
+}
@@ -0,0 +1,65 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestActiveChildCount(t *testing.T) {
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(WaitDoneWorker("child3"), WaitDoneWorker("child4")),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child2"),
+			cap.Subtree(subtree),
+		),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, sup.ActiveChildCount(false))
+	assert.Equal(t, 5, sup.ActiveChildCount(true))
+
+	assert.NoError(t, sup.Terminate())
+
+	// a terminated supervisor has no running children
+	assert.Equal(t, 0, sup.ActiveChildCount(false))
+	assert.Equal(t, 0, sup.ActiveChildCount(true))
+}
+
+func TestActiveChildCountCompletedChild(t *testing.T) {
+	completedCh := make(chan struct{}, 1)
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.ProcessCompleted {
+			completedCh <- struct{}{}
+		}
+	}
+
+	child1 := cap.NewWorker(
+		"child1",
+		func(context.Context) error { return nil },
+		cap.WithRestart(cap.Temporary),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		cap.WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	<-completedCh
+	// the completed child is not restarted, it is not accounted
+	assert.Equal(t, 1, sup.ActiveChildCount(true))
+
+	assert.NoError(t, sup.Terminate())
+}
@@ -2,7 +2,9 @@ package s_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/capatazlib/go-capataz/cap"
 	. "github.com/capatazlib/go-capataz/internal/stest"
@@ -96,3 +98,174 @@ func TestCapturePanic(t *testing.T) {
 		)
 	})
 }
+
+func TestPanicEscalation(t *testing.T) {
+	parentName := "root"
+	// We create a worker that panics more times than the escalation setting
+	panicChild1, signalPanic1 := PanicOnSignalWorker(
+		5,
+		"child1",
+		cap.WithPanicEscalation(3),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		parentName,
+		cap.WithNodes(panicChild1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			// the restart tolerance would allow many more restarts
+			cap.WithRestartTolerance(10, 10*time.Second),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			signalPanic1(true /* done */)
+			evIt.WaitTill(WorkerFailedWith("root/child1", "panicking child (3 out of 5)"))
+		},
+	)
+
+	assert.Error(t, err)
+
+	var restartErr *cap.SupervisorRestartError
+	assert.True(t, errors.As(err, &restartErr))
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' crashed due to repeated panics.\n\t"+
+			"worker node 'root/child1' panicked 3 times, restarting it is not going to help.\n\t"+
+			"the last error reported was:\n\t\t> panicking child (3 out of 5)",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailedWith("root/child1", "panicking child (1 out of 5)"),
+			WorkerStarted("root/child1"),
+			WorkerFailedWith("root/child1", "panicking child (2 out of 5)"),
+			WorkerStarted("root/child1"),
+			WorkerFailedWith("root/child1", "panicking child (3 out of 5)"),
+			// ^^^ escalation happens here, even though the restart tolerance
+			// was not surpassed
+			WorkerTerminated("root/child2"),
+			SupervisorFailed("root"),
+		},
+	)
+}
+
+func TestPanicErrorUnwrapsPanicValue(t *testing.T) {
+	panicValue := errors.New("panic value")
+	panicked := make(chan struct{})
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			cap.NewWorker("child1", func(ctx context.Context) error {
+				select {
+				case <-panicked:
+				default:
+					close(panicked)
+					panic(panicValue)
+				}
+				<-ctx.Done()
+				return nil
+			}),
+		),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	for _, ev := range events {
+		if ev.GetTag() == cap.ProcessFailed {
+			// the PanicError keeps the message of the panic value, and unwraps to it
+			assert.True(t, errors.Is(ev.Err(), panicValue))
+			assert.Equal(t, "panic value", ev.Err().Error())
+		}
+	}
+}
+
+func TestPanicRecoveryReportsValueAndStack(t *testing.T) {
+	panicChild1, signalPanic1 := PanicOnSignalWorker(1, "child1")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(panicChild1),
+		[]cap.Opt{cap.WithPanicRecovery(true)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			signalPanic1(true /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	var panicErr *cap.PanicError
+	for _, ev := range events {
+		if ev.GetTag() == cap.ProcessFailed && errors.As(ev.Err(), &panicErr) {
+			break
+		}
+	}
+
+	if assert.NotNil(t, panicErr) {
+		panicVal, ok := panicErr.GetValue().(error)
+		assert.True(t, ok)
+		assert.Equal(t, "panicking child (1 out of 1)", panicVal.Error())
+		assert.Contains(t, string(panicErr.GetStack()), "stest.PanicOnSignalWorker")
+	}
+}
+
+func TestPanicRecoveryEnabledByDefault(t *testing.T) {
+	panicked := make(chan struct{})
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			// the worker does not capture panics, but the supervisor recovers
+			// them by default
+			cap.NewWorker("child1", func(ctx context.Context) error {
+				select {
+				case <-panicked:
+				default:
+					close(panicked)
+					panic("plain panic")
+				}
+				<-ctx.Done()
+				return nil
+			}, cap.WithCapturePanic(false)),
+		),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	var panicErr *cap.PanicError
+	for _, ev := range events {
+		if ev.GetTag() == cap.ProcessFailed && errors.As(ev.Err(), &panicErr) {
+			break
+		}
+	}
+
+	if assert.NotNil(t, panicErr) {
+		assert.Equal(t, "plain panic", panicErr.GetValue())
+	}
+}
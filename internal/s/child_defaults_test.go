@@ -0,0 +1,119 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestSupervisorSpecWithDefaults(t *testing.T) {
+	blockingWorker := func(name string, opts ...cap.WorkerOpt) cap.Node {
+		return cap.NewWorker(
+			name,
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			opts...,
+		)
+	}
+
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(WaitDoneWorker("child3")))
+
+	spec := cap.NewSupervisorSpecWithDefaults(
+		"root",
+		[]cap.WorkerOpt{
+			cap.WithRestart(cap.Transient),
+			cap.WithShutdown(cap.Timeout(time.Second)),
+		},
+		cap.WithNodes(
+			blockingWorker("child1"),
+			// the options of the child take precedence over the defaults
+			blockingWorker("child2", cap.WithRestart(cap.Temporary)),
+			cap.Subtree(subtree),
+		),
+	)
+
+	sup, err := spec.Start(context.TODO())
+	assert.NoError(t, err)
+
+	topology, err := sup.Topology()
+	assert.NoError(t, err)
+
+	if assert.Len(t, topology.GetChildren(), 3) {
+		child1 := topology.GetChildren()[0]
+		assert.Equal(t, cap.Transient, child1.GetRestart())
+		assert.Equal(t, cap.Timeout(time.Second), child1.GetShutdown())
+
+		child2 := topology.GetChildren()[1]
+		assert.Equal(t, cap.Temporary, child2.GetRestart())
+		assert.Equal(t, cap.Timeout(time.Second), child2.GetShutdown())
+
+		subtreeNode := topology.GetChildren()[2]
+		assert.Equal(t, cap.Transient, subtreeNode.GetRestart())
+		// defaults are not inherited by the children of sub-trees
+		if assert.Len(t, subtreeNode.GetChildren(), 1) {
+			assert.Equal(t, cap.Permanent, subtreeNode.GetChildren()[0].GetRestart())
+		}
+	}
+
+	assert.NoError(t, sup.Terminate())
+}
+
+// dependsOnRecorder is a RestartStrategy that records the dependencies of the
+// siblings of a failed child
+type dependsOnRecorder struct {
+	dependsOn chan map[string][]string
+}
+
+func (r dependsOnRecorder) AffectedChildren(
+	failed cap.ChildInfo, siblings []cap.ChildInfo,
+) []cap.ChildInfo {
+	dependsOn := make(map[string][]string, len(siblings))
+	for _, sibling := range siblings {
+		dependsOn[sibling.GetName()] = sibling.GetDependsOn()
+	}
+	r.dependsOn <- dependsOn
+	return []cap.ChildInfo{failed}
+}
+
+func TestSupervisorSpecWithDefaultsDependsOn(t *testing.T) {
+	childA, failWorkerA := FailOnSignalWorker(1, "a")
+	recorder := dependsOnRecorder{dependsOn: make(chan map[string][]string, 1)}
+
+	spec := cap.NewSupervisorSpecWithDefaults(
+		"root",
+		[]cap.WorkerOpt{
+			cap.WithShutdown(cap.Timeout(time.Second)),
+		},
+		cap.WithNodes(
+			childA,
+			cap.NewWorker(
+				"b",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithDependsOn("a"),
+			),
+		),
+		cap.WithRestartStrategy(recorder),
+	)
+
+	sup, err := spec.Start(context.TODO())
+	assert.NoError(t, err)
+
+	failWorkerA(true /* done */)
+	dependsOn := <-recorder.dependsOn
+
+	// the options of the child are applied once on top of the defaults
+	assert.Equal(t, []string{"a"}, dependsOn["b"])
+	assert.Empty(t, dependsOn["a"])
+
+	assert.NoError(t, sup.Terminate())
+}
@@ -0,0 +1,157 @@
+package s
+
+// This file contains the logic to handle children that have a circuit breaker
+// (see c.WithCircuitBreaker)
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+var loopCtrlKey capatazSupKey = "__capataz.supervisor.loop_ctrl__"
+
+// loopCtrl allows the logic running on the supervisor thread to schedule
+// control messages for its own monitor loop. The ctx is done once the monitor
+// loop is over.
+type loopCtrl struct {
+	ctx      context.Context
+	ctrlChan chan ctrlMsg
+}
+
+// withLoopCtrl sets the loopCtrl of the monitor loop in the supervisor context
+func withLoopCtrl(ctx context.Context, lc loopCtrl) context.Context {
+	return context.WithValue(ctx, loopCtrlKey, lc)
+}
+
+// getLoopCtrl returns the loopCtrl of the monitor loop running with the given
+// supervisor context
+func getLoopCtrl(ctx context.Context) (loopCtrl, bool) {
+	lc, ok := ctx.Value(loopCtrlKey).(loopCtrl)
+	return lc, ok
+}
+
+// circuitTrialMsg is a message a supervisor sends to itself once a child with
+// a half-open circuit breaker ran for a whole cooldown period.
+type circuitTrialMsg struct {
+	nodeName string
+	trial    uint32
+}
+
+func (ctm circuitTrialMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	ch, ok := supChildren[ctm.nodeName]
+	// the trial failed already, or the message belongs to a previous trial
+	if !ok ||
+		ch.GetCircuitState() != c.CircuitHalfOpen ||
+		ch.GetCircuitTrialCount() != ctm.trial {
+		return specChildren, supChildren
+	}
+
+	ch = ch.CloseCircuit()
+	supChildren[ctm.nodeName] = ch
+	evNotifier.withTags(ch.GetSpec()).childCircuitClosed(ch.GetTag(), ch.GetRuntimeName())
+
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = circuitTrialMsg{}
+
+// scheduleCircuitTrial sends a circuitTrialMsg to the monitor loop once the
+// given child with a half-open circuit breaker runs for a whole cooldown
+// period. The message is discarded when the monitor loop is over.
+func scheduleCircuitTrial(supCtx context.Context, supSpec SupervisorSpec, ch c.Child) {
+	lc, ok := getLoopCtrl(supCtx)
+	if !ok {
+		return
+	}
+
+	msg := circuitTrialMsg{nodeName: ch.GetName(), trial: ch.GetCircuitTrialCount()}
+	cooldown := ch.GetSpec().GetCircuitBreakerCooldown()
+
+	trialDone := supSpec.getClock().After(cooldown)
+
+	go func() {
+		select {
+		case <-trialDone:
+			// when the monitor loop is over, the message is dropped
+			_ = sendCtrlMsg(lc.ctx, lc.ctrlChan, msg)
+		case <-lc.ctx.Done():
+		}
+	}()
+}
+
+// openChildNodeCircuit replaces a child that opened its circuit breaker with a
+// child that waits for the breaker's cooldown period without running. The
+// siblings of the child are not affected, and the failure is not accounted on
+// the restart tolerance of the supervisor.
+func openChildNodeCircuit(
+	supCtx context.Context,
+	supSpec SupervisorSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+) map[string]c.Child {
+	// the restart of a child with an open circuit always succeeds, the Start
+	// function of the child is not called
+	cooldownCh, _ := startChildNode(
+		supCtx, supSpec, supRuntimeName, supNotifyChan, sourceCh.GetSpec(), supChildren,
+	)
+	supChildren[sourceCh.GetName()] = cooldownCh
+	supSpec.getEventNotifier().withTags(sourceCh.GetSpec()).childCircuitOpened(
+		sourceCh.GetTag(), sourceCh.GetRuntimeName(),
+	)
+	return supChildren
+}
+
+// handleCircuitCooldownNotification handles the notification of a child that
+// waited for the cooldown period of its open circuit breaker. The child is
+// started again as a trial, regardless of the supervisor strategy; when the
+// child fails to start, the start error is handled as a failure of the trial.
+func handleCircuitCooldownNotification(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	sourceCh = sourceCh.HalfOpenCircuit()
+	supChildren[sourceCh.GetName()] = sourceCh
+	supSpec.getEventNotifier().withTags(sourceCh.GetSpec()).childCircuitHalfOpened(
+		sourceCh.GetTag(), sourceCh.GetRuntimeName(),
+	)
+
+	newCh, startErr := startChildNode(
+		supCtx, supSpec, supRuntimeName, supNotifyChan, sourceCh.GetSpec(), supChildren,
+	)
+	if startErr != nil {
+		drainStartFailure(startErr, supNotifyChan)
+		return handleChildNodeError(
+			supCtx,
+			supTolerance,
+			supSpec, supChildSpecs,
+			supRuntimeName, supChildren, supNotifyChan,
+			sourceCh, startErr,
+		)
+	}
+
+	supChildren[newCh.GetName()] = newCh
+	scheduleCircuitTrial(supCtx, supSpec, newCh)
+	return supChildren, nil
+}
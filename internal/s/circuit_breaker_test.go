@@ -0,0 +1,181 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/captest"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	child1 := cap.NewWorker(
+		"child1",
+		func(context.Context) error {
+			return errors.New("child1 is broken")
+		},
+		cap.WithCircuitBreaker(3, 100*time.Millisecond),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// without the circuit breaker, the second failure would surpass
+			// the supervisor's default tolerance
+			evIt.WaitTill(WorkerCircuitOpened("root/child1"))
+			// the trial fails, and the circuit opens again
+			evIt.WaitTill(WorkerCircuitHalfOpened("root/child1"))
+			evIt.WaitTill(WorkerCircuitOpened("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerCircuitOpened("root/child1"),
+			WorkerCircuitHalfOpened("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerCircuitOpened("root/child1"),
+			// the child with an open circuit is not running, it is not
+			// terminated
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestCircuitBreakerClosesAfterTrial(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithCircuitBreaker(2, 50*time.Millisecond),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			// siblings are restarted on failures, but not when the circuit
+			// opens
+			cap.WithStrategy(cap.OneForAll),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerCircuitOpened("root/child1"))
+
+			// the trial runs for a whole cooldown period without failing
+			failWorker1(true /* done */)
+			evIt.WaitTill(WorkerCircuitClosed("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerCircuitOpened("root/child1"),
+			WorkerCircuitHalfOpened("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerCircuitClosed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestCircuitBreakerCooldownUsesClock(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithCircuitBreaker(2, time.Hour),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerCircuitOpened("root/child1"))
+
+			// the cooldown is over once the supervisor clock says so
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerCircuitHalfOpened("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// the trial runs for a whole cooldown period without failing
+			failWorker1(true /* done */)
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerCircuitClosed("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerCircuitOpened("root/child1"),
+			WorkerCircuitHalfOpened("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerCircuitClosed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,161 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// stopLoggedWorker is a worker that registers when it stops on the given log
+func stopLoggedWorker(rl *resourceLog, name string) cap.Node {
+	return cap.NewWorker(name, func(ctx context.Context) error {
+		<-ctx.Done()
+		rl.add("stop " + name)
+		return nil
+	})
+}
+
+func TestCleanupRunsAfterChildrenStop(t *testing.T) {
+	rl := &resourceLog{}
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(stopLoggedWorker(rl, "child1"), stopLoggedWorker(rl, "child2")),
+		[]cap.Opt{
+			cap.WithCleanup(func() error {
+				rl.add("cleanup")
+				return nil
+			}),
+			cap.WithResources([]cap.ResourceSpec{loggedResource(rl, "r1", nil)}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{"acquire r1", "stop child2", "stop child1", "cleanup", "release r1"},
+		rl.get(),
+	)
+}
+
+func TestCleanupRunsOnRestartToleranceSurpassed(t *testing.T) {
+	rl := &resourceLog{}
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, stopLoggedWorker(rl, "child2")),
+		[]cap.Opt{
+			cap.WithRestartTolerance(1, time.Minute),
+			cap.WithCleanup(func() error {
+				rl.add("cleanup")
+				return errors.New("pool close failed")
+			}),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerTerminated("root/child2"))
+		},
+	)
+
+	assert.Error(t, err)
+	// the cleanup runs exactly once, after the children stopped
+	assert.Equal(t, []string{"stop child2", "cleanup"}, rl.get())
+
+	var errKVs cap.ErrKVs
+	if assert.True(t, errors.As(err, &errKVs)) {
+		cleanupErr, ok := errKVs.KVs()["supervisor.termination.cleanup.error"].(error)
+		if assert.True(t, ok) {
+			assert.Equal(t, "pool close failed", cleanupErr.Error())
+		}
+	}
+}
+
+// startLoggedWorker is a worker that registers when it starts on the given log
+func startLoggedWorker(rl *resourceLog, name string) cap.Node {
+	return cap.NewWorker(name, func(ctx context.Context) error {
+		rl.add("start " + name)
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestSetupRunsBeforeChildrenStart(t *testing.T) {
+	rl := &resourceLog{}
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(startLoggedWorker(rl, "child1")),
+		[]cap.Opt{
+			cap.WithSetup(func(context.Context) (cap.CleanupResourcesFn, error) {
+				rl.add("setup")
+				return func() error {
+					rl.add("setup cleanup")
+					return nil
+				}, nil
+			}),
+			cap.WithCleanup(func() error {
+				rl.add("cleanup")
+				return nil
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{"setup", "start child1", "cleanup", "setup cleanup"},
+		rl.get(),
+	)
+}
+
+func TestSetupFailureAbortsStart(t *testing.T) {
+	rl := &resourceLog{}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(startLoggedWorker(rl, "child1")),
+		[]cap.Opt{
+			cap.WithResources([]cap.ResourceSpec{loggedResource(rl, "r1", nil)}),
+			cap.WithSetup(func(context.Context) (cap.CleanupResourcesFn, error) {
+				return nil, errors.New("database is not reachable")
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	var setupErr *cap.SupervisorSetupError
+	if assert.True(t, errors.As(err, &setupErr)) {
+		assert.Equal(t, "database is not reachable", setupErr.KVs()["supervisor.setup.error"].(error).Error())
+	}
+	assert.Equal(
+		t,
+		"supervisor 'root' setup function failed\n\t> database is not reachable",
+		cap.ExplainError(err),
+	)
+	// no children get started, and acquired resources are released
+	assert.Equal(t, []string{"acquire r1", "release r1"}, rl.get())
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
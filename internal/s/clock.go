@@ -0,0 +1,49 @@
+package s
+
+// This file contains the clock the supervisor uses to measure time
+
+import (
+	"time"
+)
+
+// Clock is the source of time of a supervisor. The restart tolerance window,
+// the restart dampening window, the time-based settings of children (e.g.
+// start timeouts and periodic restarts), the overlap of swapped children and
+// the start and stop durations reported on events are measured with it.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel that receives the current time once the given
+	// duration has elapsed
+	After(d time.Duration) <-chan time.Time
+	// Sleep blocks the current goroutine for the given duration
+	Sleep(d time.Duration)
+}
+
+// realClock is a Clock that uses the functions of the time package
+type realClock struct{}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep pauses the current goroutine for the given duration
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// getClock returns the Clock of the supervisor, when no Clock was specified,
+// it returns a Clock that uses the functions of the time package
+func (spec SupervisorSpec) getClock() Clock {
+	if spec.clock == nil {
+		return realClock{}
+	}
+	return spec.clock
+}
@@ -0,0 +1,77 @@
+package s
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stepClock is a Clock that only moves forward when it is advanced
+type stepClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (sc *stepClock) Now() time.Time {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.now
+}
+
+func (sc *stepClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (sc *stepClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (sc *stepClock) advance(d time.Duration) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.now = sc.now.Add(d)
+}
+
+func TestClockEventDurations(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+
+	var mu sync.Mutex
+	durations := make(map[string]time.Duration)
+	notifier := func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		durations[ev.GetTag().String()+" "+ev.GetProcessRuntimeName()] = ev.duration
+	}
+
+	worker := NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart NotifyStartFn) error {
+			clock.advance(time.Minute)
+			notifyStart(nil)
+			<-ctx.Done()
+			clock.advance(time.Second)
+			return nil
+		},
+	)
+
+	sup, err := NewSupervisorSpec(
+		"root",
+		WithNodes(worker),
+		WithClock(clock),
+		WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.NoError(t, sup.Terminate())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the start and stop durations are measured with the supervisor clock
+	assert.Equal(t, time.Minute, durations["ProcessStarted root/child1"])
+	assert.Equal(t, time.Minute, durations["ProcessStarted root"])
+	assert.Equal(t, time.Second, durations["ProcessTerminated root/child1"])
+	assert.Equal(t, time.Second, durations["ProcessTerminated root"])
+}
@@ -0,0 +1,378 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/captest"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestClockRestartToleranceWindow(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithRestartTolerance(1, time.Minute),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// the second failure happens after the tolerance window, it doesn't
+			// surpass the restart tolerance
+			clock.Advance(2 * time.Minute)
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockRestartDampeningInherited(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithRestartDampening(time.Hour),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false)
+			evIt.WaitTill(SupervisorGroupRestarted("root/subtree", "root/subtree/child1"))
+			failWorker1(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/subtree/child1"))
+
+			// the sub-tree waits for the dampening window on the clock of the
+			// root supervisor
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerExitedBackoff("root/subtree/child1"))
+			evIt.WaitTill(SupervisorGroupRestarted("root/subtree", "root/subtree/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorStarted("root/subtree"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerTerminated("root/subtree/child2"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorGroupRestarted("root/subtree", "root/subtree/child1"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerEnteredBackoff("root/subtree/child1"),
+			WorkerExitedBackoff("root/subtree/child1"),
+			WorkerTerminated("root/subtree/child2"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorGroupRestarted("root/subtree", "root/subtree/child1"),
+			WorkerTerminated("root/subtree/child2"),
+			WorkerTerminated("root/subtree/child1"),
+			SupervisorTerminated("root/subtree"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockHealDuration(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithRestartTolerance(1, time.Hour),
+			cap.WithHealDuration(time.Minute),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// the child is stable for longer than the heal duration, its
+			// previous restart is forgiven
+			clock.Advance(2 * time.Minute)
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockMinRuntime(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2, "child1", cap.WithMinRuntime(time.Minute, 4),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithRestartTolerance(3, time.Hour),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			// the child runs for longer than its min runtime, its failures
+			// count once
+			clock.Advance(2 * time.Minute)
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			clock.Advance(2 * time.Minute)
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockCircuitBreakerCooldown(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2, "child1", cap.WithCircuitBreaker(2, time.Minute),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// the child runs for a whole cooldown period, its failures are not
+			// consecutive anymore
+			clock.Advance(2 * time.Minute)
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockPeriodicRestart(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithPeriodicRestart(time.Hour),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			// the interval is measured with the supervisor clock
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerRestartedPeriodically("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedPeriodically("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestClockStartTimeout(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	doneCh := make(chan struct{})
+
+	// the timeout is measured with the supervisor clock
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}()
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(hungStartWorker("child1", doneCh, cap.WithStartTimeout(time.Hour))),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrStartTimeout))
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStartFailed("root/child1"),
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestClockSwapChildOverlap(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	clock := captest.NewFakeClock(time.Now())
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		cap.WithClock(clock),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	swapErrCh := make(chan error, 1)
+	go func() {
+		swapErrCh <- sup.SwapChild("child1", WaitDoneWorker("ignored"), time.Hour)
+	}()
+
+	// both versions run until the overlap is over on the supervisor clock
+	evIt.WaitTill(WorkerSwapStarted("root/child1"))
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.NoError(t, <-swapErrCh)
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1.next"),
+			WorkerSwapStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			WorkerSwapCompleted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,30 @@
+package s
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitCtrlResultSupervisorGone(t *testing.T) {
+	sup := Supervisor{doneCh: make(chan struct{})}
+	close(sup.doneCh)
+
+	// the supervisor accepted the message, but it is gone without a result
+	resultChan := make(chan error, 1)
+	err := sup.waitCtrlResult(resultChan)
+	assert.True(t, errors.Is(err, ErrSupervisorUnreachable))
+	assert.True(t, errors.Is(err, ErrSupervisorTerminated))
+}
+
+func TestWaitCtrlResultReportedBeforeTermination(t *testing.T) {
+	sup := Supervisor{doneCh: make(chan struct{})}
+	close(sup.doneCh)
+
+	// the result is reported right before the supervisor is gone
+	resultChan := make(chan error, 1)
+	resultErr := errors.New("result error")
+	resultChan <- resultErr
+	assert.Equal(t, resultErr, sup.waitCtrlResult(resultChan))
+}
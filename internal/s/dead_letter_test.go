@@ -0,0 +1,159 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// deadLetters keeps track of the calls of a dead letter callback
+type deadLetters struct {
+	mu      sync.Mutex
+	names   []string
+	lastErr error
+}
+
+func (dl *deadLetters) add(runtimeName string, lastErr error) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.names = append(dl.names, runtimeName)
+	dl.lastErr = lastErr
+}
+
+func (dl *deadLetters) get() ([]string, error) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return append([]string{}, dl.names...), dl.lastErr
+}
+
+func TestDeadLetterOnRestartToleranceSurpassed(t *testing.T) {
+	dl := &deadLetters{}
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithRestartTolerance(1, time.Minute),
+			cap.WithDeadLetter(dl.add),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.Error(t, err)
+
+	names, lastErr := dl.get()
+	assert.Equal(t, []string{"root/child1"}, names)
+
+	var toleranceErr *cap.RestartToleranceReached
+	if assert.True(t, errors.As(lastErr, &toleranceErr)) {
+		kvs := toleranceErr.KVs()
+		assert.Equal(t, "root/child1", kvs["node.name"])
+		assert.Equal(t, "failing child (2 out of 2)", kvs["node.error.last.msg"])
+	}
+	assert.Equal(t, "failing child (2 out of 2)", errors.Unwrap(lastErr).Error())
+}
+
+func TestDeadLetterOnTransientBudgetExhausted(t *testing.T) {
+	dl := &deadLetters{}
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithRestart(cap.Transient),
+		cap.WithTransientBudget(1, time.Minute),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			cap.WithRestartTolerance(10, time.Minute),
+			cap.WithDeadLetter(dl.add),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	// the supervisor doesn't crash, the child is dropped
+	assert.NoError(t, err)
+
+	names, lastErr := dl.get()
+	assert.Equal(t, []string{"root/child1"}, names)
+
+	var toleranceErr *cap.RestartToleranceReached
+	if assert.True(t, errors.As(lastErr, &toleranceErr)) {
+		kvs := toleranceErr.KVs()
+		assert.Equal(t, "failing child (2 out of 2)", kvs["node.error.msg"])
+		assert.Equal(t, uint32(1), kvs["node.error.budget.count"])
+		assert.Equal(t, time.Minute, kvs["node.error.budget.duration"])
+	}
+	assert.Equal(
+		t,
+		"worker node 'root/child1' failed more than 1 times in a 1m0s window, "+
+			"it is not going to be restarted.\n"+
+			"the last error reported was:\n"+
+			"\t> failing child (2 out of 2)",
+		cap.ExplainError(lastErr),
+	)
+}
+
+func TestDeadLetterOnRestartFailure(t *testing.T) {
+	dl := &deadLetters{}
+	var builds int32
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		func() ([]cap.Node, cap.CleanupResourcesFn, error) {
+			// child2 cannot start after a restart
+			if atomic.AddInt32(&builds, 1) > 1 {
+				return []cap.Node{WaitDoneWorker("child1"), FailStartWorker("child2")}, nil, nil
+			}
+			return []cap.Node{WaitDoneWorker("child1"), WaitDoneWorker("child2")}, nil, nil
+		},
+		cap.WithDeadLetter(dl.add),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Error(t, sup.Restart())
+	assert.Error(t, sup.Wait())
+
+	// the child that failed to start is reported, not the supervisor
+	names, lastErr := dl.get()
+	assert.Equal(t, []string{"root/child2"}, names)
+
+	var toleranceErr *cap.RestartToleranceReached
+	if assert.True(t, errors.As(lastErr, &toleranceErr)) {
+		assert.Equal(t, "root/child2", toleranceErr.GetFailedChildName())
+		kvs := toleranceErr.KVs()
+		assert.Equal(t, "root/child2", kvs["node.name"])
+		assert.Equal(t, "root", kvs["supervisor.name"])
+	}
+	assert.Contains(
+		t,
+		cap.ExplainError(lastErr),
+		"worker node 'root/child2' could not be started again after a restart.",
+	)
+}
@@ -0,0 +1,203 @@
+package s
+
+// This file contains the logic for the dependencies declared with WithDependsOn
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// validateDependencies checks that every dependency declared on the given
+// children names one of its siblings, and that no child depends on a sibling
+// of a later start phase.
+func validateDependencies(children []c.ChildSpec) error {
+	phases := make(map[string]int, len(children))
+	for _, chSpec := range children {
+		phases[chSpec.GetName()] = chSpec.GetStartPhase()
+	}
+
+	for _, chSpec := range children {
+		for _, depName := range chSpec.GetDependsOn() {
+			if depName == chSpec.GetName() {
+				return fmt.Errorf("child '%s' cannot depend on itself", chSpec.GetName())
+			}
+			depPhase, ok := phases[depName]
+			if !ok {
+				return fmt.Errorf(
+					"child '%s' depends on '%s', which is not a sibling",
+					chSpec.GetName(),
+					depName,
+				)
+			}
+			if depPhase > chSpec.GetStartPhase() {
+				return fmt.Errorf(
+					"child '%s' (phase %d) depends on '%s', which starts in the later phase %d",
+					chSpec.GetName(),
+					chSpec.GetStartPhase(),
+					depName,
+					depPhase,
+				)
+			}
+		}
+	}
+
+	if cycle := findDependencyCycle(children); len(cycle) > 0 {
+		return fmt.Errorf("children have a dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	return nil
+}
+
+// findDependencyCycle returns the names of the children that form a dependency
+// cycle, starting and ending with the same name; it returns nil when the
+// dependencies of the given children are acyclic.
+func findDependencyCycle(children []c.ChildSpec) []string {
+	deps := make(map[string][]string, len(children))
+	for _, chSpec := range children {
+		deps[chSpec.GetName()] = chSpec.GetDependsOn()
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(children))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			// the path contains the cycle from the first visit of this name
+			for i, pathName := range path {
+				if pathName == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, depName := range deps[name] {
+			if cycle := visit(depName); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, chSpec := range children {
+		if cycle := visit(chSpec.GetName()); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// sortByDependencies returns the given children sorted so that every child
+// comes after the siblings it depends on; children keep their relative order
+// otherwise. Dependencies on children that are not in the given slice are
+// ignored.
+//
+// The children are sorted with Kahn's algorithm, the ready child that comes
+// first in the given slice is placed next. Dependency cycles are rejected when
+// the supervisor is built (see validateDependencies).
+func sortByDependencies(input []c.ChildSpec) []c.ChildSpec {
+	indexes := make(map[string]int, len(input))
+	for i, chSpec := range input {
+		indexes[chSpec.GetName()] = i
+	}
+
+	// indegrees holds the number of dependencies of each child that are not
+	// placed yet, and dependents the children that depend on each child
+	indegrees := make([]int, len(input))
+	dependents := make([][]int, len(input))
+	for i, chSpec := range input {
+		for _, depName := range chSpec.GetDependsOn() {
+			if depIndex, ok := indexes[depName]; ok {
+				indegrees[i]++
+				dependents[depIndex] = append(dependents[depIndex], i)
+			}
+		}
+	}
+
+	ready := &indexHeap{}
+	for i, indegree := range indegrees {
+		if indegree == 0 {
+			heap.Push(ready, i)
+		}
+	}
+
+	output := make([]c.ChildSpec, 0, len(input))
+	for ready.Len() > 0 {
+		i := heap.Pop(ready).(int)
+		output = append(output, input[i])
+		for _, dependent := range dependents[i] {
+			indegrees[dependent]--
+			if indegrees[dependent] == 0 {
+				heap.Push(ready, dependent)
+			}
+		}
+	}
+
+	if len(output) != len(input) {
+		panic("library bug: dependency cycle found after the supervisor was built")
+	}
+	return output
+}
+
+// indexHeap is a min-heap of indexes of a slice of children, it implements
+// heap.Interface
+type indexHeap []int
+
+func (h indexHeap) Len() int           { return len(h) }
+func (h indexHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h indexHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *indexHeap) Push(x interface{}) {
+	*h = append(*h, x.(int))
+}
+
+func (h *indexHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// dependentsOf returns the specs of the children that depend, directly or
+// transitively, on the child with the given name
+func dependentsOf(children []c.ChildSpec, name string) []c.ChildSpec {
+	closure := map[string]struct{}{name: {}}
+	for changed := true; changed; {
+		changed = false
+		for _, chSpec := range children {
+			if _, ok := closure[chSpec.GetName()]; ok {
+				continue
+			}
+			for _, depName := range chSpec.GetDependsOn() {
+				if _, ok := closure[depName]; ok {
+					closure[chSpec.GetName()] = struct{}{}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var dependents []c.ChildSpec
+	for _, chSpec := range children {
+		if _, ok := closure[chSpec.GetName()]; ok && chSpec.GetName() != name {
+			dependents = append(dependents, chSpec)
+		}
+	}
+	return dependents
+}
@@ -0,0 +1,253 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// dependentWorker creates a worker that waits for its supervisor to terminate,
+// and depends on the given sibling names
+func dependentWorker(name string, deps ...string) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithDependsOn(deps...),
+	)
+}
+
+func TestDependsOnValidSiblings(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			dependentWorker("child2", "child1"),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestDependsOnDanglingDependency(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			dependentWorker("child2", "chlid1"),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+
+	var buildErr *cap.SupervisorBuildError
+	assert.True(t, errors.As(err, &buildErr))
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> child 'child2' depends on 'chlid1', which is not a sibling",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestDependsOnRestartsDependents(t *testing.T) {
+	childA, failWorkerA := FailOnSignalWorker(1, "a")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			// dependents are started after their dependencies, regardless of
+			// the order of the nodes
+			dependentWorker("c", "b"),
+			dependentWorker("b", "a"),
+			childA,
+			WaitDoneWorker("d"),
+		),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			failWorkerA(true /* done */)
+			evIt.WaitTill(WorkerFailed("root/a"))
+			evIt.WaitTill(WorkerStarted("root/c"))
+		},
+	)
+
+	assert.NoError(t, err)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/a"),
+			WorkerStarted("root/b"),
+			WorkerStarted("root/c"),
+			WorkerStarted("root/d"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/a"),
+			// the transitive dependents of the failed child are restarted in
+			// dependency order, the independent sibling keeps running
+			WorkerTerminated("root/c"),
+			WorkerTerminated("root/b"),
+			WorkerStarted("root/a"),
+			WorkerStarted("root/b"),
+			WorkerStarted("root/c"),
+			WorkerTerminated("root/d"),
+			WorkerTerminated("root/c"),
+			WorkerTerminated("root/b"),
+			WorkerTerminated("root/a"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestDependsOnCycle(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			dependentWorker("a", "c"),
+			dependentWorker("b", "a"),
+			dependentWorker("c", "b"),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> children have a dependency cycle: a -> c -> b -> a",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestDependsOnConcurrentStartup(t *testing.T) {
+	var aStarted int32
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			cap.NewWorkerWithNotifyStart(
+				"a",
+				func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+					time.Sleep(100 * time.Millisecond)
+					atomic.StoreInt32(&aStarted, 1)
+					notifyStart(nil)
+					<-ctx.Done()
+					return nil
+				},
+			),
+			cap.NewWorkerWithNotifyStart(
+				"b",
+				func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+					// b must not be started while its dependency is starting
+					if atomic.LoadInt32(&aStarted) == 0 {
+						err := errors.New("b started before a")
+						notifyStart(err)
+						return err
+					}
+					notifyStart(nil)
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithDependsOn("a"),
+			),
+		),
+		[]cap.Opt{
+			cap.WithStartupConcurrency(2),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/a"),
+			WorkerStarted("root/b"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/b"),
+			WorkerTerminated("root/a"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestDependsOnLaterPhase(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			dependentWorker("a", "b"),
+			cap.NewWorker(
+				"b",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithStartPhase(1),
+			),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+
+	var buildErr *cap.SupervisorBuildError
+	assert.True(t, errors.As(err, &buildErr))
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> child 'a' (phase 0) depends on 'b', which starts in the later phase 1",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
@@ -3,6 +3,8 @@ package s
 import (
 	"context"
 
+	"golang.org/x/time/rate"
+
 	"github.com/capatazlib/go-capataz/internal/c"
 )
 
@@ -13,14 +15,23 @@ type Spawner interface {
 }
 
 type spawnerClient struct {
+	ctx      context.Context
 	ctrlChan chan ctrlMsg
+	limiter  *rate.Limiter
 }
 
-func newSpawnerClient(ctrlChan chan ctrlMsg) spawnerClient {
-	return spawnerClient{ctrlChan: ctrlChan}
+func newSpawnerClient(
+	ctx context.Context,
+	ctrlChan chan ctrlMsg,
+	limiter *rate.Limiter,
+) spawnerClient {
+	return spawnerClient{ctx: ctx, ctrlChan: ctrlChan, limiter: limiter}
 }
 
 func (s spawnerClient) Spawn(node Node) (func() error, error) {
+	if err := waitSpawnLimiter(s.ctx, s.limiter); err != nil {
+		return nil, err
+	}
 	return sendSpawnToSupervisor(s.ctrlChan, node)
 }
 
@@ -91,7 +102,9 @@ func NewDynSubtreeWithNotifyStart(
 						func(ctx context.Context, notifyStart NotifyStartFn) error {
 							// we create a value that allows this the spawner to communicate
 							// with the subtree in a safe way.
-							spawner := newSpawnerClient(ctrlChan)
+							spawner := newSpawnerClient(
+								ctx, ctrlChan, spawnerSpec.newSpawnLimiter(),
+							)
 							return runFn(ctx, notifyStart, spawner)
 						},
 						opts...,
@@ -112,6 +112,7 @@ func TestDynSubtreeFailing(t *testing.T) {
 			WorkerStarted("root/one/subtree/dos"),
 			WorkerStarted("root/one/subtree/tres"),
 			WorkerStarted("root/one/spawner"),
+			SupervisorGroupRestarted("root/one", "root/one/spawner"),
 
 			// dyn subtree terminates in reverse order
 			WorkerTerminated("root/one/spawner"),
@@ -190,6 +191,7 @@ func TestDynSubtreeFailedSpawnedWorker(t *testing.T) {
 			WorkerStarted("root/one/subtree/child1"),
 			WorkerStarted("root/one/subtree/child2"),
 			WorkerStarted("root/one/spawner"),
+			SupervisorGroupRestarted("root/one", "root/one/subtree"),
 
 			// dyn subtree terminates in reverse order
 			WorkerTerminated("root/one/spawner"),
@@ -223,7 +225,7 @@ func TestDynSubtreeFailedTermination(t *testing.T) {
 
 	// assert that error contains all information required to assess what went wrong
 	assert.Error(t, err)
-	assert.Equal(t, "supervisor terminated with failures", err.Error())
+	assert.Equal(t, "supervisor terminated with failures (dyn/subtree/child1: child1 failed)", err.Error())
 
 	t.Run("starts and stops routines in the correct order", func(t *testing.T) {
 		AssertExactMatch(t, events,
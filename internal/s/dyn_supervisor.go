@@ -3,10 +3,11 @@ package s
 import (
 	"context"
 	"errors"
-	"fmt"
 	"runtime/debug"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/capatazlib/go-capataz/internal/c"
 )
 
@@ -49,16 +50,14 @@ func (scm startChildMsg) processMsg(
 ) ([]c.ChildSpec, map[string]c.Child) {
 	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
 
-	childSpec := scm.node(spec)
+	childSpec := spec.applyStartMiddleware(scm.node(spec)).AsSpawned()
 
-	ch, startErr := startChildNode(supCtx, spec, supRuntimeName, supNotifyChan, childSpec)
+	// spawned children are not part of a restart, even when this supervisor
+	// was restarted
+	startCtx := withRestartMark(supCtx, false)
+	ch, startErr := startChildNode(startCtx, spec, supRuntimeName, supNotifyChan, childSpec, nil)
 	if startErr != nil {
-		// When we fail, we send an error to the supNotifyChan and return the error,
-		// this doesn't have any detrimental consequence in static supervisors,
-		// but on dynamic supervisors, it means the monitor loop will get bothered
-		// with an error that it should not really handle. We are going to read it
-		// out and return after that.
-		<-supNotifyChan
+		drainStartFailure(startErr, supNotifyChan)
 		// do not block waiting for a read
 		select {
 		case scm.resultChan <- startChildResult{
@@ -114,10 +113,9 @@ func (tcm terminateChildMsg) processMsg(
 
 	ch, ok := supChildren[tcm.nodeName]
 	if !ok {
-		errMsg := fmt.Sprintf("worker %s not found", tcm.nodeName)
 		// do not block waiting for a read
 		select {
-		case tcm.resultChan <- errors.New(errMsg):
+		case tcm.resultChan <- c.WrapSentinel(ErrNodeNotFound, nil, "worker %s not found", tcm.nodeName):
 		default:
 		}
 
@@ -126,7 +124,7 @@ func (tcm terminateChildMsg) processMsg(
 
 	// we call our basic terminateChildNode function that is found in the
 	// monitor.go file
-	terminateErr := terminateChildNode(evNotifier, ch)
+	terminateErr := terminateChildNode(spec, ch)
 
 	// do not block waiting for a read
 	select {
@@ -153,6 +151,9 @@ type DynSupervisor struct {
 	sup            Supervisor
 	terminated     bool
 	terminationErr error
+
+	ctx          context.Context
+	spawnLimiter *rate.Limiter
 }
 
 // handleCtrlMsg is used in the supervisor monitor loop to operator over public
@@ -197,7 +198,10 @@ func buildTerminateNodeCallback(ctrlChan chan ctrlMsg, nodeName string) func() e
 			}
 
 			if panicErr, ok := panicVal.(error); ok {
-				err = fmt.Errorf("could not talk to supervisor: %w\n%s", panicErr, debug.Stack())
+				err = c.WrapSentinel(
+					ErrSupervisorUnreachable, panicErr,
+					"could not talk to supervisor: %v\n%s", panicErr, debug.Stack(),
+				)
 				return
 			}
 
@@ -212,7 +216,7 @@ func buildTerminateNodeCallback(ctrlChan chan ctrlMsg, nodeName string) func() e
 			// This scenario can happen when the supervisor is being terminated and the
 			// non-blocking sup.GetCrashError happened just before that (race
 			// condition).
-			err = errors.New("could not talk to supervisor")
+			err = ErrSupervisorUnreachable
 			return
 		}
 
@@ -241,7 +245,7 @@ func sendSpawnToSupervisor(ctrlChan chan ctrlMsg, node Node) (func() error, erro
 		// This scenario can happen when the supervisor is being terminated and the
 		// non-blocking sup.GetCrashError happened just before that (race
 		// condition).
-		return nil, errors.New("could not talk to supervisor")
+		return nil, ErrSupervisorUnreachable
 	}
 
 	select {
@@ -264,33 +268,83 @@ func sendSpawnToSupervisor(ctrlChan chan ctrlMsg, node Node) (func() error, erro
 // Spawn creates a new worker routine from the given node specification. It
 // either returns a cancel/shutdown callback or an error in the scenario the
 // start of this worker failed. This function blocks until the worker is
-// started.
+// started, and before that, until the spawn rate limit of the supervisor allows
+// the spawn (see WithSpawnRateLimit).
 func (dyn *DynSupervisor) Spawn(nodeFn Node) (func() error, error) {
 	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
 
 	// if we already registered a terminationErr, return it
 	if dyn.terminated {
-		return nil, fmt.Errorf("supervisor already terminated: %w", dyn.terminationErr)
+		return nil, c.WrapSentinel(
+			ErrSupervisorTerminated, dyn.terminationErr,
+			"supervisor already terminated: %v", dyn.terminationErr,
+		)
 	}
 
 	// if the underlying supervisor is kaput, return the error
 	if terminated, terminationErr := dyn.sup.GetCrashError(false); terminated {
 		dyn.terminated = true
 		dyn.terminationErr = terminationErr
-		return nil, fmt.Errorf("supervisor already terminated: %w", terminationErr)
+		return nil, c.WrapSentinel(
+			ErrSupervisorTerminated, terminationErr,
+			"supervisor already terminated: %v", terminationErr,
+		)
+	}
+
+	if err := waitSpawnLimiter(dyn.ctx, dyn.spawnLimiter); err != nil {
+		return nil, err
 	}
 
 	return sendSpawnToSupervisor(dyn.sup.ctrlCh, nodeFn)
 }
 
+// Notifications returns a channel that receives the notification of every
+// child that fails in this supervision tree. Check Supervisor.Notifications for
+// details.
+func (dyn *DynSupervisor) Notifications() <-chan c.ChildNotification {
+	return dyn.sup.Notifications()
+}
+
 // Terminate is a synchronous procedure that halts the execution of the whole
 // supervision tree.
+//
+// Workers are stopped strictly in the reverse order they were spawned, waiting
+// for each worker to honor its Shutdown setting before moving to the next one.
 func (dyn *DynSupervisor) Terminate() error {
 	dyn.terminationErr = dyn.sup.Terminate()
 	dyn.terminated = true
 	return dyn.terminationErr
 }
 
+// TerminateWithTimeout behaves like Terminate, with the difference that the
+// supervisor stops waiting for its workers once the given duration expires.
+// Check Supervisor.TerminateWithTimeout for details.
+func (dyn *DynSupervisor) TerminateWithTimeout(d time.Duration) error {
+	dyn.terminationErr = dyn.sup.TerminateWithTimeout(d)
+	dyn.terminated = true
+	return dyn.terminationErr
+}
+
+// Restart is a synchronous procedure that terminates all the children of the
+// supervisor, and starts again the children given on construction (see
+// NewDynSupervisorWithNodes). Spawned children cannot be started again, they
+// are discarded and reported in a DynChildrenDiscarded event.
+func (dyn *DynSupervisor) Restart() error {
+	if dyn.terminated {
+		return c.WrapSentinel(
+			ErrSupervisorTerminated, dyn.terminationErr,
+			"supervisor already terminated: %v", dyn.terminationErr,
+		)
+	}
+	return dyn.sup.Restart()
+}
+
+// WaitStarted blocks until every worker of this supervisor has notified its
+// start. Check Supervisor.WaitStarted for details.
+func (dyn DynSupervisor) WaitStarted(ctx context.Context) error {
+	return dyn.sup.WaitStarted(ctx)
+}
+
 // Wait blocks the execution of the current goroutine until the Supervisor
 // finishes it execution.
 func (dyn DynSupervisor) Wait() error {
@@ -317,16 +371,34 @@ func (dyn DynSupervisor) GetName() string {
 // As opposed to a Supervisor, a DynSupervisor:
 //
 // * Cannot receive node specifications to start them in an static fashion
+// (use NewDynSupervisorWithNodes for that)
 //
 // * It is able to spawn workers dynamically
 //
 //   - In case of a hard crash and following restart, it will start with an empty
 //     list of children
+//
+// A DynSupervisor without children keeps running until it is terminated, which
+// makes it useful as a lifecycle anchor for a shared resource; use WithSetup
+// and WithCleanup to acquire the resource on start and to release it once
+// every spawned worker is stopped.
 func NewDynSupervisor(ctx context.Context, name string, opts ...Opt) (DynSupervisor, error) {
-	spec := NewSupervisorSpec(name, WithNodes(), opts...)
+	return NewDynSupervisorWithNodes(ctx, name, WithNodes(), opts...)
+}
+
+// NewDynSupervisorWithNodes creates a DynSupervisor that starts the nodes
+// returned by the given BuildNodesFn, as a regular Supervisor would, and that
+// is able to spawn more workers at runtime.
+func NewDynSupervisorWithNodes(
+	ctx context.Context,
+	name string,
+	buildNodes BuildNodesFn,
+	opts ...Opt,
+) (DynSupervisor, error) {
+	spec := NewSupervisorSpec(name, buildNodes, opts...)
 	sup, err := spec.Start(ctx)
 	if err != nil {
 		return DynSupervisor{}, err
 	}
-	return DynSupervisor{sup: sup}, nil
+	return DynSupervisor{sup: sup, ctx: ctx, spawnLimiter: spec.newSpawnLimiter()}, nil
 }
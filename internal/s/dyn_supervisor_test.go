@@ -7,6 +7,7 @@ package s_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -220,6 +221,7 @@ func TestDynTerminateFailedChild(t *testing.T) {
 			// ---- stop of the supervisor begins here
 
 			WorkerTerminated("root/branch1/child3"),
+			WorkerAbandonedOnShutdown("root/branch1/child2"),
 			WorkerFailed("root/branch1/child2"),
 			// ^^^ child2 never stops and fails with a timeout caused by the
 			// NeverTerminateWorker specification
@@ -243,6 +245,51 @@ func TestDynSpawnAfterTerminate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDynEmptySupervisorWithSetup(t *testing.T) {
+	var lifecycle []string
+
+	events, errs := ObserveDynSupervisor(
+		context.TODO(),
+		"root",
+		[]cap.Node{},
+		[]cap.Opt{
+			cap.WithSetup(func(context.Context) (cap.CleanupResourcesFn, error) {
+				lifecycle = append(lifecycle, "setup")
+				return nil, nil
+			}),
+			cap.WithCleanup(func() error {
+				lifecycle = append(lifecycle, "cleanup")
+				return nil
+			}),
+		},
+		func(sup cap.DynSupervisor, em EventManager) {
+			// a supervisor without children keeps running until it is
+			// terminated
+			time.Sleep(50 * time.Millisecond)
+			// only the start of the supervisor is reported
+			assert.Len(t, em.Snapshot(), 1)
+
+			_, err := sup.Spawn(WaitDoneWorker("one"))
+			assert.NoError(t, err)
+
+			evIt := em.Iterator()
+			evIt.WaitTill(WorkerStarted("root/one"))
+		},
+	)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"setup", "cleanup"}, lifecycle)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStarted("root"),
+			WorkerStarted("root/one"),
+			WorkerTerminated("root/one"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
 func TestDynSpawnAfterCrashedSupervisor(t *testing.T) {
 	failingNode, failWorker := FailOnSignalWorker(1, "failing")
 
@@ -354,6 +401,7 @@ func TestDynCancelAlreadyTerminatedWorker(t *testing.T) {
 			err = cancelWorker1()
 			assert.Error(t, err)
 			assert.Equal(t, err.Error(), "worker one not found")
+			assert.True(t, errors.Is(err, cap.ErrNodeNotFound))
 
 			// spawn a second worker to spice the test a little
 			_, err = sup.Spawn(WaitDoneWorker("two"))
@@ -390,4 +438,9 @@ func TestDynCancelAlreadyTerminatedSupervisor(t *testing.T) {
 	err = cancelWorker()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not talk to supervisor: send on closed channel")
+	assert.True(t, errors.Is(err, cap.ErrSupervisorUnreachable))
+
+	_, err = sup.Spawn(WaitDoneWorker("two"))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrSupervisorTerminated))
 }
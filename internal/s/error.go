@@ -18,6 +18,33 @@ type terminateNodeError = error
 // node fails
 type startNodeError = error
 
+var (
+	// ErrNodeNotFound is reported when a supervisor is asked to operate on a
+	// child node that it is not running
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrSupervisorUnreachable is reported when a request cannot be delivered
+	// to a supervisor
+	ErrSupervisorUnreachable = errors.New("could not talk to supervisor")
+	// ErrSupervisorTerminated is reported when a request is sent to a
+	// supervisor that is not running anymore
+	ErrSupervisorTerminated = errors.New("supervisor terminated")
+	// ErrForcedTermination is reported by RunWithSignals when a second signal
+	// arrives before the supervision tree finished its graceful termination
+	ErrForcedTermination = errors.New("supervisor termination forced")
+	// ErrSpawnRateLimited is reported when the context of a spawn call is done
+	// before the spawn rate limit of the supervisor allows it
+	ErrSpawnRateLimited = errors.New("spawn rate limit wait cancelled")
+	// ErrUnexpectedCleanExit is reported when a supervisor escalates the clean
+	// exit of a Transient child (see WithUnexpectedCleanExit)
+	ErrUnexpectedCleanExit = errors.New("child exited unexpectedly")
+	// ErrSwapInProgress is reported when a supervisor is asked to swap a child
+	// that is being swapped already
+	ErrSwapInProgress = errors.New("child swap in progress")
+	// ErrInvalidSpecDoc is reported by LoadSpec when the given document is not
+	// a valid supervision tree spec
+	ErrInvalidSpecDoc = errors.New("invalid supervisor spec document")
+)
+
 // ErrKVs is an utility interface used to get key-values out of Capataz errors
 type ErrKVs interface {
 	KVs() map[string]interface{}
@@ -29,19 +56,111 @@ type errExplain interface {
 	explainLines() []string
 }
 
+// nodeSeparator returns the given separator of the supervision tree an error
+// belongs to, or the default one when it is not set
+func nodeSeparator(sep string) string {
+	if sep == "" {
+		return NodeSepToken
+	}
+	return sep
+}
+
 // SupervisorTerminationError wraps errors returned by a child node that failed
 // to terminate (io errors, timeouts, etc.), enhancing it with supervisor
 // information. Note, the only way to have a valid SupervisorTerminationError is
 // for one of the child nodes to fail or the supervisor cleanup operation fails.
 type SupervisorTerminationError struct {
 	supRuntimeName string
+	nodeSep        string
 	nodeErrMap     map[string]error
 	rscCleanupErr  error
 }
 
-// Error returns an error message
+// Error returns an error message that summarizes the errors of the nodes that
+// failed to terminate, sorted by name, and the cleanup error of the
+// supervisor, if any
 func (err *SupervisorTerminationError) Error() string {
-	return "supervisor terminated with failures"
+	failures := err.summarize("")
+	if len(failures) == 0 {
+		return "supervisor terminated with failures"
+	}
+	return fmt.Sprintf("supervisor terminated with failures (%s)", strings.Join(failures, "; "))
+}
+
+// Is reports whether the error of any of the nodes that failed to terminate,
+// or the cleanup error of the supervisor, matches the given target (see
+// errors.Is)
+func (err *SupervisorTerminationError) Is(target error) bool {
+	for _, nodeErr := range err.errors() {
+		if errors.Is(nodeErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of the nodes that failed to terminate (sorted by
+// name), or the cleanup error of the supervisor, that matches the given target
+// (see errors.As)
+func (err *SupervisorTerminationError) As(target interface{}) bool {
+	for _, nodeErr := range err.errors() {
+		if errors.As(nodeErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the errors of the nodes that failed to terminate (sorted by
+// name), followed by the cleanup error of the supervisor, if any. This allows
+// the standard library (errors.Is, errors.As) to traverse the error like one
+// created with errors.Join.
+func (err *SupervisorTerminationError) Unwrap() []error {
+	return err.errors()
+}
+
+// errors returns the errors of the nodes that failed to terminate sorted by
+// name, followed by the cleanup error of the supervisor, if any
+func (err *SupervisorTerminationError) errors() []error {
+	nodeNames := make([]string, 0, len(err.nodeErrMap))
+	for nodeName := range err.nodeErrMap {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	errs := make([]error, 0, len(nodeNames)+1)
+	for _, nodeName := range nodeNames {
+		errs = append(errs, err.nodeErrMap[nodeName])
+	}
+	if err.rscCleanupErr != nil {
+		errs = append(errs, err.rscCleanupErr)
+	}
+	return errs
+}
+
+// summarize returns an entry per node that failed to terminate, sorted by name;
+// the nodes of sub-trees that failed to terminate are reported with their name
+// relative to this supervisor
+func (err *SupervisorTerminationError) summarize(prefix string) []string {
+	nodeNames := make([]string, 0, len(err.nodeErrMap))
+	for nodeName := range err.nodeErrMap {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	failures := make([]string, 0, len(nodeNames)+1)
+	for _, nodeName := range nodeNames {
+		nodeErr := err.nodeErrMap[nodeName]
+		if subtreeErr, ok := nodeErr.(*SupervisorTerminationError); ok {
+			failures = append(failures, subtreeErr.summarize(prefix+nodeName+nodeSeparator(err.nodeSep))...)
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s%s: %v", prefix, nodeName, nodeErr))
+	}
+	if err.rscCleanupErr != nil {
+		failures = append(failures, fmt.Sprintf("%scleanup: %v", prefix, err.rscCleanupErr))
+	}
+	return failures
 }
 
 // KVs returns a metadata map for structured logging
@@ -66,8 +185,10 @@ func (err *SupervisorTerminationError) KVs() map[string]interface{} {
 		} else {
 			acc[fmt.Sprintf("supervisor.termination.node.%d.name", i)] = nodeName
 			acc[fmt.Sprintf("supervisor.termination.node.%d.error", i)] = nodeErr
+			if errors.Is(nodeErr, c.ErrAbandoned) {
+				acc[fmt.Sprintf("supervisor.termination.node.%d.abandoned", i)] = true
+			}
 		}
-
 	}
 
 	if err.rscCleanupErr != nil {
@@ -95,7 +216,7 @@ func (err *SupervisorTerminationError) explainLines() []string {
 					nodeErrLines,
 					fmt.Sprintf("worker node '%s%s%s' failed to terminate",
 						err.supRuntimeName,
-						NodeSepToken,
+						nodeSeparator(err.nodeSep),
 						childName,
 					),
 				)
@@ -147,7 +268,7 @@ func (err *SupervisorTerminationError) explainLines() []string {
 					workerErrLines,
 					fmt.Sprintf("the worker node '%s%s%s' failed to terminate:",
 						err.supRuntimeName,
-						NodeSepToken,
+						nodeSeparator(err.nodeSep),
 						childName,
 					),
 				)
@@ -218,17 +339,26 @@ func (err *SupervisorTerminationError) explainLines() []string {
 type SupervisorBuildError struct {
 	supRuntimeName string
 	buildNodesErr  error
+	duplicateNames []string
 }
 
 func (err *SupervisorBuildError) Error() string {
 	return "supervisor build nodes function failed"
 }
 
+// Unwrap returns the error reported by the build nodes function
+func (err *SupervisorBuildError) Unwrap() error {
+	return err.buildNodesErr
+}
+
 // KVs returns a metadata map for structured logging
 func (err *SupervisorBuildError) KVs() map[string]interface{} {
 	acc := make(map[string]interface{})
 	acc["supervisor.name"] = err.supRuntimeName
 	acc["supervisor.build.error"] = err.buildNodesErr
+	if len(err.duplicateNames) > 0 {
+		acc["supervisor.build.duplicate_names"] = strings.Join(err.duplicateNames, ", ")
+	}
 	return acc
 }
 
@@ -250,11 +380,55 @@ func (err *SupervisorBuildError) explainLines() []string {
 	return outputLines
 }
 
+// SupervisorSetupError wraps errors returned from the client provided setup
+// function of a supervisor (see WithSetup), enhancing it with supervisor
+// information
+type SupervisorSetupError struct {
+	supRuntimeName string
+	setupErr       error
+}
+
+func (err *SupervisorSetupError) Error() string {
+	return "supervisor setup function failed"
+}
+
+// Unwrap returns the error reported by the setup function
+func (err *SupervisorSetupError) Unwrap() error {
+	return err.setupErr
+}
+
+// KVs returns a metadata map for structured logging
+func (err *SupervisorSetupError) KVs() map[string]interface{} {
+	acc := make(map[string]interface{})
+	acc["supervisor.name"] = err.supRuntimeName
+	acc["supervisor.setup.error"] = err.setupErr
+	return acc
+}
+
+// explainLines returns a human-friendly message of the error represented as a slice
+// of lines
+func (err *SupervisorSetupError) explainLines() []string {
+	var outputLines []string
+
+	outputLines = append(
+		outputLines,
+		fmt.Sprintf("supervisor '%s' setup function failed", err.supRuntimeName),
+	)
+
+	outputLines = append(
+		outputLines,
+		indentExplain(1, errToExplain(err.setupErr))...,
+	)
+
+	return outputLines
+}
+
 // SupervisorStartError wraps an error reported on the initialization of a child
 // node, enhancing it with supervisor information and possible termination errors
 // on other siblings
 type SupervisorStartError struct {
 	supRuntimeName string
+	nodeSep        string
 	nodeName       string
 	nodeErr        error
 	// terminationErr is non-nil when the abort process triggered by a supervisor
@@ -268,6 +442,12 @@ func (err *SupervisorStartError) Error() string {
 	return "supervisor node failed to start"
 }
 
+// Unwrap returns the start error of the child node; the termination errors of
+// its siblings are not unwrapped
+func (err *SupervisorStartError) Unwrap() error {
+	return err.nodeErr
+}
+
 // KVs returns a metadata map for structured logging
 func (err *SupervisorStartError) KVs() map[string]interface{} {
 	acc := make(map[string]interface{})
@@ -311,7 +491,7 @@ func (err *SupervisorStartError) explainLines() []string {
 		workerErrLines = append(
 			workerErrLines,
 			fmt.Sprintf("\tworker node '%s%s%s' failed to start",
-				err.supRuntimeName, NodeSepToken, err.nodeName),
+				err.supRuntimeName, nodeSeparator(err.nodeSep), err.nodeName),
 		)
 		workerErrLines = append(
 			workerErrLines,
@@ -363,6 +543,16 @@ func (err *SupervisorRestartError) Error() string {
 	return "supervisor crashed due to restart tolerance surpassed"
 }
 
+// Unwrap returns the RestartToleranceReached error of the child node that
+// crashed the supervisor; the termination errors of its siblings are not
+// unwrapped
+func (err *SupervisorRestartError) Unwrap() error {
+	if err.nodeErr == nil {
+		return nil
+	}
+	return err.nodeErr
+}
+
 // KVs returns a metadata map for structured logging
 func (err *SupervisorRestartError) KVs() map[string]interface{} {
 	acc := make(map[string]interface{})
@@ -388,11 +578,26 @@ func (err *SupervisorRestartError) KVs() map[string]interface{} {
 func (err *SupervisorRestartError) explainLines() []string {
 	var outputLines []string
 
+	crashReason := "restart tolerance surpassed"
+	if err.nodeErr.cause == panicsSurpassed {
+		crashReason = "repeated panics"
+	}
+	if err.nodeErr.cause == unexpectedCleanExit {
+		crashReason = "an unexpected child exit"
+	}
+	if err.nodeErr.cause == escalationPropagated {
+		crashReason = "a sub-tree escalation"
+	}
+	if err.nodeErr.cause == restartFailed {
+		crashReason = "a failed restart"
+	}
+
 	outputLines = append(
 		outputLines,
 		fmt.Sprintf(
-			"supervisor '%s' crashed due to restart tolerance surpassed.",
+			"supervisor '%s' crashed due to %s.",
 			err.supRuntimeName,
+			crashReason,
 		),
 	)
 
@@ -415,15 +620,43 @@ func (err *SupervisorRestartError) explainLines() []string {
 	return outputLines
 }
 
+// escalationCause indicates the reason why a supervisor gave up on restarting
+// a failing child
+type escalationCause uint32
+
+const (
+	// toleranceSurpassed indicates the child failed more times than the
+	// supervisor's restart tolerance allows
+	toleranceSurpassed escalationCause = iota
+	// panicsSurpassed indicates the child panicked as many times as its panic
+	// escalation setting allows
+	panicsSurpassed
+	// transientBudgetExhausted indicates the child failed more times than its
+	// transient budget allows
+	transientBudgetExhausted
+	// unexpectedCleanExit indicates a Transient child finished without an
+	// error, and its supervisor escalates it (see WithUnexpectedCleanExit)
+	unexpectedCleanExit
+	// escalationPropagated indicates a sub-tree gave up on one of its
+	// children, and its supervisor propagates it (see WithEscalationPolicy)
+	escalationPropagated
+	// restartFailed indicates the children of a supervisor could not be built
+	// or started again on a full restart (see Supervisor.Restart)
+	restartFailed
+)
+
 // RestartToleranceReached is an error that gets reported when a supervisor has
 // restarted a child so many times over a period of time that it does not make
 // sense to keep restarting.
 type RestartToleranceReached struct {
+	supRuntimeName         string
 	failedChildName        string
+	failedChildTag         c.ChildTag
 	failedChildErrCount    uint32
 	failedChildErrDuration time.Duration
 	sourceErr              error
 	lastErr                error
+	cause                  escalationCause
 }
 
 // NewRestartToleranceReached creates an ErrorToleranceReached record
@@ -442,10 +675,115 @@ func NewRestartToleranceReached(
 	}
 }
 
+// NewPanicEscalationReached creates an ErrorToleranceReached record for a
+// child that panicked as many times as its panic escalation setting allows
+func NewPanicEscalationReached(
+	sourceCh c.Child,
+	lastErr error,
+) *RestartToleranceReached {
+	return &RestartToleranceReached{
+		failedChildName:     sourceCh.GetRuntimeName(),
+		failedChildErrCount: sourceCh.GetPanicCount(),
+		sourceErr:           lastErr,
+		lastErr:             lastErr,
+		cause:               panicsSurpassed,
+	}
+}
+
+// NewTransientBudgetExhausted creates an ErrorToleranceReached record for a
+// Transient child that failed more times than its transient budget allows
+func NewTransientBudgetExhausted(
+	sourceCh c.Child,
+	lastErr error,
+) *RestartToleranceReached {
+	budget, window := sourceCh.GetSpec().GetTransientBudget()
+	return &RestartToleranceReached{
+		failedChildName:        sourceCh.GetRuntimeName(),
+		failedChildErrCount:    budget,
+		failedChildErrDuration: window,
+		sourceErr:              lastErr,
+		lastErr:                lastErr,
+		cause:                  transientBudgetExhausted,
+	}
+}
+
+// NewUnexpectedCleanExit creates an ErrorToleranceReached record for a
+// Transient child that finished without an error, when its supervisor escalates
+// unexpected clean exits
+func NewUnexpectedCleanExit(sourceCh c.Child) *RestartToleranceReached {
+	lastErr := c.WrapSentinel(
+		ErrUnexpectedCleanExit, nil,
+		"child '%s' exited unexpectedly", sourceCh.GetRuntimeName(),
+	)
+	return &RestartToleranceReached{
+		failedChildName: sourceCh.GetRuntimeName(),
+		sourceErr:       lastErr,
+		lastErr:         lastErr,
+		cause:           unexpectedCleanExit,
+	}
+}
+
+// NewEscalationPropagated creates an ErrorToleranceReached record for a
+// sub-tree that gave up on one of its children, when its supervisor propagates
+// the escalation (see WithEscalationPolicy)
+func NewEscalationPropagated(sourceCh c.Child, lastErr error) *RestartToleranceReached {
+	return &RestartToleranceReached{
+		failedChildName: sourceCh.GetRuntimeName(),
+		sourceErr:       lastErr,
+		lastErr:         lastErr,
+		cause:           escalationPropagated,
+	}
+}
+
+// NewSupervisorRestartFailed creates an ErrorToleranceReached record for a
+// supervisor that could not build or start its children again on a full
+// restart (see Supervisor.Restart). The given runtime name and spec are the
+// ones of the child that failed to start; when the children could not be built,
+// the runtime name is empty, and the supervisor is reported as the failed node.
+func NewSupervisorRestartFailed(
+	supRuntimeName string,
+	sourceChRuntimeName string,
+	sourceChSpec c.ChildSpec,
+	lastErr error,
+) *RestartToleranceReached {
+	failedChildName, failedChildTag := sourceChRuntimeName, sourceChSpec.GetTag()
+	if failedChildName == "" {
+		failedChildName, failedChildTag = supRuntimeName, c.Supervisor
+	}
+	return &RestartToleranceReached{
+		supRuntimeName:  supRuntimeName,
+		failedChildName: failedChildName,
+		failedChildTag:  failedChildTag,
+		sourceErr:       lastErr,
+		lastErr:         lastErr,
+		cause:           restartFailed,
+	}
+}
+
 // KVs returns a data bag map that may be used in structured logging
 func (err *RestartToleranceReached) KVs() map[string]interface{} {
 	kvs := make(map[string]interface{})
 	kvs["node.name"] = err.failedChildName
+	if err.cause == transientBudgetExhausted {
+		kvs["node.error.msg"] = err.lastErr.Error()
+		kvs["node.error.budget.count"] = err.failedChildErrCount
+		kvs["node.error.budget.duration"] = err.failedChildErrDuration
+		return kvs
+	}
+	if err.cause == panicsSurpassed {
+		kvs["node.error.msg"] = err.lastErr.Error()
+		kvs["node.error.panic.count"] = err.failedChildErrCount
+		return kvs
+	}
+	if err.cause == restartFailed {
+		kvs["supervisor.name"] = err.supRuntimeName
+		kvs["node.error.msg"] = err.lastErr.Error()
+		return kvs
+	}
+	if err.cause == unexpectedCleanExit || err.cause == escalationPropagated {
+		kvs["node.error.msg"] = err.lastErr.Error()
+		return kvs
+	}
 	if err.lastErr != nil {
 		kvs["node.error.source.msg"] = err.sourceErr.Error()
 		kvs["node.error.last.msg"] = err.lastErr.Error()
@@ -455,6 +793,36 @@ func (err *RestartToleranceReached) KVs() map[string]interface{} {
 	return kvs
 }
 
+// GetFailedChildName returns the runtime name of the child node whose failures
+// surpassed the restart tolerance
+func (err *RestartToleranceReached) GetFailedChildName() string {
+	return err.failedChildName
+}
+
+// GetRestartCount returns the number of restarts that were tolerated before
+// giving up on the child node; when the child panicked too many times, it is
+// the number of panics of the child instead (see WithPanicEscalation).
+func (err *RestartToleranceReached) GetRestartCount() uint32 {
+	return err.failedChildErrCount
+}
+
+// GetRestartWindow returns the time window in which the restarts were
+// accounted; it is zero when the child panicked too many times.
+func (err *RestartToleranceReached) GetRestartWindow() time.Duration {
+	return err.failedChildErrDuration
+}
+
+// GetSourceError returns the error that started the restart window in which
+// the tolerance was surpassed
+func (err *RestartToleranceReached) GetSourceError() error {
+	return err.sourceErr
+}
+
+// GetLastError returns the error that surpassed the restart tolerance
+func (err *RestartToleranceReached) GetLastError() error {
+	return err.lastErr
+}
+
 func (err *RestartToleranceReached) Error() string {
 	return "node failures surpassed restart tolerance"
 }
@@ -469,6 +837,75 @@ func (err *RestartToleranceReached) Unwrap() error {
 // of lines
 func (err *RestartToleranceReached) explainLines() []string {
 	var outputLines []string
+	if err.cause == transientBudgetExhausted {
+		outputLines = append(
+			outputLines,
+			fmt.Sprintf(
+				"worker node '%s' failed more than %d times in a %v window, it is not going to be restarted.",
+				err.failedChildName,
+				err.failedChildErrCount,
+				err.failedChildErrDuration,
+			),
+			"the last error reported was:",
+		)
+		return append(
+			outputLines,
+			indentExplain(1, errToExplain(err.lastErr))...,
+		)
+	}
+	if err.cause == panicsSurpassed {
+		outputLines = append(
+			outputLines,
+			fmt.Sprintf(
+				"worker node '%s' panicked %d times, restarting it is not going to help.",
+				err.failedChildName,
+				err.failedChildErrCount,
+			),
+			"the last error reported was:",
+		)
+		return append(
+			outputLines,
+			indentExplain(1, errToExplain(err.lastErr))...,
+		)
+	}
+	if err.cause == unexpectedCleanExit {
+		return append(
+			outputLines,
+			fmt.Sprintf(
+				"worker node '%s' finished without errors, but it was not expected to finish.",
+				err.failedChildName,
+			),
+		)
+	}
+	if err.cause == escalationPropagated {
+		outputLines = append(
+			outputLines,
+			fmt.Sprintf(
+				"supervisor node '%s' gave up on its children, the escalation was propagated.",
+				err.failedChildName,
+			),
+			"the error reported was:",
+		)
+		return append(
+			outputLines,
+			indentExplain(1, errToExplain(err.lastErr))...,
+		)
+	}
+	if err.cause == restartFailed {
+		reason := "the children could not be built again after a restart."
+		if err.failedChildName != err.supRuntimeName {
+			reason = fmt.Sprintf(
+				"%s node '%s' could not be started again after a restart.",
+				strings.ToLower(err.failedChildTag.String()),
+				err.failedChildName,
+			)
+		}
+		outputLines = append(outputLines, reason, "the error reported was:")
+		return append(
+			outputLines,
+			indentExplain(1, errToExplain(err.lastErr))...,
+		)
+	}
 	outputLines = append(
 		outputLines,
 		[]string{
@@ -0,0 +1,139 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+var errNodeBroken = errors.New("node is broken")
+
+func TestUnwrapNestedStartError(t *testing.T) {
+	failingWorker := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(_ context.Context, notifyStart cap.NotifyStartFn) error {
+			notifyStart(errNodeBroken)
+			return errNodeBroken
+		},
+	)
+
+	subtree2 := cap.NewSupervisorSpec("subtree2", cap.WithNodes(failingWorker))
+	subtree1 := cap.NewSupervisorSpec("subtree1", cap.WithNodes(cap.Subtree(subtree2)))
+
+	_, err := cap.NewSupervisorSpec(
+		"root", cap.WithNodes(cap.Subtree(subtree1)),
+	).Start(context.TODO())
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errNodeBroken))
+
+	var startErr *cap.SupervisorStartError
+	if assert.True(t, errors.As(err, &startErr)) {
+		// the first match is the error of the root supervisor
+		assert.Equal(t, "root", startErr.KVs()["supervisor.name"])
+	}
+
+	// every level of the tree is reachable
+	nestedErr := errors.Unwrap(err)
+	if assert.True(t, errors.As(nestedErr, &startErr)) {
+		assert.Equal(t, "root/subtree1", startErr.KVs()["supervisor.name"])
+	}
+	nestedErr = errors.Unwrap(nestedErr)
+	if assert.True(t, errors.As(nestedErr, &startErr)) {
+		assert.Equal(t, "root/subtree1/subtree2", startErr.KVs()["supervisor.name"])
+	}
+	assert.Equal(t, errNodeBroken, errors.Unwrap(nestedErr))
+}
+
+func TestUnwrapRestartError(t *testing.T) {
+	failingWorker := cap.NewWorker(
+		"child1",
+		func(context.Context) error {
+			return errNodeBroken
+		},
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(failingWorker),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// the second failure surpasses the default restart tolerance
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errNodeBroken))
+
+	var toleranceErr *cap.RestartToleranceReached
+	if assert.True(t, errors.As(err, &toleranceErr)) {
+		assert.Equal(t, "root/child1", toleranceErr.KVs()["node.name"])
+		assert.Equal(t, "root/child1", toleranceErr.GetFailedChildName())
+		// the default restart tolerance is 1 restart every 5 seconds
+		assert.Equal(t, uint32(1), toleranceErr.GetRestartCount())
+		assert.Equal(t, 5*time.Second, toleranceErr.GetRestartWindow())
+		assert.Equal(t, errNodeBroken, toleranceErr.GetSourceError())
+		assert.Equal(t, errNodeBroken, toleranceErr.GetLastError())
+	}
+}
+
+func TestUnwrapNestedTerminationError(t *testing.T) {
+	subtree1 := cap.NewSupervisorSpec(
+		"subtree1",
+		cap.WithNodes(WaitDoneWorker("child1"), NeverTerminateWorker("child2")),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child0"), cap.Subtree(subtree1)),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	// the timeout of a worker in a sub-tree is reachable from the root error
+	assert.True(t, errors.Is(err, cap.ErrShutdownTimeout))
+	assert.False(t, errors.Is(err, cap.ErrStartTimeout))
+
+	var terminationErr *cap.SupervisorTerminationError
+	if assert.True(t, errors.As(err, &terminationErr)) {
+		assert.Equal(t, "root", terminationErr.KVs()["supervisor.name"])
+	}
+}
+
+func TestUnwrapTerminationErrorList(t *testing.T) {
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			NeverTerminateWorker("child1"),
+			NeverTerminateWorker("child2"),
+		),
+		[]cap.Opt{
+			cap.WithCleanup(func() error { return errNodeBroken }),
+		},
+		func(EventManager) {},
+	)
+
+	var terminationErr *cap.SupervisorTerminationError
+	if assert.True(t, errors.As(err, &terminationErr)) {
+		errs := terminationErr.Unwrap()
+		// node errors are sorted by name, the cleanup error goes last
+		if assert.Len(t, errs, 3) {
+			assert.True(t, errors.Is(errs[0], cap.ErrShutdownTimeout))
+			assert.True(t, errors.Is(errs[1], cap.ErrShutdownTimeout))
+			assert.True(t, errors.Is(errs[2], errNodeBroken))
+		}
+	}
+}
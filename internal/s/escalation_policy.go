@@ -0,0 +1,61 @@
+package s
+
+// This file contains the handling of the sub-trees that give up on their
+// children (see WithEscalationPolicy)
+
+import (
+	"errors"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// EscalationPolicy specifies what a supervisor does when one of its sub-trees
+// fails with a SupervisorRestartError (see WithEscalationPolicy)
+type EscalationPolicy uint32
+
+const (
+	// RestartSubtree restarts the failed sub-tree as any other failing child,
+	// the failure is accounted on the restart tolerance of the supervisor
+	RestartSubtree EscalationPolicy = iota
+	// StopSubtree leaves the failed sub-tree stopped, as if it was a Temporary
+	// child
+	StopSubtree
+	// Propagate makes the supervisor give up and fail with a
+	// SupervisorRestartError, regardless of its restart tolerance
+	Propagate
+)
+
+func (p EscalationPolicy) String() string {
+	switch p {
+	case RestartSubtree:
+		return "RestartSubtree"
+	case StopSubtree:
+		return "StopSubtree"
+	case Propagate:
+		return "Propagate"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// reportSubtreeEscalation reports the escalation of the given sub-tree when
+// the supervisor has an escalation policy, and returns the policy the
+// supervisor must apply. The second result is false when the child is not a
+// sub-tree that gave up on its children, or when the supervisor doesn't have
+// an escalation policy.
+func (spec SupervisorSpec) reportSubtreeEscalation(
+	sourceCh c.Child,
+	sourceErr error,
+) (EscalationPolicy, bool) {
+	var restartErr *SupervisorRestartError
+	if spec.escalationPolicy == nil ||
+		sourceCh.IsWorker() ||
+		!errors.As(sourceErr, &restartErr) {
+		return RestartSubtree, false
+	}
+	policy := *spec.escalationPolicy
+	spec.getEventNotifier().withTags(sourceCh.GetSpec()).subtreeEscalated(
+		sourceCh.GetRuntimeName(), policy,
+	)
+	return policy, true
+}
@@ -0,0 +1,176 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// observeSubtreeEscalation runs a root supervisor with the given options, and
+// a sub-tree that gives up on its only child after it fails two times in a row
+func observeSubtreeEscalation(
+	t *testing.T,
+	opts []cap.Opt,
+	callback func(EventManager),
+) ([]cap.Event, error) {
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(child1))
+
+	return ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree), WaitDoneWorker("child2")),
+		opts,
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker1(false)
+			evIt.WaitTill(WorkerFailed("root/subtree/child1"))
+			evIt.WaitTill(WorkerStarted("root/subtree/child1"))
+			failWorker1(false)
+			evIt.WaitTill(SupervisorFailed("root/subtree"))
+			callback(em)
+			// unblock the worker if it was restarted
+			failWorker1(true)
+		},
+	)
+}
+
+func TestEscalationPolicyRestartSubtree(t *testing.T) {
+	events, err := observeSubtreeEscalation(
+		t,
+		[]cap.Opt{cap.WithEscalationPolicy(cap.RestartSubtree)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SubtreeEscalated("root/subtree"))
+			evIt.WaitTill(SupervisorStarted("root/subtree"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/subtree/child1"),
+			SupervisorStarted("root/subtree"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerFailed("root/subtree/child1"),
+			SupervisorFailed("root/subtree"),
+			SubtreeEscalated("root/subtree"),
+			WorkerStarted("root/subtree/child1"),
+			SupervisorStarted("root/subtree"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/subtree/child1"),
+			SupervisorTerminated("root/subtree"),
+			SupervisorTerminated("root"),
+		},
+	)
+
+	for _, ev := range events {
+		if ev.GetTag() == cap.SubtreeEscalated {
+			policy, ok := ev.GetEscalationPolicy()
+			assert.True(t, ok)
+			assert.Equal(t, cap.RestartSubtree, policy)
+		}
+	}
+}
+
+func TestEscalationPolicyStopSubtree(t *testing.T) {
+	events, err := observeSubtreeEscalation(
+		t,
+		[]cap.Opt{cap.WithEscalationPolicy(cap.StopSubtree)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SubtreeEscalated("root/subtree"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/subtree/child1"),
+			SupervisorStarted("root/subtree"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerFailed("root/subtree/child1"),
+			SupervisorFailed("root/subtree"),
+			SubtreeEscalated("root/subtree"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestEscalationPolicyPropagate(t *testing.T) {
+	events, err := observeSubtreeEscalation(
+		t,
+		[]cap.Opt{cap.WithEscalationPolicy(cap.Propagate)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(WorkerTerminated("root/child2"))
+		},
+	)
+
+	assert.Error(t, err)
+	var restartErr *cap.SupervisorRestartError
+	assert.True(t, errors.As(err, &restartErr))
+	assert.Equal(
+		t,
+		"supervisor 'root' crashed due to a sub-tree escalation.\n"+
+			"\tsupervisor node 'root/subtree' gave up on its children, the escalation was propagated.\n"+
+			"\tthe error reported was:\n"+
+			"\t\t> supervisor crashed due to restart tolerance surpassed",
+		cap.ExplainError(err),
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/subtree/child1"),
+			SupervisorStarted("root/subtree"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerFailed("root/subtree/child1"),
+			SupervisorFailed("root/subtree"),
+			SubtreeEscalated("root/subtree"),
+			WorkerTerminated("root/child2"),
+			SupervisorFailed("root"),
+		},
+	)
+}
+
+func TestEscalationPolicyNotSet(t *testing.T) {
+	events, err := observeSubtreeEscalation(
+		t,
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorFailed("root/subtree"))
+			// the sub-tree is restarted as any other child
+			evIt.WaitTill(SupervisorStarted("root/subtree"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	for _, ev := range events {
+		assert.NotEqual(t, cap.SubtreeEscalated, ev.GetTag())
+	}
+}
@@ -26,6 +26,79 @@ const (
 	ProcessFailed
 	// ProcessCompleted is an Event that indicates a process finished without errors
 	ProcessCompleted
+	// ProcessPaused is an Event that indicates a process was paused by its parent
+	// supervisor
+	ProcessPaused
+	// ProcessResumed is an Event that indicates a paused process was resumed by
+	// its parent supervisor
+	ProcessResumed
+	// ProcessUnhealthy is an Event that indicates the health probe of a
+	// process reported an error
+	ProcessUnhealthy
+	// ProcessRetired is an Event that indicates a process finished after its
+	// retirement time, and it is not going to be restarted
+	ProcessRetired
+	// DynChildrenDiscarded is an Event that indicates a supervisor restart
+	// terminated children that were spawned dynamically, and that are not going
+	// to be started again
+	DynChildrenDiscarded
+	// ChildRestartedManually is an Event that indicates a process was restarted
+	// on request of a client, rather than because of a failure
+	ChildRestartedManually
+	// ChildEnteredBackoff is an Event that indicates a process finished and it
+	// is waiting for the restart dampening window of its parent supervisor to
+	// be over before it gets restarted (see WithRestartDampening)
+	ChildEnteredBackoff
+	// ChildExitedBackoff is an Event that indicates a process is no longer
+	// waiting for the restart dampening window of its parent supervisor, either
+	// because the window is over, or because the supervisor is terminating
+	ChildExitedBackoff
+	// ChildRestartedPeriodically is an Event that indicates a process was
+	// restarted because its periodic restart interval expired, rather than
+	// because of a failure
+	ChildRestartedPeriodically
+	// ChildCircuitOpened is an Event that indicates a process failed too many
+	// times in a row, and it is not going to be restarted until the cooldown
+	// period of its circuit breaker is over (see WithCircuitBreaker)
+	ChildCircuitOpened
+	// ChildCircuitHalfOpened is an Event that indicates the cooldown period of
+	// the circuit breaker of a process is over, and the process is being
+	// started as a trial
+	ChildCircuitHalfOpened
+	// ChildCircuitClosed is an Event that indicates a process ran without
+	// failures on its trial, and it is restarted on failures again
+	ChildCircuitClosed
+	// ChildSwitchedToFallback is an Event that indicates a process exhausted
+	// the restart tolerance of its supervisor, and it is restarted with its
+	// fallback start function (see WithFallback)
+	ChildSwitchedToFallback
+	// ChildRestartRequested is an Event that indicates a process was restarted
+	// because it requested its own restart (see RequestRestart), rather than
+	// because of a failure
+	ChildRestartRequested
+	// GroupRestarted is an Event that indicates a supervisor with a OneForAll
+	// strategy restarted all its children because one of them finished (see
+	// WithGroupRestartEventsOnly)
+	GroupRestarted
+	// ChildExitedUnexpectedly is an Event that indicates a Transient process
+	// finished without an error, and its supervisor treats it as an anomaly
+	// (see WithUnexpectedCleanExit)
+	ChildExitedUnexpectedly
+	// ChildSwapStarted is an Event that indicates the new version of a child
+	// that is being swapped started, and it runs next to the old version (see
+	// Supervisor.SwapChild)
+	ChildSwapStarted
+	// ChildSwapCompleted is an Event that indicates the old version of a child
+	// that was being swapped got terminated, and the new version took its name
+	ChildSwapCompleted
+	// ChildAbandonedOnShutdown is an Event that indicates a process didn't
+	// finish before its shutdown timeout expired, and its goroutine was left
+	// running (see Supervisor.AbandonedChildren)
+	ChildAbandonedOnShutdown
+	// SubtreeEscalated is an Event that indicates a sub-tree gave up on one of
+	// its children, and its parent supervisor applied its escalation policy
+	// (see WithEscalationPolicy)
+	SubtreeEscalated
 )
 
 // String returns a string representation of the current EventTag
@@ -41,6 +114,46 @@ func (tag EventTag) String() string {
 		return "ProcessFailed"
 	case ProcessCompleted:
 		return "ProcessCompleted"
+	case ProcessPaused:
+		return "ProcessPaused"
+	case ProcessResumed:
+		return "ProcessResumed"
+	case ProcessUnhealthy:
+		return "ProcessUnhealthy"
+	case ProcessRetired:
+		return "ProcessRetired"
+	case DynChildrenDiscarded:
+		return "DynChildrenDiscarded"
+	case ChildRestartedManually:
+		return "ChildRestartedManually"
+	case ChildEnteredBackoff:
+		return "ChildEnteredBackoff"
+	case ChildExitedBackoff:
+		return "ChildExitedBackoff"
+	case ChildRestartedPeriodically:
+		return "ChildRestartedPeriodically"
+	case ChildCircuitOpened:
+		return "ChildCircuitOpened"
+	case ChildCircuitHalfOpened:
+		return "ChildCircuitHalfOpened"
+	case ChildCircuitClosed:
+		return "ChildCircuitClosed"
+	case ChildSwitchedToFallback:
+		return "ChildSwitchedToFallback"
+	case ChildRestartRequested:
+		return "ChildRestartRequested"
+	case GroupRestarted:
+		return "GroupRestarted"
+	case ChildExitedUnexpectedly:
+		return "ChildExitedUnexpectedly"
+	case ChildSwapStarted:
+		return "ChildSwapStarted"
+	case ChildSwapCompleted:
+		return "ChildSwapCompleted"
+	case ChildAbandonedOnShutdown:
+		return "ChildAbandonedOnShutdown"
+	case SubtreeEscalated:
+		return "SubtreeEscalated"
 	default:
 		return "<Unknown>"
 	}
@@ -54,8 +167,15 @@ type Event struct {
 	nodeTag            c.ChildTag
 	processRuntimeName string
 	err                error
+	reasonCode         ReasonCode
 	created            time.Time
 	duration           time.Duration
+	discardedChildren  []string
+	triggeringChild    string
+	restartedChildren  []string
+	tags               map[string]string
+	priorTermination   *c.TerminationReason
+	escalationPolicy   *EscalationPolicy
 }
 
 // GetTag returns the EventTag from an Event
@@ -78,11 +198,68 @@ func (e Event) Err() error {
 	return e.err
 }
 
+// ReasonCode returns a machine-readable code that specifies why this event was
+// reported; events that do not represent a failure return ReasonNone
+func (e Event) ReasonCode() ReasonCode {
+	return e.reasonCode
+}
+
 // GetCreated returns a timestamp of the creation of the event by the process
 func (e Event) GetCreated() time.Time {
 	return e.created
 }
 
+// GetTags returns a copy of the tags of the child that emitted this event
+// (see c.WithTags); it is nil when the child has no tags
+func (e Event) GetTags() map[string]string {
+	if len(e.tags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(e.tags))
+	for k, v := range e.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// GetDiscardedChildren returns the runtime names of the children that were
+// discarded on a DynChildrenDiscarded event
+func (e Event) GetDiscardedChildren() []string {
+	return e.discardedChildren
+}
+
+// GetTriggeringChild returns the runtime name of the child whose termination
+// caused the restart of its siblings on a GroupRestarted event
+func (e Event) GetTriggeringChild() string {
+	return e.triggeringChild
+}
+
+// GetRestartedChildren returns the runtime names of the siblings that were
+// restarted together with the triggering child on a GroupRestarted event
+func (e Event) GetRestartedChildren() []string {
+	return e.restartedChildren
+}
+
+// GetPriorTermination returns why a restarted worker stopped running before
+// the restart on a ProcessStarted event (e.g. a Permanent worker that exited
+// cleanly, or one that crashed); the second value is false when the event is
+// not the restart of a worker.
+func (e Event) GetPriorTermination() (c.TerminationReason, bool) {
+	if e.priorTermination == nil {
+		return c.TerminationCleanExit, false
+	}
+	return *e.priorTermination, true
+}
+
+// GetEscalationPolicy returns the escalation policy a supervisor applied on a
+// SubtreeEscalated event; the second value is false for other events.
+func (e Event) GetEscalationPolicy() (EscalationPolicy, bool) {
+	if e.escalationPolicy == nil {
+		return RestartSubtree, false
+	}
+	return *e.escalationPolicy, true
+}
+
 // String returns an string representation for the Event
 func (e Event) String() string {
 	var buffer strings.Builder
@@ -92,8 +269,22 @@ func (e Event) String() string {
 	buffer.WriteString(fmt.Sprintf(", nodeTag: %10s", e.nodeTag))
 	buffer.WriteString(fmt.Sprintf(", processRuntime: %s", e.processRuntimeName))
 	if e.err != nil {
+		buffer.WriteString(fmt.Sprintf(", reasonCode: %s", e.reasonCode))
 		buffer.WriteString(fmt.Sprintf(", err: %+v", e.err))
 	}
+	if len(e.discardedChildren) > 0 {
+		buffer.WriteString(fmt.Sprintf(", discardedChildren: %v", e.discardedChildren))
+	}
+	if e.triggeringChild != "" {
+		buffer.WriteString(fmt.Sprintf(", triggeringChild: %s", e.triggeringChild))
+		buffer.WriteString(fmt.Sprintf(", restartedChildren: %v", e.restartedChildren))
+	}
+	if e.priorTermination != nil {
+		buffer.WriteString(fmt.Sprintf(", priorTermination: %s", *e.priorTermination))
+	}
+	if e.escalationPolicy != nil {
+		buffer.WriteString(fmt.Sprintf(", escalationPolicy: %s", *e.escalationPolicy))
+	}
 	buffer.WriteString("}")
 	return buffer.String()
 }
@@ -104,27 +295,38 @@ func (e Event) String() string {
 // Check the documentation of WithNotifier for more details.
 type EventNotifier func(Event)
 
-// processTerminated reports an event with an EventTag of ProcessTerminated
+// withTags returns an EventNotifier that attaches the tags of the given child
+// spec to the reported events
+func (en EventNotifier) withTags(chSpec c.ChildSpec) EventNotifier {
+	tags := chSpec.GetTags()
+	if len(tags) == 0 {
+		return en
+	}
+	return func(ev Event) {
+		ev.tags = tags
+		en(ev)
+	}
+}
+
+// processTerminated reports an event with an EventTag of ProcessTerminated; the
+// stop duration is measured by the caller with the supervisor Clock
 func (en EventNotifier) processTerminated(
 	nodeTag c.ChildTag,
 	name string,
-	stopTime time.Time,
+	stopDuration time.Duration,
 ) {
-	createdTime := time.Now()
-	stopDuration := createdTime.Sub(stopTime)
-
 	en(Event{
 		tag:                ProcessTerminated,
 		nodeTag:            nodeTag,
 		processRuntimeName: name,
-		created:            createdTime,
+		created:            time.Now(),
 		duration:           stopDuration,
 	})
 }
 
 // supervisorTerminated reports an event with an EventTag of ProcessTerminated
-func (en EventNotifier) supervisorTerminated(name string, stopTime time.Time) {
-	en.processTerminated(c.Supervisor, name, stopTime)
+func (en EventNotifier) supervisorTerminated(name string, stopDuration time.Duration) {
+	en.processTerminated(c.Supervisor, name, stopDuration)
 }
 
 // workerCompleted reports an event with an EventTag of ProcessCompleted
@@ -137,29 +339,249 @@ func (en EventNotifier) workerCompleted(name string) {
 	})
 }
 
+// workerPaused reports an event with an EventTag of ProcessPaused
+func (en EventNotifier) workerPaused(name string) {
+	en(Event{
+		tag:                ProcessPaused,
+		nodeTag:            c.Worker,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// workerResumed reports an event with an EventTag of ProcessResumed
+func (en EventNotifier) workerResumed(name string) {
+	en(Event{
+		tag:                ProcessResumed,
+		nodeTag:            c.Worker,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// processRetired reports an event with an EventTag of ProcessRetired
+func (en EventNotifier) processRetired(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ProcessRetired,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// dynChildrenDiscarded reports an event with an EventTag of
+// DynChildrenDiscarded
+func (en EventNotifier) dynChildrenDiscarded(name string, discarded []string) {
+	en(Event{
+		tag:                DynChildrenDiscarded,
+		nodeTag:            c.Supervisor,
+		processRuntimeName: name,
+		created:            time.Now(),
+		discardedChildren:  discarded,
+	})
+}
+
+// groupRestarted reports an event with an EventTag of GroupRestarted
+func (en EventNotifier) groupRestarted(name string, triggering string, restarted []string) {
+	en(Event{
+		tag:                GroupRestarted,
+		nodeTag:            c.Supervisor,
+		processRuntimeName: name,
+		created:            time.Now(),
+		triggeringChild:    triggering,
+		restartedChildren:  restarted,
+	})
+}
+
+// childRestartedManually reports an event with an EventTag of
+// ChildRestartedManually
+func (en EventNotifier) childRestartedManually(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildRestartedManually,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childSwapStarted reports an event with an EventTag of ChildSwapStarted
+func (en EventNotifier) childSwapStarted(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildSwapStarted,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childSwapCompleted reports an event with an EventTag of ChildSwapCompleted
+func (en EventNotifier) childSwapCompleted(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildSwapCompleted,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childAbandonedOnShutdown reports an event with an EventTag of
+// ChildAbandonedOnShutdown
+func (en EventNotifier) childAbandonedOnShutdown(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildAbandonedOnShutdown,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childRestartedPeriodically reports an event with an EventTag of
+// ChildRestartedPeriodically
+func (en EventNotifier) childRestartedPeriodically(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildRestartedPeriodically,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childRestartRequested reports an event with an EventTag of
+// ChildRestartRequested
+func (en EventNotifier) childRestartRequested(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildRestartRequested,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childExitedUnexpectedly reports an event with an EventTag of
+// ChildExitedUnexpectedly
+func (en EventNotifier) childExitedUnexpectedly(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildExitedUnexpectedly,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// subtreeEscalated reports an event with an EventTag of SubtreeEscalated
+func (en EventNotifier) subtreeEscalated(name string, policy EscalationPolicy) {
+	en(Event{
+		tag:                SubtreeEscalated,
+		nodeTag:            c.Supervisor,
+		processRuntimeName: name,
+		created:            time.Now(),
+		escalationPolicy:   &policy,
+	})
+}
+
+// childEnteredBackoff reports an event with an EventTag of
+// ChildEnteredBackoff
+func (en EventNotifier) childEnteredBackoff(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildEnteredBackoff,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childExitedBackoff reports an event with an EventTag of ChildExitedBackoff
+func (en EventNotifier) childExitedBackoff(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildExitedBackoff,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childCircuitOpened reports an event with an EventTag of ChildCircuitOpened
+func (en EventNotifier) childCircuitOpened(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildCircuitOpened,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childCircuitHalfOpened reports an event with an EventTag of ChildCircuitHalfOpened
+func (en EventNotifier) childCircuitHalfOpened(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildCircuitHalfOpened,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childCircuitClosed reports an event with an EventTag of ChildCircuitClosed
+func (en EventNotifier) childCircuitClosed(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildCircuitClosed,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
+// childSwitchedToFallback reports an event with an EventTag of
+// ChildSwitchedToFallback
+func (en EventNotifier) childSwitchedToFallback(nodeTag c.ChildTag, name string) {
+	en(Event{
+		tag:                ChildSwitchedToFallback,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		created:            time.Now(),
+	})
+}
+
 // processFailed reports an event with an EventTag of ProcessFailed
 func (en EventNotifier) processFailed(
 	nodeTag c.ChildTag,
 	name string,
 	err error,
+	reasonCode ReasonCode,
 ) {
 	en(Event{
 		tag:                ProcessFailed,
 		nodeTag:            nodeTag,
 		processRuntimeName: name,
 		err:                err,
+		reasonCode:         reasonCode,
 		created:            time.Now(),
 	})
 }
 
 // supervisorFailed reports a supervisor event with an EventTag of ProcessFailed
 func (en EventNotifier) supervisorFailed(name string, err error) {
-	en.processFailed(c.Supervisor, name, err)
+	en.processFailed(c.Supervisor, name, err, failureReasonCode(err))
 }
 
 // workerFailed reports a worker event with an EventTag of ProcessFailed
 func (en EventNotifier) workerFailed(name string, err error) {
-	en.processFailed(c.Worker, name, err)
+	en.processFailed(c.Worker, name, err, failureReasonCode(err))
+}
+
+// processUnhealthy reports an event with an EventTag of ProcessUnhealthy
+func (en EventNotifier) processUnhealthy(
+	nodeTag c.ChildTag,
+	name string,
+	err error,
+) {
+	en(Event{
+		tag:                ProcessUnhealthy,
+		nodeTag:            nodeTag,
+		processRuntimeName: name,
+		err:                err,
+		reasonCode:         ReasonHealthCheckFailed,
+		created:            time.Now(),
+	})
 }
 
 // workerFailed reports an event with an EventTag of ProcessFailed
@@ -178,6 +600,7 @@ func (en EventNotifier) processStartFailed(
 		nodeTag:            nodeTag,
 		processRuntimeName: name,
 		err:                err,
+		reasonCode:         startFailureReasonCode(err),
 	})
 }
 
@@ -191,27 +614,49 @@ func (en EventNotifier) supervisorStartFailed(name string, err error) {
 //	en.processStartFailed(c.Worker, name, err)
 // }
 
-func processStarted(en EventNotifier, nodeTag c.ChildTag, name string, startTime time.Time) {
-	createdTime := time.Now()
-	startDuration := createdTime.Sub(startTime)
+// processStarted reports an event with an EventTag of ProcessStarted; the start
+// duration is measured by the caller with the supervisor Clock
+func processStarted(
+	en EventNotifier,
+	nodeTag c.ChildTag,
+	name string,
+	startDuration time.Duration,
+) {
 	en(Event{
 		tag:                ProcessStarted,
 		nodeTag:            nodeTag,
 		processRuntimeName: name,
 		err:                nil,
-		created:            createdTime,
+		created:            time.Now(),
 		duration:           startDuration,
 	})
 }
 
 // supervisorStarted reports an event with an EventTag of ProcessStarted
-func (en EventNotifier) supervisorStarted(name string, startTime time.Time) {
-	processStarted(en, c.Supervisor, name, startTime)
+func (en EventNotifier) supervisorStarted(name string, startDuration time.Duration) {
+	processStarted(en, c.Supervisor, name, startDuration)
 }
 
 // workerStarted reports an event with an EventTag of ProcessStarted
-func (en EventNotifier) workerStarted(name string, startTime time.Time) {
-	processStarted(en, c.Worker, name, startTime)
+func (en EventNotifier) workerStarted(name string, startDuration time.Duration) {
+	processStarted(en, c.Worker, name, startDuration)
+}
+
+// workerRestarted reports a worker event with an EventTag of ProcessStarted,
+// which includes the reason the worker stopped before it got restarted
+func (en EventNotifier) workerRestarted(
+	name string,
+	startDuration time.Duration,
+	priorTermination c.TerminationReason,
+) {
+	en(Event{
+		tag:                ProcessStarted,
+		nodeTag:            c.Worker,
+		processRuntimeName: name,
+		created:            time.Now(),
+		duration:           startDuration,
+		priorTermination:   &priorTermination,
+	})
 }
 
 // emptyEventNotifier is an utility function that works as a default value
@@ -0,0 +1,73 @@
+package s
+
+// This file contains the implementation of the WithEventHistory option
+
+import "sync"
+
+// eventHistory is a ring buffer that retains the most recent events of a
+// supervision tree. A single value is shared by the root supervisor and all its
+// sub-trees.
+type eventHistory struct {
+	mu     sync.Mutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// newEventHistory creates the eventHistory of a supervision tree with the
+// settings of the given root supervisor spec; it returns nil when the history
+// is disabled
+func newEventHistory(spec SupervisorSpec) *eventHistory {
+	if spec.eventHistory <= 0 {
+		return nil
+	}
+	return &eventHistory{events: make([]Event, spec.eventHistory)}
+}
+
+// record returns an EventNotifier that retains every event in the history
+// before it is given to the given EventNotifier
+func (eh *eventHistory) record(en EventNotifier) EventNotifier {
+	return func(ev Event) {
+		eh.mu.Lock()
+		eh.events[eh.next] = ev
+		eh.next = (eh.next + 1) % len(eh.events)
+		if eh.next == 0 {
+			eh.full = true
+		}
+		eh.mu.Unlock()
+		en(ev)
+	}
+}
+
+// replay returns up to n of the most recent events, oldest first
+func (eh *eventHistory) replay(n int) []Event {
+	eh.mu.Lock()
+	defer eh.mu.Unlock()
+
+	size := eh.next
+	if eh.full {
+		size = len(eh.events)
+	}
+	if n > size {
+		n = size
+	}
+
+	result := make([]Event, 0, n)
+	start := eh.next - n
+	for i := 0; i < n; i++ {
+		idx := (start + i + len(eh.events)) % len(eh.events)
+		result = append(result, eh.events[idx])
+	}
+	return result
+}
+
+// ReplayEvents returns up to n of the most recent events of this supervision
+// tree (including the events of its sub-trees), oldest first. It returns nil
+// when the supervisor was not created with the WithEventHistory option, or when
+// n is not positive.
+func (sup Supervisor) ReplayEvents(n int) []Event {
+	if sup.eventHistory == nil || n <= 0 {
+		return nil
+	}
+	return sup.eventHistory.replay(n)
+}
@@ -0,0 +1,83 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestReplayEventsDisabledByDefault(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Nil(t, sup.ReplayEvents(10))
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestReplayEventsStartup(t *testing.T) {
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(WaitDoneWorker("child2")))
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), cap.Subtree(subtree)),
+		cap.WithEventHistory(10),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// events of the sub-trees are retained as well
+	AssertExactMatch(t, sup.ReplayEvents(10),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorStarted("root/subtree"),
+			SupervisorStarted("root"),
+		},
+	)
+
+	// only the most recent events are returned
+	AssertExactMatch(t, sup.ReplayEvents(2),
+		[]EventP{
+			SupervisorStarted("root/subtree"),
+			SupervisorStarted("root"),
+		},
+	)
+
+	assert.Nil(t, sup.ReplayEvents(0))
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestReplayEventsBounded(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child2"),
+			WaitDoneWorker("child3"),
+		),
+		cap.WithEventHistory(2),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// older events are discarded once the history is full
+	AssertExactMatch(t, sup.ReplayEvents(10),
+		[]EventP{
+			WorkerStarted("root/child3"),
+			SupervisorStarted("root"),
+		},
+	)
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestWithEventHistoryNegativeSize(t *testing.T) {
+	assert.Panics(t, func() {
+		cap.WithEventHistory(-1)
+	})
+}
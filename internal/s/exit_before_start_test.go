@@ -0,0 +1,142 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// exitBeforeStartWorker creates a worker that returns the given error without
+// notifying its start
+func exitBeforeStartWorker(name string, err error) cap.Node {
+	return cap.NewWorkerWithNotifyStart(
+		name,
+		func(context.Context, cap.NotifyStartFn) error {
+			return err
+		},
+	)
+}
+
+func TestExitBeforeStart(t *testing.T) {
+	t.Run("with an error", func(t *testing.T) {
+		exitErr := errors.New("could not connect")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(
+				WaitDoneWorker("child0"),
+				exitBeforeStartWorker("child1", exitErr),
+				WaitDoneWorker("child2"),
+			),
+			[]cap.Opt{},
+			func(EventManager) {},
+		)
+
+		assert.Error(t, err)
+		var startErr *cap.SupervisorStartError
+		assert.True(t, errors.As(err, &startErr))
+		assert.True(t, errors.Is(err, exitErr))
+
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child0"),
+				WorkerStartFailed("root/child1"),
+				// the previously started siblings are terminated
+				WorkerTerminated("root/child0"),
+				SupervisorStartFailed("root"),
+			},
+		)
+	})
+
+	t.Run("without an error", func(t *testing.T) {
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(
+				WaitDoneWorker("child0"),
+				exitBeforeStartWorker("child1", nil),
+			),
+			[]cap.Opt{},
+			func(EventManager) {},
+		)
+
+		assert.Error(t, err)
+		var startErr *cap.SupervisorStartError
+		assert.True(t, errors.As(err, &startErr))
+		assert.True(t, errors.Is(err, cap.ErrExitedBeforeStart))
+
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child0"),
+				WorkerStartFailed("root/child1"),
+				WorkerTerminated("root/child0"),
+				SupervisorStartFailed("root"),
+			},
+		)
+	})
+}
+
+func TestExitBeforeStartOnRestart(t *testing.T) {
+	exitErr := errors.New("could not reconnect")
+
+	// the worker notifies its start on its first run, and it returns before
+	// notifying its start on its restarts
+	var runs int32
+	child1 := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				notifyStart(nil)
+				return errors.New("connection lost")
+			}
+			return exitErr
+		},
+	)
+
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child0"), child1),
+		cap.WithRestartTolerance(2, time.Minute),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	err = sup.Wait()
+
+	// the failed restarts do not block the supervisor, which gives up once its
+	// restart tolerance is surpassed
+	assert.Error(t, err)
+	var toleranceErr *cap.RestartToleranceReached
+	assert.True(t, errors.As(err, &toleranceErr))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorFailed("root"))
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child0"),
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStartFailed("root/child1"),
+			WorkerStartFailed("root/child1"),
+			WorkerTerminated("root/child0"),
+			SupervisorFailed("root"),
+		},
+	)
+}
@@ -0,0 +1,158 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestFallbackAfterRestartToleranceExhausted(t *testing.T) {
+	var fallbackStarts int32
+
+	child1 := cap.NewWorker(
+		"child1",
+		func(context.Context) error {
+			return errors.New("child1 is broken")
+		},
+		// the fallback is Permanent, even when the primary is not
+		cap.WithRestart(cap.Transient),
+		cap.WithFallback(func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			atomic.AddInt32(&fallbackStarts, 1)
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		}),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// the second failure surpasses the default restart tolerance
+			evIt.WaitTill(WorkerSwitchedToFallback("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fallbackStarts))
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerSwitchedToFallback("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestFallbackExhaustsRestartTolerance(t *testing.T) {
+	child1 := cap.NewWorker(
+		"child1",
+		func(context.Context) error {
+			return errors.New("child1 is broken")
+		},
+		cap.WithFallback(func(_ context.Context, notifyStart cap.NotifyStartFn) error {
+			notifyStart(nil)
+			return errors.New("fallback is broken")
+		}),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// the second failure of the fallback surpasses the restart
+			// tolerance again
+			evIt.WaitTill(WorkerFailedWith("root/child1", "fallback is broken"))
+			evIt.WaitTill(WorkerFailedWith("root/child1", "fallback is broken"))
+		},
+	)
+
+	assert.Error(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerSwitchedToFallback("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailedWith("root/child1", "fallback is broken"),
+			WorkerStarted("root/child1"),
+			WorkerFailedWith("root/child1", "fallback is broken"),
+			SupervisorFailed("root"),
+		},
+	)
+}
+
+func TestFallbackFailsFirstStart(t *testing.T) {
+	var primaryStarts, fallbackStarts int32
+
+	child1 := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			atomic.AddInt32(&primaryStarts, 1)
+			notifyStart(nil)
+			return errors.New("child1 is broken")
+		},
+		cap.WithFallback(func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			// the first start of the fallback fails, the retry must use the
+			// fallback again
+			if atomic.AddInt32(&fallbackStarts, 1) == 1 {
+				err := errors.New("fallback start failure")
+				notifyStart(err)
+				return err
+			}
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		}),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{cap.WithRestartTolerance(2, time.Minute)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(WorkerSwitchedToFallback("root/child1"))
+			evIt.WaitTill(WorkerStartFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fallbackStarts))
+	// the primary start function is not used after the switch
+	assert.Equal(t, int32(3), atomic.LoadInt32(&primaryStarts))
+}
@@ -0,0 +1,105 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestGroupRestartedEvent(t *testing.T) {
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), child2, WaitDoneWorker("child3")),
+		[]cap.Opt{cap.WithStrategy(cap.OneForAll)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker2(true /* done */)
+			evIt.WaitTill(SupervisorGroupRestarted("root", "root/child2"))
+		},
+	)
+	assert.NoError(t, err)
+
+	var groupEv cap.Event
+	for _, ev := range events {
+		if ev.GetTag() == cap.GroupRestarted {
+			groupEv = ev
+		}
+	}
+	assert.Equal(t, "root/child2", groupEv.GetTriggeringChild())
+	assert.Equal(t, []string{"root/child1", "root/child3"}, groupEv.GetRestartedChildren())
+}
+
+func TestGroupRestartsReport(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+
+	groupCh := make(chan struct{}, 1)
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.GroupRestarted {
+			groupCh <- struct{}{}
+		}
+	}
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2"), WaitDoneWorker("child3")),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	failWorker1(true /* done */)
+	<-groupCh
+
+	report := sup.RestartAmplification()
+	// a single group restart, that restarted every child
+	assert.Equal(t, uint64(1), report.GetGroupRestarts())
+	assert.Equal(t, uint64(3), report.GetRestarts())
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestGroupRestartEventsOnly(t *testing.T) {
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), child2, WaitDoneWorker("child3")),
+		[]cap.Opt{
+			cap.WithStrategy(cap.OneForAll),
+			cap.WithGroupRestartEventsOnly(),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker2(true /* done */)
+			evIt.WaitTill(SupervisorGroupRestarted("root", "root/child2"))
+		},
+	)
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child3"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child2"),
+			// the termination and start of the siblings is not reported
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child2"),
+			WorkerTerminated("root/child3"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,86 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestHealDuration(t *testing.T) {
+	t.Run("restarts of a stable child are forgiven", func(t *testing.T) {
+		child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1),
+			[]cap.Opt{
+				cap.WithRestartTolerance(1, time.Minute),
+				cap.WithHealDuration(20 * time.Millisecond),
+			},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				failWorker1(false /* done */)
+				evIt.WaitTill(WorkerStarted("root/child1"))
+				// the child is stable for longer than the heal duration
+				time.Sleep(50 * time.Millisecond)
+				failWorker1(true /* done */)
+				evIt.WaitTill(WorkerStarted("root/child1"))
+			},
+		)
+
+		assert.NoError(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+
+	t.Run("restarts of an unstable child are accounted", func(t *testing.T) {
+		child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1),
+			[]cap.Opt{
+				cap.WithRestartTolerance(1, time.Minute),
+				cap.WithHealDuration(time.Minute),
+			},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				failWorker1(false /* done */)
+				evIt.WaitTill(WorkerStarted("root/child1"))
+				failWorker1(true /* done */)
+				evIt.WaitTill(WorkerFailed("root/child1"))
+			},
+		)
+
+		assert.Error(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerFailed("root/child1"),
+				SupervisorFailed("root"),
+			},
+		)
+	})
+}
@@ -0,0 +1,268 @@
+package s
+
+// This file contains the implementation of the health probes API
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// runningChild contains the information of a running child of a supervisor
+type runningChild struct {
+	runtimeName string
+	spec        c.ChildSpec
+	// lazyPending is true when the child is waiting for its lazy start
+	// trigger, and it is not running yet
+	lazyPending bool
+}
+
+// listChildrenMsg is a message sent from clients to get the children that are
+// running on a supervisor.
+type listChildrenMsg struct {
+	resultChan chan<- []runningChild
+}
+
+func (lcm listChildrenMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	children := make([]runningChild, 0, len(supChildren))
+	for _, chSpec := range spec.order.sortStart(specChildren) {
+		ch, ok := supChildren[chSpec.GetName()]
+		if !ok {
+			// child is not running (e.g. it completed and it is not restarted)
+			continue
+		}
+		children = append(
+			children,
+			runningChild{
+				runtimeName: ch.GetRuntimeName(),
+				spec:        ch.GetSpec(),
+				lazyPending: ch.IsLazyPending(),
+			},
+		)
+	}
+
+	// do not block waiting for a read
+	select {
+	case lcm.resultChan <- children:
+	default:
+	}
+
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = listChildrenMsg{}
+
+// sendCtrlMsg delivers the given message to a supervisor, it fails if the
+// supervisor is not running anymore or if the given context is done before the
+// supervisor is able to read the message.
+func sendCtrlMsg(ctx context.Context, ctrlChan chan ctrlMsg, msg ctrlMsg) (err error) {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	defer func() {
+		// sending a message to a terminated root supervisor panics given its
+		// ctrlChan is closed
+		if panicVal := recover(); panicVal != nil {
+			err = c.WrapSentinel(
+				ErrSupervisorUnreachable, ErrSupervisorTerminated,
+				"could not talk to supervisor: supervisor terminated",
+			)
+		}
+	}()
+	select {
+	case ctrlChan <- msg:
+		return nil
+	case <-ctx.Done():
+		return c.WrapSentinel(
+			ErrSupervisorUnreachable, ctx.Err(),
+			"could not talk to supervisor: %v", ctx.Err(),
+		)
+	}
+}
+
+// waitCtrlResult waits for the result of a control message the supervisor
+// accepted already. Once the supervisor received the message, it always reports
+// a result, unless its goroutine is gone before it gets to process it.
+func (sup Supervisor) waitCtrlResult(resultChan <-chan error) error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	select {
+	case err := <-resultChan:
+		return err
+	case <-sup.doneCh:
+		// the result may have been reported right before the supervisor
+		// terminated
+		select {
+		case err := <-resultChan:
+			return err
+		default:
+		}
+		return c.WrapSentinel(
+			ErrSupervisorUnreachable, ErrSupervisorTerminated,
+			"could not get a result from supervisor: supervisor terminated",
+		)
+	}
+}
+
+// listChildren returns the children of the supervisor that listens to the
+// given ctrlChan, without the children of its sub-trees.
+func listChildren(ctx context.Context, ctrlChan chan ctrlMsg) ([]runningChild, error) {
+	// we initialize the resultChan with a buffer of 1, we may store the result
+	// before the client is ready to read it.
+	resultChan := make(chan []runningChild, 1)
+	err := sendCtrlMsg(ctx, ctrlChan, listChildrenMsg{resultChan: resultChan})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case children := <-resultChan:
+		return children, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("could not get children from supervisor: %w", ctx.Err())
+	}
+}
+
+// childTree contains a child of a supervisor, and the children of its sub-tree
+// when the child is a supervisor.
+type childTree struct {
+	runningChild
+	children []childTree
+	// subtreeErr is not nil when the sub-tree didn't report its children (e.g.
+	// it is restarting)
+	subtreeErr error
+}
+
+// walkChildren returns the children of the supervisor that listens to the
+// given ctrlChan, and the children of its sub-trees at any depth. Each
+// supervisor reports its children from its own monitor loop, so a supervisor
+// level never reports a partial restart. The sub-trees waiting for their lazy
+// start trigger are not walked.
+func walkChildren(ctx context.Context, ctrlChan chan ctrlMsg) ([]childTree, error) {
+	children, err := listChildren(ctx, ctrlChan)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make([]childTree, 0, len(children))
+	for _, ch := range children {
+		tree := childTree{runningChild: ch}
+		if subtreeCtrlChan, ok := ch.spec.GetSubtreeCtrl().(chan ctrlMsg); ok && !ch.lazyPending {
+			tree.children, tree.subtreeErr = walkChildren(ctx, subtreeCtrlChan)
+		}
+		trees = append(trees, tree)
+	}
+
+	return trees, nil
+}
+
+// listRunningChildren returns the children running on the supervisor that
+// listens to the given ctrlChan, and all the children of its sub-trees.
+func listRunningChildren(ctx context.Context, ctrlChan chan ctrlMsg) ([]runningChild, error) {
+	trees, err := walkChildren(ctx, ctrlChan)
+	if err != nil {
+		return nil, err
+	}
+	return flattenRunningChildren(trees), nil
+}
+
+// flattenRunningChildren returns the running children of the given trees, each
+// one followed by the children of its sub-tree
+func flattenRunningChildren(trees []childTree) []runningChild {
+	result := make([]runningChild, 0, len(trees))
+	for _, tree := range trees {
+		if tree.lazyPending {
+			// the child is not running, there is nothing to probe
+			continue
+		}
+		ch := tree.runningChild
+		if tree.subtreeErr != nil {
+			// the sub-tree is not reachable (e.g. it is restarting), the
+			// sub-tree node is going to be reported as unhealthy
+			subtreeErr := tree.subtreeErr
+			ch.spec = ch.spec.AttachHealthCheck(
+				func(context.Context) error { return subtreeErr },
+			)
+		}
+		result = append(result, ch)
+		result = append(result, flattenRunningChildren(tree.children)...)
+	}
+	return result
+}
+
+// HealthCheck invokes the health probe of every running node in the
+// supervision tree, and returns the results keyed by each node's runtime name.
+// Nodes without a probe (see WithHealthCheck) are reported as healthy (nil
+// error). Every failing probe is also reported with a ProcessUnhealthy event.
+//
+// The probes run concurrently, and they receive the given context; when the
+// context is done before a probe returns, the context error is reported for
+// that node.
+func (sup Supervisor) HealthCheck(ctx context.Context) map[string]error {
+	children, err := listRunningChildren(ctx, sup.ctrlCh)
+	if err != nil {
+		return map[string]error{sup.runtimeName: err}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error, len(children))
+
+	for _, ch := range children {
+		probe := ch.spec.GetHealthCheck()
+		if probe == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(runtimeName string, probe func(context.Context) error) {
+			defer wg.Done()
+			probeErr := probe(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			results[runtimeName] = probeErr
+		}(ch.runtimeName, probe)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	eventNotifier := sup.spec.getEventNotifier()
+	report := make(map[string]error, len(children))
+	for _, ch := range children {
+		if ch.spec.GetHealthCheck() == nil {
+			// nodes without a probe are healthy
+			report[ch.runtimeName] = nil
+			continue
+		}
+		probeErr, ok := results[ch.runtimeName]
+		if !ok {
+			// the probe did not finish before the context was done
+			probeErr = ctx.Err()
+		}
+		if probeErr != nil {
+			eventNotifier.withTags(ch.spec).processUnhealthy(ch.spec.GetTag(), ch.runtimeName, probeErr)
+		}
+		report[ch.runtimeName] = probeErr
+	}
+	return report
+}
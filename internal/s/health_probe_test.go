@@ -0,0 +1,112 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestHealthCheckProbes(t *testing.T) {
+	probeErr := errors.New("database is unreachable")
+
+	child0 := WaitDoneWorker("child0")
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithHealthCheck(func(context.Context) error { return probeErr }),
+	)
+	child2 := cap.NewWorker(
+		"child2",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithHealthCheck(func(context.Context) error { return nil }),
+	)
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(child2))
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child0, child1, cap.Subtree(subtree)),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	report := sup.HealthCheck(context.TODO())
+
+	assert.Equal(
+		t,
+		map[string]error{
+			"root/child0":         nil,
+			"root/child1":         probeErr,
+			"root/subtree":        nil,
+			"root/subtree/child2": nil,
+		},
+		report,
+	)
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestHealthCheckRespectsDeadline(t *testing.T) {
+	stuckProbe := func(ctx context.Context) error {
+		<-ctx.Done()
+		// return a different error to make sure the report doesn't wait on us
+		time.Sleep(50 * time.Millisecond)
+		return errors.New("probe gave up")
+	}
+
+	child0 := cap.NewWorker(
+		"child0",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithHealthCheck(stuckProbe),
+	)
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithHealthCheck(stuckProbe),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child0, child1),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	ctx, cancelFn := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+	defer cancelFn()
+
+	startTime := time.Now()
+	report := sup.HealthCheck(ctx)
+
+	// probes run concurrently, so we only wait for the deadline once
+	assert.True(t, time.Since(startTime) < 50*time.Millisecond)
+	assert.Equal(
+		t,
+		map[string]error{
+			"root/child0": context.DeadlineExceeded,
+			"root/child1": context.DeadlineExceeded,
+		},
+		report,
+	)
+
+	assert.NoError(t, sup.Terminate())
+
+	// a terminated supervisor reports itself as unhealthy
+	report = sup.HealthCheck(context.TODO())
+	assert.Error(t, report["root"])
+}
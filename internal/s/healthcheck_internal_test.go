@@ -22,8 +22,8 @@ func TestHealthHappyPath(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
-	notifier.workerStarted("w2", time.Now())
+	notifier.workerStarted("w1", 0)
+	notifier.workerStarted("w2", 0)
 	assert.True(t, healthcheckMonitor.IsHealthy())
 }
 
@@ -34,8 +34,8 @@ func TestAtMaxFailuresAndUnderRestartDuration(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
-	notifier.workerStarted("w2", time.Now())
+	notifier.workerStarted("w1", 0)
+	notifier.workerStarted("w2", 0)
 	assert.True(t, healthcheckMonitor.IsHealthy())
 
 	// We tolerate 2 failures, so OK
@@ -54,7 +54,7 @@ func TestHealthyReport(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
+	notifier.workerStarted("w1", 0)
 
 	hr := healthcheckMonitor.GetHealthReport()
 	assert.True(t, hr.IsHealthyReport())
@@ -68,7 +68,7 @@ func TestUnhealthyFailuresReport(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
+	notifier.workerStarted("w1", 0)
 	// Unacceptable failure
 	notifier.workerFailed("w1", errors.New("w1 error"))
 
@@ -90,7 +90,7 @@ func TestUnhealthyDelaysReport(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
+	notifier.workerStarted("w1", 0)
 	// Unacceptable delay
 	notifier.workerFailed("w1", errors.New("w1 error"))
 
@@ -112,7 +112,7 @@ func TestHealthRestoredReport(t *testing.T) {
 		healthcheckMonitor.HandleEvent(ev)
 	}
 
-	notifier.workerStarted("w1", time.Now())
+	notifier.workerStarted("w1", 0)
 	// Unacceptable failures and delays
 	notifier.workerFailed("w1", errors.New("w1 error"))
 
@@ -128,6 +128,6 @@ func TestHealthRestoredReport(t *testing.T) {
 	assert.True(t, hr.GetDelayedRestartProcesses()["w1"])
 
 	// Failures recovered
-	notifier.workerStarted("w1", time.Now())
+	notifier.workerStarted("w1", 0)
 	assert.True(t, healthcheckMonitor.GetHealthReport().IsHealthyReport())
 }
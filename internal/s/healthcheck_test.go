@@ -138,6 +138,7 @@ func TestHealthTerminateFailedChild(t *testing.T) {
 			// NOTE: From here, the stop of the supervisor begins
 			WorkerTerminated("root/branch1/child3"),
 			// NOTE: the child2 never stops and fails with a timeout
+			WorkerAbandonedOnShutdown("root/branch1/child2"),
 			WorkerFailed("root/branch1/child2"),
 			// NOTE: The supervisor branch1 fails because of child2 timeout
 			SupervisorFailed("root/branch1"),
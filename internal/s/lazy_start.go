@@ -0,0 +1,48 @@
+package s
+
+// This file contains the logic to handle children that are started on demand
+// (see c.WithLazyStart)
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// handleLazyStartNotification handles the notification of a child whose lazy
+// start trigger fired. The child is started for the first time, regardless of
+// the supervisor strategy; when the child fails to start, the start error is
+// handled as a failure of the child.
+func handleLazyStartNotification(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	sourceCh = sourceCh.TriggerLazyStart()
+	supChildren[sourceCh.GetName()] = sourceCh
+
+	newCh, startErr := startChildNode(
+		supCtx, supSpec, supRuntimeName, supNotifyChan, sourceCh.GetSpec(), supChildren,
+	)
+	if startErr != nil {
+		drainStartFailure(startErr, supNotifyChan)
+		return handleChildNodeError(
+			supCtx,
+			supTolerance,
+			supSpec, supChildSpecs,
+			supRuntimeName, supChildren, supNotifyChan,
+			sourceCh, startErr,
+		)
+	}
+
+	supChildren[newCh.GetName()] = newCh
+	return supChildren, nil
+}
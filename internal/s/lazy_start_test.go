@@ -0,0 +1,157 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// lazyTrigger returns a lazy start trigger, and a function that fires it
+func lazyTrigger() (func(context.Context) <-chan struct{}, func()) {
+	triggerCh := make(chan struct{})
+	return func(context.Context) <-chan struct{} { return triggerCh },
+		func() { close(triggerCh) }
+}
+
+func TestLazyStart(t *testing.T) {
+	t.Run("the worker starts once the trigger fires", func(t *testing.T) {
+		trigger, fire := lazyTrigger()
+		child1, failWorker1 := FailOnSignalWorker(1, "child1", cap.WithLazyStart(trigger))
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1, WaitDoneWorker("child2")),
+			[]cap.Opt{},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				fire()
+				evIt.WaitTill(WorkerStarted("root/child1"))
+				// once started, the worker is restarted when it fails
+				failWorker1(true /* done */)
+				evIt.WaitTill(WorkerFailed("root/child1"))
+				evIt.WaitTill(WorkerStarted("root/child1"))
+			},
+		)
+
+		assert.NoError(t, err)
+
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child2"),
+				SupervisorStarted("root"),
+				WorkerStarted("root/child1"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+
+	t.Run("the worker is not started when the trigger doesn't fire", func(t *testing.T) {
+		triggerDone := make(chan struct{})
+		trigger := func(ctx context.Context) <-chan struct{} {
+			go func() {
+				<-ctx.Done()
+				close(triggerDone)
+			}()
+			return nil
+		}
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(
+				WaitDoneWorker("child1"),
+				cap.NewWorker(
+					"child2",
+					func(context.Context) error {
+						t.Error("lazy worker should not be started")
+						return nil
+					},
+					cap.WithLazyStart(trigger),
+				),
+			),
+			[]cap.Opt{},
+			func(EventManager) {},
+		)
+
+		assert.NoError(t, err)
+
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+
+		select {
+		case <-triggerDone:
+		case <-time.After(time.Second):
+			t.Error("expected trigger context to be done on termination")
+		}
+	})
+
+	t.Run("a pending worker is reported on snapshots", func(t *testing.T) {
+		trigger, fire := lazyTrigger()
+		startedCh := make(chan struct{})
+
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(
+				WaitDoneWorker("child1"),
+				cap.NewWorker(
+					"child2",
+					func(ctx context.Context) error {
+						close(startedCh)
+						<-ctx.Done()
+						return nil
+					},
+					cap.WithLazyStart(trigger),
+				),
+			),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		snapshot, err := sup.Snapshot(context.TODO())
+		assert.NoError(t, err)
+		if assert.Len(t, snapshot.GetChildren(), 2) {
+			assert.False(t, snapshot.GetChildren()[0].IsLazyPending())
+			assert.True(t, snapshot.GetChildren()[1].IsLazyPending())
+		}
+		assert.Equal(t, 1, sup.ActiveChildCount(false))
+		assert.NoError(t, sup.WaitStarted(context.TODO()))
+
+		fire()
+		select {
+		case <-startedCh:
+		case <-time.After(time.Second):
+			t.Fatal("expected lazy worker to be started")
+		}
+
+		// the supervisor replaces the pending child after the worker start
+		assert.NoError(t, sup.WaitStarted(context.TODO()))
+		snapshot, err = sup.Snapshot(context.TODO())
+		assert.NoError(t, err)
+		if assert.Len(t, snapshot.GetChildren(), 2) {
+			assert.False(t, snapshot.GetChildren()[1].IsLazyPending())
+		}
+		assert.Equal(t, 2, sup.ActiveChildCount(false))
+
+		assert.NoError(t, sup.Terminate())
+	})
+}
@@ -0,0 +1,267 @@
+package s
+
+// This file contains the implementation of LoadSpec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// specDoc is the declarative representation of a node of a supervision tree;
+// nodes with children are supervisors, and nodes with a start function name
+// are workers.
+type specDoc struct {
+	Name       string            `json:"name" yaml:"name"`
+	Start      string            `json:"start,omitempty" yaml:"start,omitempty"`
+	Restart    string            `json:"restart,omitempty" yaml:"restart,omitempty"`
+	Shutdown   string            `json:"shutdown,omitempty" yaml:"shutdown,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	StartPhase int               `json:"start_phase,omitempty" yaml:"start_phase,omitempty"`
+
+	Strategy         string               `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Order            string               `json:"order,omitempty" yaml:"order,omitempty"`
+	RestartTolerance *restartToleranceDoc `json:"restart_tolerance,omitempty" yaml:"restart_tolerance,omitempty"`
+	Children         []specDoc            `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// restartToleranceDoc is the declarative representation of the restart
+// tolerance of a supervisor (see WithRestartTolerance)
+type restartToleranceDoc struct {
+	MaxRestarts uint32 `json:"max_restarts" yaml:"max_restarts"`
+	Window      string `json:"window" yaml:"window"`
+}
+
+// specLoader accumulates the problems found while building a SupervisorSpec
+// from a specDoc
+type specLoader struct {
+	registry map[string]StartFn
+	problems []string
+}
+
+func (sl *specLoader) problem(path, format string, args ...interface{}) {
+	sl.problems = append(sl.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// parseRestart parses the name of a c.Restart value (e.g. "Transient")
+func parseRestart(input string) (c.Restart, bool) {
+	for _, r := range []c.Restart{c.Permanent, c.Transient, c.Temporary} {
+		if r.String() == input {
+			return r, true
+		}
+	}
+	return c.Permanent, false
+}
+
+// parseShutdown parses a c.Shutdown value; it accepts "Indefinitely", a
+// duration (e.g. "5s"), and the string representation of a timeout (e.g.
+// "Timeout(5s)")
+func parseShutdown(input string) (c.Shutdown, bool) {
+	if input == c.Indefinitely.String() {
+		return c.Indefinitely, true
+	}
+	if strings.HasPrefix(input, "Timeout(") && strings.HasSuffix(input, ")") {
+		input = strings.TrimSuffix(strings.TrimPrefix(input, "Timeout("), ")")
+	}
+	d, err := time.ParseDuration(input)
+	if err != nil || d < 0 {
+		return c.Shutdown{}, false
+	}
+	return c.Timeout(d), true
+}
+
+// parseStrategy parses the name of a Strategy value (e.g. "OneForAll")
+func parseStrategy(input string) (Strategy, bool) {
+	switch input {
+	case "OneForOne":
+		return OneForOne, true
+	case "OneForAll":
+		return OneForAll, true
+	case "RestForOne":
+		return RestForOne, true
+	default:
+		return OneForOne, false
+	}
+}
+
+// parseOrder parses the name of an Order value (e.g. "RightToLeft")
+func parseOrder(input string) (Order, bool) {
+	switch input {
+	case "LeftToRight":
+		return LeftToRight, true
+	case "RightToLeft":
+		return RightToLeft, true
+	default:
+		return LeftToRight, false
+	}
+}
+
+// childOpts returns the c.Opt values of the child settings of the given node
+func (sl *specLoader) childOpts(path string, doc specDoc) []c.Opt {
+	var opts []c.Opt
+	if doc.Restart != "" {
+		if r, ok := parseRestart(doc.Restart); ok {
+			opts = append(opts, c.WithRestart(r))
+		} else {
+			sl.problem(path, "unknown restart '%s'", doc.Restart)
+		}
+	}
+	if doc.Shutdown != "" {
+		if shutdown, ok := parseShutdown(doc.Shutdown); ok {
+			opts = append(opts, c.WithShutdown(shutdown))
+		} else {
+			sl.problem(path, "invalid shutdown '%s'", doc.Shutdown)
+		}
+	}
+	if len(doc.Tags) > 0 {
+		opts = append(opts, c.WithTags(doc.Tags))
+	}
+	if doc.StartPhase != 0 {
+		opts = append(opts, c.WithStartPhase(doc.StartPhase))
+	}
+	return opts
+}
+
+// supervisorOpts returns the Opt values of the supervisor settings of the
+// given node
+func (sl *specLoader) supervisorOpts(path string, doc specDoc) []Opt {
+	var opts []Opt
+	if doc.Strategy != "" {
+		if strategy, ok := parseStrategy(doc.Strategy); ok {
+			opts = append(opts, WithStrategy(strategy))
+		} else {
+			sl.problem(path, "unknown strategy '%s'", doc.Strategy)
+		}
+	}
+	if doc.Order != "" {
+		if order, ok := parseOrder(doc.Order); ok {
+			opts = append(opts, WithStartOrder(order))
+		} else {
+			sl.problem(path, "unknown order '%s'", doc.Order)
+		}
+	}
+	if doc.RestartTolerance != nil {
+		window, err := time.ParseDuration(doc.RestartTolerance.Window)
+		if err != nil || window <= 0 {
+			sl.problem(path, "invalid restart tolerance window '%s'", doc.RestartTolerance.Window)
+		} else {
+			opts = append(opts, WithRestartTolerance(doc.RestartTolerance.MaxRestarts, window))
+		}
+	}
+	return opts
+}
+
+// node returns the Node of a child of a supervisor
+func (sl *specLoader) node(parentPath string, doc specDoc) Node {
+	path := parentPath + "/" + doc.Name
+	if doc.Name == "" {
+		sl.problem(parentPath, "child with an empty name")
+	}
+	opts := sl.childOpts(path, doc)
+
+	if len(doc.Children) > 0 {
+		if doc.Start != "" {
+			sl.problem(path, "a node cannot have both a start function and children")
+		}
+		return Subtree(sl.supervisor(path, doc), opts...)
+	}
+
+	if doc.Start == "" {
+		sl.problem(path, "a node must have either a start function or children")
+		return nil
+	}
+	if doc.Strategy != "" || doc.Order != "" || doc.RestartTolerance != nil {
+		sl.problem(path, "a worker cannot have supervisor settings")
+	}
+	startFn, ok := sl.registry[doc.Start]
+	if !ok || startFn == nil {
+		sl.problem(path, "unknown start function '%s'", doc.Start)
+		return nil
+	}
+	return NewWorkerWithNotifyStart(doc.Name, startFn, opts...)
+}
+
+// supervisor returns the SupervisorSpec of the given node
+func (sl *specLoader) supervisor(path string, doc specDoc) SupervisorSpec {
+	nodes := make([]Node, 0, len(doc.Children))
+	for _, childDoc := range doc.Children {
+		nodes = append(nodes, sl.node(path, childDoc))
+	}
+	opts := sl.supervisorOpts(path, doc)
+	if len(sl.problems) > 0 {
+		// the spec is discarded, NewSupervisorSpec panics on empty names
+		return SupervisorSpec{}
+	}
+	return NewSupervisorSpec(doc.Name, WithNodes(nodes...), opts...)
+}
+
+// LoadSpec builds a SupervisorSpec from a YAML (or JSON) document that
+// describes the shape and the policies of a supervision tree: the names, tags,
+// restart and shutdown settings and start phases of its nodes, and the
+// strategy, start order and restart tolerance of its supervisors. The start
+// functions of the workers are bound by name from the given registry.
+//
+// A document looks as follows:
+//
+//	name: root
+//	strategy: OneForAll           # OneForOne (default), OneForAll, RestForOne
+//	order: LeftToRight            # LeftToRight (default), RightToLeft
+//	restart_tolerance: {max_restarts: 3, window: 5s}
+//	children:
+//	  - name: http-server
+//	    start: http               # key of the registry
+//	    restart: Permanent        # Permanent (default), Transient, Temporary
+//	    shutdown: 10s             # Indefinitely, or a duration
+//	    start_phase: 1
+//	    tags: {team: payments}
+//	  - name: consumers           # nodes with children are sub-trees
+//	    children:
+//	      - name: orders
+//	        start: orders-consumer
+//
+// LoadSpec fails with an error that matches ErrInvalidSpecDoc, listing every
+// problem found, when the document cannot be parsed, when it uses unknown
+// settings values, or when it references start functions that are not in the
+// registry. Unknown fields are rejected as well, so that typos in the document
+// do not go unnoticed.
+func LoadSpec(doc []byte, registry map[string]StartFn) (SupervisorSpec, error) {
+	var root specDoc
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(string(doc)), "{") {
+		decoder := json.NewDecoder(bytes.NewReader(doc))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&root)
+	} else {
+		err = yaml.UnmarshalStrict(doc, &root)
+	}
+	if err != nil {
+		return SupervisorSpec{}, c.WrapSentinel(
+			ErrInvalidSpecDoc, err, "could not parse supervisor spec document: %v", err,
+		)
+	}
+
+	sl := &specLoader{registry: registry}
+	if root.Name == "" {
+		sl.problem("", "empty supervisor name")
+	}
+	if root.Start != "" || root.Restart != "" || root.Shutdown != "" || root.StartPhase != 0 {
+		sl.problem(root.Name, "the root supervisor cannot have child settings")
+	}
+	spec := sl.supervisor(root.Name, root)
+
+	if len(sl.problems) > 0 {
+		return SupervisorSpec{}, c.WrapSentinel(
+			ErrInvalidSpecDoc,
+			nil,
+			"supervisor spec document is invalid: %s",
+			strings.Join(sl.problems, ", "),
+		)
+	}
+	return spec, nil
+}
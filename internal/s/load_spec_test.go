@@ -0,0 +1,112 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+)
+
+// waitDoneStart is a cap.StartFn that runs until its context is done
+func waitDoneStart(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+	notifyStart(nil)
+	<-ctx.Done()
+	return nil
+}
+
+func TestLoadSpec(t *testing.T) {
+	registry := map[string]cap.StartFn{"wait-done": waitDoneStart}
+
+	t.Run("builds the tree of a YAML document", func(t *testing.T) {
+		doc := `
+name: root
+strategy: OneForAll
+restart_tolerance: {max_restarts: 3, window: 5s}
+children:
+  - name: child1
+    start: wait-done
+    restart: Transient
+    shutdown: 10s
+    tags: {team: payments}
+  - name: subtree
+    order: RightToLeft
+    children:
+      - name: child2
+        start: wait-done
+        shutdown: Indefinitely
+`
+		spec, err := cap.LoadSpec([]byte(doc), registry)
+		assert.NoError(t, err)
+
+		sup, err := spec.Start(context.TODO())
+		assert.NoError(t, err)
+		defer sup.Terminate()
+
+		topology, err := sup.Topology()
+		assert.NoError(t, err)
+		assert.Equal(t, "root", topology.GetName())
+
+		children := topology.GetChildren()
+		if assert.Len(t, children, 2) {
+			assert.Equal(t, "root/child1", children[0].GetRuntimeName())
+			assert.Equal(t, cap.Transient, children[0].GetRestart())
+			assert.Equal(t, cap.Timeout(10*time.Second), children[0].GetShutdown())
+			assert.Equal(t, map[string]string{"team": "payments"}, children[0].GetTags())
+
+			assert.Equal(t, cap.SupervisorT, children[1].GetTag())
+			if assert.Len(t, children[1].GetChildren(), 1) {
+				child2 := children[1].GetChildren()[0]
+				assert.Equal(t, "root/subtree/child2", child2.GetRuntimeName())
+				assert.Equal(t, cap.Indefinitely, child2.GetShutdown())
+			}
+		}
+	})
+
+	t.Run("builds the tree of a JSON document", func(t *testing.T) {
+		doc := `{"name": "root", "children": [{"name": "child1", "start": "wait-done"}]}`
+		spec, err := cap.LoadSpec([]byte(doc), registry)
+		assert.NoError(t, err)
+
+		sup, err := spec.Start(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sup.ActiveChildCount(false))
+		assert.NoError(t, sup.Terminate())
+	})
+
+	t.Run("reports every problem of the document", func(t *testing.T) {
+		doc := `
+name: root
+strategy: AllForOne
+children:
+  - name: child1
+    start: unknown
+  - name: child2
+    start: wait-done
+    restart: Sometimes
+  - name: child3
+`
+		_, err := cap.LoadSpec([]byte(doc), registry)
+		assert.True(t, errors.Is(err, cap.ErrInvalidSpecDoc))
+		assert.Equal(
+			t,
+			"supervisor spec document is invalid: "+
+				"root/child1: unknown start function 'unknown', "+
+				"root/child2: unknown restart 'Sometimes', "+
+				"root/child3: a node must have either a start function or children, "+
+				"root: unknown strategy 'AllForOne'",
+			err.Error(),
+		)
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		_, err := cap.LoadSpec([]byte("name: root\nstrategi: OneForAll\n"), registry)
+		assert.True(t, errors.Is(err, cap.ErrInvalidSpecDoc))
+
+		_, err = cap.LoadSpec([]byte(`{"name": "root", "strategi": "OneForAll"}`), registry)
+		assert.True(t, errors.Is(err, cap.ErrInvalidSpecDoc))
+	})
+}
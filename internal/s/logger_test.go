@@ -0,0 +1,123 @@
+package s_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// recordLogger is a cap.Logger that keeps track of the logged messages
+type recordLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (rl *recordLogger) record(level, msg string, keyvals ...interface{}) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.entries = append(rl.entries, fmt.Sprintf("%s %s %v", level, msg, keyvals))
+}
+
+func (rl *recordLogger) Debug(msg string, keyvals ...interface{}) {
+	rl.record("DEBUG", msg, keyvals...)
+}
+
+func (rl *recordLogger) Info(msg string, keyvals ...interface{}) {
+	rl.record("INFO", msg, keyvals...)
+}
+
+func (rl *recordLogger) Error(msg string, keyvals ...interface{}) {
+	rl.record("ERROR", msg, keyvals...)
+}
+
+func (rl *recordLogger) get() []string {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return append([]string{}, rl.entries...)
+}
+
+// loggingWorker logs a message with the logger of its context before it
+// notifies it has started
+func loggingWorker(name string) cap.Node {
+	return cap.NewWorkerWithNotifyStart(
+		name,
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			cap.LoggerFromContext(ctx).Info("started", "worker", name)
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		},
+	)
+}
+
+func TestLoggerIsScopedToNodes(t *testing.T) {
+	logger := &recordLogger{}
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(loggingWorker("child2")),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(loggingWorker("child1"), cap.Subtree(subtree)),
+		[]cap.Opt{cap.WithLogger(logger)},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"INFO root/child1: started [worker child1]",
+			"INFO root/subtree/child2: started [worker child2]",
+		},
+		logger.get(),
+	)
+}
+
+func TestLoggerOverriddenBySubtree(t *testing.T) {
+	rootLogger := &recordLogger{}
+	subtreeLogger := &recordLogger{}
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(loggingWorker("child2")),
+		cap.WithLogger(subtreeLogger),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(loggingWorker("child1"), cap.Subtree(subtree)),
+		[]cap.Opt{cap.WithLogger(rootLogger)},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INFO root/child1: started [worker child1]"}, rootLogger.get())
+	assert.Equal(
+		t,
+		[]string{"INFO root/subtree/child2: started [worker child2]"},
+		subtreeLogger.get(),
+	)
+}
+
+func TestLoggerFromContextWithoutLogger(t *testing.T) {
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(loggingWorker("child1")),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+	// the logger from the context discards the messages
+	assert.NoError(t, err)
+}
@@ -0,0 +1,187 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// inFlightTracker keeps track of the maximum number of workers that were
+// starting at the same time
+type inFlightTracker struct {
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (tr *inFlightTracker) worker(name string, failCh <-chan struct{}) cap.Node {
+	return cap.NewWorkerWithNotifyStart(
+		name,
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			n := atomic.AddInt32(&tr.inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&tr.maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&tr.maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&tr.inFlight, -1)
+			notifyStart(nil)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-failCh:
+				return errors.New("worker failure")
+			}
+		},
+	)
+}
+
+func TestMaxConcurrentRestarts(t *testing.T) {
+	for _, tc := range []struct {
+		startup     int
+		maxRestarts int
+		bound       int32
+	}{
+		// without the option, restarts use the startup concurrency
+		{startup: 4, maxRestarts: 0, bound: 4},
+		{startup: 4, maxRestarts: 1, bound: 1},
+		{startup: 4, maxRestarts: 2, bound: 2},
+		// restarts never go beyond the startup concurrency
+		{startup: 1, maxRestarts: 4, bound: 1},
+	} {
+		name := fmt.Sprintf(
+			"with %d concurrent starts and %d concurrent restarts", tc.startup, tc.maxRestarts,
+		)
+		t.Run(name, func(t *testing.T) {
+			tr := &inFlightTracker{}
+			failCh := make(chan struct{}, 1)
+
+			groupCh := make(chan struct{}, 1)
+			notifier := func(ev cap.Event) {
+				if ev.GetTag() == cap.GroupRestarted {
+					groupCh <- struct{}{}
+				}
+			}
+
+			opts := []cap.Opt{
+				cap.WithStrategy(cap.OneForAll),
+				cap.WithStartupConcurrency(tc.startup),
+				cap.WithNotifier(notifier),
+			}
+			if tc.maxRestarts > 0 {
+				opts = append(opts, cap.WithMaxConcurrentRestarts(tc.maxRestarts))
+			}
+
+			sup, err := cap.NewSupervisorSpec(
+				"root",
+				cap.WithNodes(
+					tr.worker("child0", failCh),
+					tr.worker("child1", nil),
+					tr.worker("child2", nil),
+					tr.worker("child3", nil),
+				),
+				opts...,
+			).Start(context.TODO())
+			assert.NoError(t, err)
+
+			// reset the tracker after the initial start
+			atomic.StoreInt32(&tr.maxInFlight, 0)
+
+			failCh <- struct{}{}
+			<-groupCh
+
+			// the restarted children are started concurrently up to the
+			// bound, and no further
+			assert.NoError(t, sup.Terminate())
+			assert.Equal(t, tc.bound, atomic.LoadInt32(&tr.maxInFlight))
+		})
+	}
+}
+
+func TestMaxConcurrentRestartsSkipsPendingOnTermination(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	collectorCtx, stopCollector := context.WithCancel(context.TODO())
+	defer stopCollector()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(collectorCtx)
+
+	child0, failWorker0 := FailOnSignalWorker(1, "child0")
+	restartingCh := make(chan struct{})
+	releaseCh := make(chan struct{})
+
+	// child1 blocks its restart until the test releases it
+	var starts int32
+	child1 := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			if atomic.AddInt32(&starts, 1) > 1 {
+				close(restartingCh)
+				<-releaseCh
+			}
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		},
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child0, child1, WaitDoneWorker("child2")),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithMaxConcurrentRestarts(1),
+		cap.WithNotifier(evManager.EventCollector(collectorCtx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+	failWorker0(true /* done */)
+	<-restartingCh
+
+	// the supervisor is terminating once its context is done, child1 is
+	// released after that
+	cancelFn()
+	close(releaseCh)
+
+	assert.NoError(t, sup.Terminate())
+	evIt.WaitTill(SupervisorTerminated("root"))
+
+	// child2 is not restarted, the supervisor was terminated while it waited
+	// for its turn; given the restart is partial, it is not reported as a
+	// group restart
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child0"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child0"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerStarted("root/child0"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child0"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestWithMaxConcurrentRestartsInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		cap.WithMaxConcurrentRestarts(0)
+	})
+}
@@ -0,0 +1,117 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// crashOnBootWorker creates a worker that fails right after it starts
+func crashOnBootWorker(name string, opts ...cap.WorkerOpt) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(context.Context) error { return errors.New("crash on boot") },
+		opts...,
+	)
+}
+
+func TestMinRuntime(t *testing.T) {
+	t.Run("crashes on boot count as weight restarts", func(t *testing.T) {
+		child1 := crashOnBootWorker("child1", cap.WithMinRuntime(time.Minute, 2))
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1),
+			[]cap.Opt{cap.WithRestartTolerance(3, time.Minute)},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				// the supervisor gives up on the second failure
+				evIt.WaitTill(WorkerFailed("root/child1"))
+				evIt.WaitTill(WorkerFailed("root/child1"))
+			},
+		)
+
+		assert.Error(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				// the second failure counts as the third and fourth restarts
+				WorkerFailed("root/child1"),
+				SupervisorFailed("root"),
+			},
+		)
+	})
+
+	t.Run("a weight above the tolerance gives up immediately", func(t *testing.T) {
+		child1 := crashOnBootWorker("child1", cap.WithMinRuntime(time.Minute, 4))
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1),
+			[]cap.Opt{cap.WithRestartTolerance(3, time.Minute)},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				// the supervisor gives up on the first failure
+				evIt.WaitTill(WorkerFailed("root/child1"))
+			},
+		)
+
+		assert.Error(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				SupervisorFailed("root"),
+			},
+		)
+	})
+
+	t.Run("failures after the min runtime count once", func(t *testing.T) {
+		child1, failWorker1 := FailOnSignalWorker(
+			2, "child1", cap.WithMinRuntime(10*time.Millisecond, 4),
+		)
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1),
+			[]cap.Opt{cap.WithRestartTolerance(3, time.Minute)},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				time.Sleep(20 * time.Millisecond)
+				failWorker1(false /* done */)
+				evIt.WaitTill(WorkerStarted("root/child1"))
+				time.Sleep(20 * time.Millisecond)
+				failWorker1(true /* done */)
+				evIt.WaitTill(WorkerStarted("root/child1"))
+			},
+		)
+
+		assert.NoError(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerFailed("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+}
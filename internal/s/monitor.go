@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/capatazlib/go-capataz/internal/c"
@@ -16,25 +18,15 @@ type strategyRestartFn = func(
 	SupervisorSpec, []c.ChildSpec, // supSpec arguments
 	supRuntimeName, map[string]c.Child, chan c.ChildNotification, // runtime arguments
 	c.Child, // source child that failed
+	[]c.Child, // children that failed with the source child (see WithRestartDampening)
 ) (map[string]c.Child, error)
 
-// NodeSepToken is the token use to separate sub-trees and child node names in
-// the supervision tree
+// NodeSepToken is the default token use to separate sub-trees and child node
+// names in the supervision tree; use WithNameSeparator to change it
 const NodeSepToken = "/"
 
 ////////////////////////////////////////////////////////////////////////////////
 
-func getRestartStrategy(supSpec SupervisorSpec) strategyRestartFn {
-	switch supSpec.strategy {
-	case OneForOne:
-		return oneForOneRestart
-	case OneForAll:
-		return oneForAllRestart
-	default:
-		panic("unknown restart strategy, check getRestartStrategy implementation")
-	}
-}
-
 func execRestartLoop(
 	supCtx context.Context,
 	supTolerance *restartToleranceManager,
@@ -45,8 +37,8 @@ func execRestartLoop(
 	supNotifyChan chan c.ChildNotification,
 	sourceCh c.Child,
 	sourceErr error,
+	coalescedChs []c.Child,
 ) (map[string]c.Child, *RestartToleranceReached) {
-	execRestart := getRestartStrategy(supSpec)
 	var prevErr, restartErr error
 
 	// we initialize prevErr with the original child error that caused this logic to get
@@ -54,9 +46,25 @@ func execRestartLoop(
 	// on
 	prevErr = sourceErr
 
+	// the children that were stable for long enough don't account for the
+	// restart tolerance anymore
+	supTolerance.heal(supChildren, supSpec.getClock().Now())
+
+	// children that crash on boot account for more restarts (see
+	// c.WithMinRuntime)
+	runtime := supSpec.getClock().Now().Sub(sourceCh.GetCreatedAt())
+	weight := sourceCh.GetSpec().ToleranceWeight(runtime)
+
 	for {
 		if prevErr != nil {
-			ok := supTolerance.checkToleranceExceeded(prevErr)
+			ok := supTolerance.checkToleranceExceeded(sourceCh.GetName(), prevErr, weight)
+			if !ok && sourceCh.GetSpec().HasFallback() && !sourceCh.IsFallback() {
+				// instead of giving up, the child is restarted with its
+				// fallback, which gets a new restart window
+				sourceCh = switchChildNodeToFallback(supSpec, supChildren, sourceCh)
+				supTolerance.reset()
+				ok = true
+			}
 			if !ok {
 				// Very important! even though we return an error value
 				// here, we want to return a supChildren, this collection
@@ -71,11 +79,11 @@ func execRestartLoop(
 			}
 		}
 
-		supChildren, restartErr = execRestart(
+		supChildren, restartErr = strategyRestart(
 			supCtx,
 			supSpec, supChildrenSpecs,
 			supRuntimeName, supChildren, supNotifyChan,
-			sourceCh,
+			sourceCh, coalescedChs,
 		)
 
 		if restartErr == nil {
@@ -83,9 +91,26 @@ func execRestartLoop(
 		}
 
 		prevErr = restartErr
+		// the restarted children failed to start, they didn't run at all
+		weight = sourceCh.GetSpec().ToleranceWeight(0)
 	}
 }
 
+// switchChildNodeToFallback marks the given child to be restarted with its
+// fallback start function (see c.WithFallback)
+func switchChildNodeToFallback(
+	supSpec SupervisorSpec,
+	supChildren map[string]c.Child,
+	sourceCh c.Child,
+) c.Child {
+	sourceCh = sourceCh.SwitchToFallback()
+	supChildren[sourceCh.GetName()] = sourceCh
+	supSpec.getEventNotifier().withTags(sourceCh.GetSpec()).childSwitchedToFallback(
+		sourceCh.GetTag(), sourceCh.GetRuntimeName(),
+	)
+	return sourceCh
+}
+
 func handleChildNodeError(
 	supCtx context.Context,
 	supTolerance *restartToleranceManager,
@@ -97,28 +122,190 @@ func handleChildNodeError(
 
 	sourceCh c.Child, sourceErr error,
 ) (map[string]c.Child, *RestartToleranceReached) {
-	eventNotifier := supSpec.getEventNotifier()
+	var escalationErr *RestartToleranceReached
+
+	sourceCh, escalationErr = registerChildNodeError(
+		supCtx, supSpec, supChildren, sourceCh, sourceErr,
+	)
+	if escalationErr != nil {
+		return supChildren, escalationErr
+	}
+
+	return restartChildNode(
+		supCtx,
+		supTolerance,
+		supSpec, supChildrenSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		sourceCh, sourceErr,
+	)
+}
+
+// registerChildNodeError notifies the failure of a child and keeps track of
+// its panics. It returns an error when the child failure must be escalated
+// regardless of the restart tolerance.
+func registerChildNodeError(
+	supCtx context.Context,
+	supSpec SupervisorSpec,
+	supChildren map[string]c.Child,
+	sourceCh c.Child, sourceErr error,
+) (c.Child, *RestartToleranceReached) {
 	chSpec := sourceCh.GetSpec()
+	eventNotifier := supSpec.getEventNotifier().withTags(chSpec)
 
-	eventNotifier.processFailed(chSpec.GetTag(), sourceCh.GetRuntimeName(), sourceErr)
+	eventNotifier.processFailed(
+		chSpec.GetTag(), sourceCh.GetRuntimeName(), sourceErr, failureReasonCode(sourceErr),
+	)
+	getRestartStats(supCtx).registerFailure()
 
-	switch chSpec.GetRestart() {
-	case c.Permanent, c.Transient:
-		// On error scenarios, Permanent and Transient try as much as possible
-		// to restart the failing child
-		return execRestartLoop(
-			supCtx,
-			supTolerance,
-			supSpec, supChildrenSpecs,
-			supRuntimeName, supChildren, supNotifyChan,
-			sourceCh, sourceErr,
-		)
+	if chSpec.HasTransientBudget() {
+		sourceCh = sourceCh.RegisterTransientFailure(supSpec.getClock().Now())
+		supChildren[chSpec.GetName()] = sourceCh
+	}
+
+	if c.IsPanicError(sourceErr) {
+		sourceCh = sourceCh.RegisterPanic()
+		supChildren[chSpec.GetName()] = sourceCh
+
+		afterPanics := chSpec.GetPanicEscalation()
+		if afterPanics > 0 && sourceCh.GetPanicCount() >= afterPanics {
+			// panics are a symptom of bugs, restarting is not going to help,
+			// we escalate regardless of the restart tolerance
+			return sourceCh, NewPanicEscalationReached(sourceCh, sourceErr)
+		}
+	}
+
+	return sourceCh, nil
+}
+
+// restartChildNode executes the restart procedure of a child that failed or
+// completed, according to the child's Restart setting, or its restart decider.
+func restartChildNode(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec, supChildrenSpecs []c.ChildSpec,
+
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+
+	sourceCh c.Child, sourceErr error,
+) (map[string]c.Child, *RestartToleranceReached) {
+	supChildren, decision, escalationErr := decideChildNodeRestart(
+		supCtx, supSpec, supRuntimeName, supChildren, supNotifyChan, sourceCh, sourceErr,
+	)
+	if escalationErr != nil || decision == nil {
+		return supChildren, escalationErr
+	}
+	return execRestartLoop(
+		supCtx,
+		supTolerance,
+		supSpec, supChildrenSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		decision.sourceCh, decision.toleranceErr, nil,
+	)
+}
 
-	default: /* Temporary */
-		// Temporary children can complete or fail, supervisor will not restart them
+// restartDecision is the outcome of decideChildNodeRestart for a child that
+// must be restarted
+type restartDecision struct {
+	// sourceCh is the child to restart, with its bookkeeping up to date
+	sourceCh c.Child
+	// toleranceErr is the error that counts against the restart tolerance, it
+	// is nil when the restart doesn't count against it
+	toleranceErr error
+}
+
+// decideChildNodeRestart applies the settings of a child that failed (when
+// sourceErr is not nil) or completed, and it decides if the child must be
+// restarted. When it returns a nil restartDecision, the child is not restarted:
+// it was removed from the supervisor, or its circuit breaker got opened. It
+// returns an error when the supervisor must escalate instead.
+//
+// Every path that restarts a child goes through this function, so that
+// dampened restarts (see WithRestartDampening) behave like regular ones.
+func decideChildNodeRestart(
+	supCtx context.Context,
+	supSpec SupervisorSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child, sourceErr error,
+) (map[string]c.Child, *restartDecision, *RestartToleranceReached) {
+	chSpec := sourceCh.GetSpec()
+
+	if chSpec.IsRetired(supSpec.getClock().Now()) {
+		return retireChildNode(supSpec, supChildren, sourceCh), nil, nil
+	}
+
+	if sourceErr == nil {
+		if action, ok := supSpec.reportUnexpectedCleanExit(sourceCh); ok {
+			switch action {
+			case CleanExitEscalate:
+				return supChildren, nil, NewUnexpectedCleanExit(sourceCh)
+			case CleanExitRestart:
+				return supChildren, &restartDecision{sourceCh: sourceCh}, nil
+			}
+		}
+
+		switch chSpec.GetRestart() {
+		case c.Transient, c.Temporary:
+			delete(supChildren, chSpec.GetName())
+			return supChildren, nil, nil
+		default: /* Permanent */
+			// On child completion, the supervisor still restart the child when
+			// the c.Restart is Permanent
+			return supChildren, &restartDecision{sourceCh: sourceCh}, nil
+		}
+	}
+
+	if policy, ok := supSpec.reportSubtreeEscalation(sourceCh, sourceErr); ok {
+		switch policy {
+		case StopSubtree:
+			// the sub-tree is not restarted, as if it was a Temporary child
+			delete(supChildren, sourceCh.GetName())
+			return supChildren, nil, nil
+		case Propagate:
+			return supChildren, nil, NewEscalationPropagated(sourceCh, sourceErr)
+		}
+	}
+
+	if sourceCh.IsTransientBudgetExceeded() {
+		// the child exhausted its transient budget, from now on it is handled
+		// as a Temporary child
 		delete(supChildren, chSpec.GetName())
-		return supChildren, nil
+		supSpec.notifyDeadLetter(NewTransientBudgetExhausted(sourceCh, sourceErr))
+		return supChildren, nil, nil
+	}
+
+	if !chSpec.ShouldRestart(sourceErr) {
+		// Temporary children can complete or fail, supervisor will not restart
+		// them; the same goes for errors discarded by a restart decider
+		delete(supChildren, chSpec.GetName())
+		return supChildren, nil, nil
+	}
+
+	if chSpec.HasCircuitBreaker() {
+		sourceCh = sourceCh.RegisterCircuitFailure(supSpec.getClock().Now())
+		supChildren[chSpec.GetName()] = sourceCh
+		if sourceCh.GetCircuitState() == c.CircuitOpen {
+			return openChildNodeCircuit(
+				supCtx, supSpec, supRuntimeName, supChildren, supNotifyChan, sourceCh,
+			), nil, nil
+		}
+		// the failures of the child are accounted by its circuit breaker,
+		// they do not count against the restart tolerance
+		return supChildren, &restartDecision{sourceCh: sourceCh}, nil
 	}
+
+	if chSpec.IsToleranceExempt() {
+		// the failure is reported, but it doesn't count against the
+		// restart tolerance
+		return supChildren, &restartDecision{sourceCh: sourceCh}, nil
+	}
+
+	// On error scenarios, Permanent and Transient try as much as possible
+	// to restart the failing child
+	return supChildren, &restartDecision{sourceCh: sourceCh, toleranceErr: sourceErr}, nil
 }
 
 func handleChildNodeCompletion(
@@ -132,32 +319,46 @@ func handleChildNodeCompletion(
 
 	sourceCh c.Child,
 ) (map[string]c.Child, *RestartToleranceReached) {
-	eventNotifier := supSpec.getEventNotifier()
+	registerChildNodeCompletion(supSpec, sourceCh)
+	return restartChildNode(
+		supCtx,
+		supTolerance,
+		supSpec, supChildSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		sourceCh,
+		nil, /* error */
+	)
+}
+
+// registerChildNodeCompletion notifies the completion of a child
+func registerChildNodeCompletion(supSpec SupervisorSpec, sourceCh c.Child) {
+	eventNotifier := supSpec.getEventNotifier().withTags(sourceCh.GetSpec())
 
 	if sourceCh.IsWorker() {
 		eventNotifier.workerCompleted(sourceCh.GetRuntimeName())
 	}
+}
 
-	chSpec := sourceCh.GetSpec()
-
-	switch chSpec.GetRestart() {
-
-	case c.Transient, c.Temporary:
-		delete(supChildren, chSpec.GetName())
-		// Do nothing
-		return supChildren, nil
-	default: /* Permanent */
-		// On child completion, the supervisor still restart the child when the
-		// c.Restart is Permanent
-		return execRestartLoop(
-			supCtx,
-			supTolerance,
-			supSpec, supChildSpecs,
-			supRuntimeName, supChildren, supNotifyChan,
-			sourceCh,
-			nil, /* error */
-		)
+// notifyDeadLetter reports to the dead letter callback of the supervisor a child
+// that is not going to be restarted again because of its failures
+func (spec SupervisorSpec) notifyDeadLetter(err *RestartToleranceReached) {
+	if spec.deadLetter == nil {
+		return
 	}
+	spec.deadLetter(err.failedChildName, err)
+}
+
+// retireChildNode removes a child that finished after its retirement time, this
+// child is not going to be restarted again.
+func retireChildNode(
+	supSpec SupervisorSpec,
+	supChildren map[string]c.Child,
+	sourceCh c.Child,
+) map[string]c.Child {
+	eventNotifier := supSpec.getEventNotifier().withTags(sourceCh.GetSpec())
+	delete(supChildren, sourceCh.GetName())
+	eventNotifier.processRetired(sourceCh.GetTag(), sourceCh.GetRuntimeName())
+	return supChildren
 }
 
 func handleChildNodeNotification(
@@ -196,6 +397,77 @@ func handleChildNodeNotification(
 	)
 }
 
+// childNotificationFn handles a notification that a child sent to its
+// supervisor
+type childNotificationFn = func(
+	context.Context,
+	*restartToleranceManager,
+	SupervisorSpec, []c.ChildSpec, // supSpec arguments
+	supRuntimeName, map[string]c.Child, chan c.ChildNotification, // runtime arguments
+	c.Child, // child that sent the notification
+	c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached)
+
+// notificationHandler returns the function that handles the given child
+// notification when the child neither failed nor completed (e.g. it got
+// restarted on purpose); it returns nil otherwise.
+func notificationHandler(chNotification c.ChildNotification) childNotificationFn {
+	switch {
+	case chNotification.IsPeriodicRestart():
+		return handlePeriodicRestartNotification
+	case chNotification.IsRestartRequested():
+		return handleRestartRequestNotification
+	case chNotification.IsCircuitCooldownExpired():
+		return handleCircuitCooldownNotification
+	case chNotification.IsLazyStartTriggered():
+		return handleLazyStartNotification
+	default:
+		return nil
+	}
+}
+
+// receiveChildNotification returns the child that sent the given notification
+// with its termination reason up to date, and it publishes the failures to the
+// notification stream of the supervision tree.
+func receiveChildNotification(
+	supCtx context.Context,
+	supSpec SupervisorSpec,
+	supChildren map[string]c.Child,
+	chNotification c.ChildNotification,
+) c.Child {
+	sourceName := supSpec.childAliases.resolve(chNotification.GetName())
+	sourceCh, ok := supChildren[sourceName]
+
+	if !ok {
+		// TODO: Expand on this case, I think this is highly unlikely, but would
+		// like to exercise this branch in test somehow (if possible)
+		panic(
+			fmt.Errorf(
+				"something horribly wrong happened here (name: %s, tag: %s)",
+				sourceCh.GetRuntimeName(),
+				sourceCh.GetTag(),
+			),
+		)
+	}
+
+	if !chNotification.IsCircuitCooldownExpired() &&
+		!chNotification.IsLazyStartTriggered() {
+		// the reason is reported once the child is restarted
+		sourceCh = sourceCh.WithTerminationReason(chNotification.Reason())
+		supChildren[sourceName] = sourceCh
+	}
+
+	// children that finished on purpose did not fail, even when they
+	// returned an error
+	if chNotification.Unwrap() != nil &&
+		!chNotification.IsPeriodicRestart() &&
+		!chNotification.IsRestartRequested() {
+		getNotificationStream(supCtx).publish(supCtx, chNotification)
+	}
+
+	return sourceCh
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // skipChildFn is a function used to skip a child during an iteration of
@@ -210,87 +482,169 @@ var noChildSkip skipChildFn = func(_ int, _ c.ChildSpec) bool {
 	return false
 }
 
-// skipChild is a skipChildFn that skips a child entry from an iteration that
-// matches the given child.
-func skipChild(ch c.Child) skipChildFn {
-	chSpec := ch.GetSpec()
-	return func(_ int, otherChSpec c.ChildSpec) bool {
-		return chSpec.GetName() == otherChSpec.GetName()
-	}
-}
-
-// TODO: use functions bellow for RestForOne strategy
-//
-
-// func skipChildBeforeIndex(i int) skipChildFn {
-//	return func(j int, _ c.ChildSpec) bool {
-//		return i < j
-//	}
-// }
-
-// func getChildIndex(chs []c.ChildSpec, ch c.Child) (int, bool) {
-//	chSpec := ch.GetSpec()
-//	for i, other := range chs {
-//		if chSpec.GetName() == other.GetName() {
-//			return i, true
-//		}
-//	}
-//	return 0, false
-// }
-
 ////////////////////////////////////////////////////////////////////////////////
 
 // startChildNode is responsible of starting a single child. This function will
 // deal with the child lifecycle notification. It will return an error if
 // something goes wrong with the initialization of this child.
+//
+// When the given supPrevChildren map contains an entry for the child, the
+// child is considered to be restarted and it keeps the bookkeeping of its
+// previous execution.
 func startChildNode(
 	startCtx context.Context,
 	supSpec SupervisorSpec,
 	supRuntimeName string,
 	notifyCh chan c.ChildNotification,
 	chSpec c.ChildSpec,
+	supPrevChildren map[string]c.Child,
 ) (c.Child, error) {
-	eventNotifier := supSpec.getEventNotifier()
-	startedTime := time.Now()
-	ch, chStartErr := chSpec.DoStart(startCtx, supRuntimeName, notifyCh)
+	var ch c.Child
+	var chStartErr error
+
+	eventNotifier := supSpec.getEventNotifier().withTags(chSpec)
+	startedTime := supSpec.getClock().Now()
+
+	exitWatchdog := supSpec.stallWatchdog.enter(stallChildStart)
+	prevCh, isRestart := supPrevChildren[chSpec.GetName()]
+	if isRestart {
+		restartCtx := withRestartMark(startCtx, true)
+		ch, chStartErr = chSpec.DoRestart(restartCtx, supRuntimeName, notifyCh, prevCh)
+	} else {
+		ch, chStartErr = chSpec.DoStart(startCtx, supRuntimeName, notifyCh)
+	}
+	exitWatchdog()
 
 	// NOTE: The error handling code bellow gets executed when the children
 	// fails at start time
 	if chStartErr != nil {
 		cRuntimeName := strings.Join(
 			[]string{supRuntimeName, chSpec.GetName()},
-			NodeSepToken,
+			c.GetNodeSeparator(startCtx),
 		)
 		eventNotifier.processStartFailed(chSpec.GetTag(), cRuntimeName, chStartErr)
 		return c.Child{}, chStartErr
 	}
 
+	if ch.GetCircuitState() == c.CircuitOpen || ch.IsLazyPending() {
+		// the child is waiting for the cooldown of its circuit breaker, or for
+		// its lazy start trigger; it is not running
+		return ch, nil
+	}
+
+	// a child that was waiting for its lazy start trigger is started for the
+	// first time
+	restarted := (isRestart && !prevCh.IsLazyPending()) || isRestartMarked(startCtx)
+	if restarted {
+		getRestartStats(startCtx).registerRestart()
+	}
+
 	// NOTE: we only notify when child is a worker because sub-trees supervisors
 	// are responsible of their own notification
-	if chSpec.IsWorker() {
-		eventNotifier.workerStarted(ch.GetRuntimeName(), startedTime)
+	if !chSpec.IsWorker() {
+		return ch, nil
+	}
+	if restarted {
+		// children that didn't report why they stopped were terminated by
+		// their supervisor (e.g. siblings restarted with the OneForAll
+		// strategy)
+		priorTermination, ok := prevCh.GetTerminationReason()
+		if !ok {
+			priorTermination = c.TerminationBySupervisor
+		}
+		eventNotifier.workerRestarted(
+			ch.GetRuntimeName(), supSpec.getClock().Now().Sub(startedTime), priorTermination,
+		)
+	} else {
+		eventNotifier.workerStarted(ch.GetRuntimeName(), supSpec.getClock().Now().Sub(startedTime))
 	}
 	return ch, nil
 }
 
-// TODO: introduce shouldSkip when supporting RestForOne functionality
+// abortChildNodesStart terminates (in reverse order) all the children that got
+// started before the given child failed to start, and builds the start error
+// that the supervisor reports.
+func abortChildNodesStart(
+	supSpec SupervisorSpec,
+	supChildrenSpecs []c.ChildSpec,
+	supRuntimeName string,
+	children map[string]c.Child,
+	chSpec c.ChildSpec,
+	chStartErr error,
+) error {
+	// we must stop previously started children before we finish the supervisor
+	nodeErrMap := terminateChildNodes(
+		supSpec,
+		supChildrenSpecs,
+		children,
+		noChildSkip,
+	)
+	var terminationErr *SupervisorTerminationError
+	if len(nodeErrMap) > 0 {
+		terminationErr = &SupervisorTerminationError{
+			supRuntimeName: supRuntimeName,
+			nodeSep:        supSpec.getNameSeparator(),
+			nodeErrMap:     nodeErrMap,
+			rscCleanupErr:  nil,
+		}
+	}
+
+	return &SupervisorStartError{
+		supRuntimeName: supRuntimeName,
+		nodeSep:        supSpec.getNameSeparator(),
+		nodeName:       chSpec.GetName(),
+		nodeErr:        chStartErr,
+		terminationErr: terminationErr,
+	}
+}
+
+// drainStartFailure reads the notification a child sends to the supNotifyChan
+// when it fails to start, so that the monitor loop doesn't handle it as the
+// failure of a running child. Children that did not notify their start on
+// time, or that returned before notifying it, do not report to the
+// supNotifyChan.
+func drainStartFailure(startErr error, supNotifyChan <-chan c.ChildNotification) {
+	if !c.IsStartTimeoutError(startErr) && !c.IsExitedBeforeStartError(startErr) {
+		<-supNotifyChan
+	}
+}
 
 // startChildNodes iterates over all the children (specified with `cap.WithNodes`
 // and `cap.WithSubtree`) starting a goroutine for each. The children iteration
 // will be sorted as specified with the `cap.WithStartOrder` option. In case any child
 // fails to start, the supervisor start operation will be aborted and all the
 // started children so far will be stopped in the reverse order.
+//
+// The supPrevChildren map contains the children of a previous execution when
+// this function is used to restart children; it is nil otherwise.
 func startChildNodes(
 	startCtx context.Context,
 	supSpec SupervisorSpec,
 	supChildrenSpecs []c.ChildSpec,
 	supRuntimeName string,
 	notifyCh chan c.ChildNotification,
+	supPrevChildren map[string]c.Child,
 ) (map[string]c.Child, error) {
+	concurrency := supSpec.startConcurrency(supPrevChildren)
+	if concurrency > 1 {
+		return startChildNodesConcurrently(
+			startCtx,
+			supSpec,
+			supChildrenSpecs,
+			supRuntimeName,
+			notifyCh,
+			supPrevChildren,
+			concurrency,
+		)
+	}
+
 	children := make(map[string]c.Child)
 
 	// Start children in the correct order
 	for _, chSpec := range supSpec.order.sortStart(supChildrenSpecs) {
+		if supSpec.skipPendingRestarts(startCtx, supPrevChildren) {
+			break
+		}
 		// the function above will modify the children internally
 		ch, chStartErr := startChildNode(
 			startCtx,
@@ -298,32 +652,178 @@ func startChildNodes(
 			supRuntimeName,
 			notifyCh,
 			chSpec,
+			supPrevChildren,
 		)
 		if chStartErr != nil {
-			// we must stop previously started children before we finish the supervisor
-			nodeErrMap := terminateChildNodes(
+			if supPrevChildren != nil {
+				// the monitor loop keeps running after a failed restart
+				drainStartFailure(chStartErr, notifyCh)
+			}
+			return nil, abortChildNodesStart(
 				supSpec,
 				supChildrenSpecs,
+				supRuntimeName,
 				children,
-				noChildSkip,
+				chSpec,
+				chStartErr,
 			)
-			var terminationErr *SupervisorTerminationError
-			if len(nodeErrMap) > 0 {
-				terminationErr = &SupervisorTerminationError{
-					supRuntimeName: supRuntimeName,
-					nodeErrMap:     nodeErrMap,
-					rscCleanupErr:  nil,
-				}
+		}
+		children[chSpec.GetName()] = ch
+	}
+
+	return children, nil
+}
+
+// startConcurrency returns how many children may be started at the same time,
+// the given map of previous children is not nil when children are restarted;
+// restarts never go beyond the startup concurrency of the supervisor
+func (spec SupervisorSpec) startConcurrency(supPrevChildren map[string]c.Child) int {
+	if supPrevChildren != nil &&
+		spec.restartConcurrency > 0 &&
+		spec.restartConcurrency < spec.startupConcurrency {
+		return spec.restartConcurrency
+	}
+	return spec.startupConcurrency
+}
+
+// skipPendingRestarts indicates if the children that wait for their turn to be
+// restarted must not be restarted, because the supervisor is terminating
+func (spec SupervisorSpec) skipPendingRestarts(
+	startCtx context.Context,
+	supPrevChildren map[string]c.Child,
+) bool {
+	return supPrevChildren != nil && spec.restartConcurrency > 0 && startCtx.Err() != nil
+}
+
+// startChildNodesConcurrently behaves like startChildNodes, with the difference
+// that it starts up to the given number of children at the same time.
+// Children are dispatched in start order, and this function returns only after
+// all the dispatched children notified their start (or failure). The events of
+// the started children are buffered, and reported from the supervisor
+// goroutine in start order once all of them are done, so the EventNotifier is
+// never called concurrently by this supervisor. The children
+// of a start phase are dispatched once all the children of the previous phase
+// notified their start, and children are dispatched once the siblings they
+// depend on notified their start (see WithDependsOn). If any child fails to
+// start, no more children are dispatched, and the started children are stopped
+// in reverse order.
+func startChildNodesConcurrently(
+	startCtx context.Context,
+	supSpec SupervisorSpec,
+	supChildrenSpecs []c.ChildSpec,
+	supRuntimeName string,
+	notifyCh chan c.ChildNotification,
+	supPrevChildren map[string]c.Child,
+	concurrency int,
+) (map[string]c.Child, error) {
+	type startResult struct {
+		ch         c.Child
+		err        error
+		events     []Event
+		dispatched bool
+	}
+
+	sortedSpecs := supSpec.order.sortStart(supChildrenSpecs)
+	results := make([]startResult, len(sortedSpecs))
+
+	// doneChs are closed once the child at the same index notified its start
+	// (or failure)
+	doneChs := make([]chan struct{}, len(sortedSpecs))
+	indexes := make(map[string]int, len(sortedSpecs))
+	for i, chSpec := range sortedSpecs {
+		doneChs[i] = make(chan struct{})
+		indexes[chSpec.GetName()] = i
+	}
+
+	var wg sync.WaitGroup
+	var failed int32
+	semaphore := make(chan struct{}, concurrency)
+
+	for i, chSpec := range sortedSpecs {
+		if i > 0 && sortedSpecs[i-1].GetStartPhase() != chSpec.GetStartPhase() {
+			// children of a phase are not dispatched until all the children of
+			// the previous phase notified their start
+			wg.Wait()
+		}
+		// children are not dispatched until the siblings they depend on
+		// notified their start; these come first in start order, so they were
+		// dispatched already
+		for _, depName := range chSpec.GetDependsOn() {
+			if j, ok := indexes[depName]; ok {
+				<-doneChs[j]
+			}
+		}
+		semaphore <- struct{}{}
+		// do not dispatch more children if one of the siblings failed already,
+		// or if the supervisor is terminating in the middle of a restart
+		if atomic.LoadInt32(&failed) == 1 ||
+			supSpec.skipPendingRestarts(startCtx, supPrevChildren) {
+			<-semaphore
+			break
+		}
+		wg.Add(1)
+		go func(i int, chSpec c.ChildSpec) {
+			defer wg.Done()
+			defer close(doneChs[i])
+			defer func() { <-semaphore }()
+			var events []Event
+			chSupSpec := supSpec
+			chSupSpec.eventNotifier = func(ev Event) { events = append(events, ev) }
+			ch, chStartErr := startChildNode(
+				startCtx,
+				chSupSpec,
+				supRuntimeName,
+				notifyCh,
+				chSpec,
+				supPrevChildren,
+			)
+			if chStartErr != nil {
+				atomic.StoreInt32(&failed, 1)
 			}
+			results[i] = startResult{ch: ch, err: chStartErr, events: events, dispatched: true}
+		}(i, chSpec)
+	}
+
+	wg.Wait()
+
+	eventNotifier := supSpec.getEventNotifier()
+	for _, result := range results {
+		for _, ev := range result.events {
+			eventNotifier(ev)
+		}
+	}
+
+	children := make(map[string]c.Child)
+	var failedSpec c.ChildSpec
+	var failedErr error
 
-			return nil, &SupervisorStartError{
-				supRuntimeName: supRuntimeName,
-				nodeName:       chSpec.GetName(),
-				nodeErr:        chStartErr,
-				terminationErr: terminationErr,
+	for i, result := range results {
+		if !result.dispatched {
+			continue
+		}
+		if result.err != nil {
+			if supPrevChildren != nil {
+				// the monitor loop keeps running after a failed restart
+				drainStartFailure(result.err, notifyCh)
+			}
+			// we report the first failing child in start order
+			if failedErr == nil {
+				failedSpec, failedErr = sortedSpecs[i], result.err
 			}
+			continue
 		}
-		children[chSpec.GetName()] = ch
+		children[sortedSpecs[i].GetName()] = result.ch
+	}
+
+	if failedErr != nil {
+		return nil, abortChildNodesStart(
+			supSpec,
+			supChildrenSpecs,
+			supRuntimeName,
+			children,
+			failedSpec,
+			failedErr,
+		)
 	}
 
 	return children, nil
@@ -332,12 +832,13 @@ func startChildNodes(
 // terminateChildNode executes the Terminate procedure on the given child, in case there is
 // an error on termination it notifies the event system
 func terminateChildNode(
-	eventNotifier EventNotifier,
+	supSpec SupervisorSpec,
 	ch c.Child,
 ) error {
 	chSpec := ch.GetSpec()
-	stoppingTime := time.Now()
-	isFirstTermination, terminationErr := ch.Terminate()
+	eventNotifier := supSpec.getEventNotifier().withTags(chSpec)
+	stoppingTime := supSpec.getClock().Now()
+	isFirstTermination, terminationErr := supSpec.terminateChild(ch)
 
 	// if it is not the first termination (it was terminated before, or finished because
 	// of a failure), we have already made notice of this termination before, so we are
@@ -347,14 +848,20 @@ func terminateChildNode(
 	}
 
 	if terminationErr != nil {
+		if isAbandonment(terminationErr) {
+			supSpec.abandonedChildren.register(ch.GetRuntimeName())
+			eventNotifier.childAbandonedOnShutdown(chSpec.GetTag(), ch.GetRuntimeName())
+		}
 		// we also notify that the process failed
 		eventNotifier.processFailed(
-			chSpec.GetTag(), ch.GetRuntimeName(), terminationErr,
+			chSpec.GetTag(), ch.GetRuntimeName(), terminationErr, ReasonShutdownError,
 		)
 		return terminationErr
 	}
 	// we need to notify that the process stopped
-	eventNotifier.processTerminated(chSpec.GetTag(), ch.GetRuntimeName(), stoppingTime)
+	eventNotifier.processTerminated(
+		chSpec.GetTag(), ch.GetRuntimeName(), supSpec.getClock().Now().Sub(stoppingTime),
+	)
 	return nil
 }
 
@@ -366,8 +873,7 @@ func terminateChildNodes(
 	supChildren map[string]c.Child,
 	shouldSkip skipChildFn,
 ) map[string]error {
-	eventNotifier := supSpec.eventNotifier
-	supChildrenSpecs := supSpec.order.sortTermination(supChildrenSpecs0)
+	supChildrenSpecs := supSpec.sortTermination(supChildrenSpecs0)
 	supNodeErrMap := make(map[string]error)
 
 	for i, chSpec := range supChildrenSpecs {
@@ -386,7 +892,7 @@ func terminateChildNodes(
 		// * On stop, there may be a Transient child that completed, or a Temporary child
 		// that completed or failed.
 		if ok {
-			terminationErr := terminateChildNode(eventNotifier, ch)
+			terminationErr := terminateChildNode(supSpec, ch)
 			if terminationErr != nil {
 				// if a child fails to stop (either because of a legit failure or a
 				// timeout), we store the terminationError so that we can report all of them
@@ -416,7 +922,9 @@ func terminateSupervisor(
 		supChildren,
 		noChildSkip,
 	)
+	exitWatchdog := supSpec.stallWatchdog.enter(stallResourceCleanup)
 	supRscCleanupErr := supRscCleanup()
+	exitWatchdog()
 
 	// If any of the children fails to stop, we should report that as an
 	// error
@@ -426,6 +934,7 @@ func terminateSupervisor(
 		// error
 		terminateErr = &SupervisorTerminationError{
 			supRuntimeName: supRuntimeName,
+			nodeSep:        supSpec.getNameSeparator(),
 			nodeErrMap:     supNodeErrMap,
 			rscCleanupErr:  supRscCleanupErr,
 		}
@@ -494,6 +1003,30 @@ func runMonitorLoop(
 	var startErr error
 	var restartErr *RestartToleranceReached
 
+	// the termination deadline is shared with the whole supervision tree
+	supSpec.terminationDeadline = getTerminationDeadline(supCtx)
+
+	// the abandoned children are accounted for the whole supervision tree
+	supSpec.abandonedChildren = getAbandonedChildren(supCtx)
+
+	// the watchdog reports the operations of this loop that take too long
+	supSpec.stallWatchdog = supSpec.newStallWatchdog()
+	defer supSpec.stallWatchdog.stop()
+	if supSpec.stallWatchdog != nil {
+		supSpec.eventNotifier = supSpec.stallWatchdog.watchNotifier(supSpec.getEventNotifier())
+	}
+
+	// swapped children keep reporting with their temporary name until they
+	// are restarted
+	supSpec.childAliases = make(childAliases)
+	supSpec.overlapChildren = make(overlapChildren)
+
+	// the logic running on the supervisor thread may schedule control messages
+	// for this loop, they are discarded once the loop is over
+	loopCtx, loopCancelFn := context.WithCancel(context.Background())
+	defer loopCancelFn()
+	supCtx = withLoopCtrl(supCtx, loopCtrl{ctx: loopCtx, ctrlChan: ctrlChan})
+
 	// Start children
 	supChildren, startErr := startChildNodes(
 		supCtx,
@@ -501,8 +1034,15 @@ func runMonitorLoop(
 		supChildrenSpecs,
 		supRuntimeName,
 		supNotifyChan,
+		nil, /* no previous children */
 	)
 	if startErr != nil {
+		// the started children were terminated already, release the resources
+		// of the supervisor before reporting the start error; we ignore cleanup
+		// errors given the start error is more relevant
+		if supRscCleanup != nil {
+			_ = supRscCleanup()
+		}
 		// in case we run in the async strategy we notify the spawner that we
 		// started with an error
 		onStart(startErr)
@@ -514,17 +1054,22 @@ func runMonitorLoop(
 	// started (we would get race-conditions if we notify from the parent
 	// otherwise).
 	eventNotifier := supSpec.getEventNotifier()
-	eventNotifier.supervisorStarted(supRuntimeName, supStartTime)
+	eventNotifier.supervisorStarted(supRuntimeName, supSpec.getClock().Now().Sub(supStartTime))
 
 	/// Once children have been spawned, we notify to the caller thread that the
 	// main loop has started without errors.
 	onStart(nil)
 
+	// dampener keeps the child failures that wait for the restart dampening
+	// window to be over, it is nil when restarts are not dampened
+	dampener := newRestartDampener(supSpec)
+
 	// Supervisor Loop
 	for {
 		select {
 		// parent context is done
 		case <-supCtx.Done():
+			dampener.exitBackoff(supCtx, supSpec)
 			return terminateSupervisor(
 				supSpec,
 				supChildrenSpecs,
@@ -536,21 +1081,14 @@ func runMonitorLoop(
 			)
 
 		case chNotification := <-supNotifyChan:
-			sourceCh, ok := supChildren[chNotification.GetName()]
+			sourceCh := receiveChildNotification(supCtx, supSpec, supChildren, chNotification)
 
-			if !ok {
-				// TODO: Expand on this case, I think this is highly unlikely, but would
-				// like to exercise this branch in test somehow (if possible)
-				panic(
-					fmt.Errorf(
-						"something horribly wrong happened here (name: %s, tag: %s)",
-						sourceCh.GetRuntimeName(),
-						sourceCh.GetTag(),
-					),
-				)
+			handleNotification := notificationHandler(chNotification)
+			if handleNotification == nil {
+				handleNotification = dampener.failureHandler()
 			}
 
-			supChildren, restartErr = handleChildNodeNotification(
+			supChildren, restartErr = handleNotification(
 				supCtx,
 				supTolerance,
 				supSpec, supChildrenSpecs,
@@ -559,6 +1097,30 @@ func runMonitorLoop(
 			)
 
 			if restartErr != nil {
+				dampener.exitBackoff(supCtx, supSpec)
+				supSpec.notifyDeadLetter(restartErr)
+				return terminateSupervisor(
+					supSpec,
+					supChildrenSpecs,
+					supRuntimeName,
+					supRscCleanup,
+					supChildren,
+					onTerminate,
+					restartErr,
+				)
+			}
+
+		// the restart dampening window is over
+		case <-dampener.getWindowDone():
+			supChildren, restartErr = dampener.restartPending(
+				supCtx,
+				supTolerance,
+				supSpec, supChildrenSpecs,
+				supRuntimeName, supChildren, supNotifyChan,
+			)
+
+			if restartErr != nil {
+				supSpec.notifyDeadLetter(restartErr)
 				return terminateSupervisor(
 					supSpec,
 					supChildrenSpecs,
@@ -571,6 +1133,35 @@ func runMonitorLoop(
 			}
 
 		case msg := <-ctrlChan:
+			// a full restart replaces the resources of the supervisor, and it
+			// makes the supervisor fail when the children cannot start again
+			if rsm, ok := msg.(restartSupervisorMsg); ok {
+				supChildrenSpecs, supChildren, supRscCleanup, restartErr = rsm.restart(
+					supCtx,
+					eventNotifier,
+					supSpec,
+					supChildrenSpecs,
+					supRuntimeName,
+					supRscCleanup,
+					supChildren,
+					supNotifyChan,
+				)
+
+				if restartErr != nil {
+					supSpec.notifyDeadLetter(restartErr)
+					return terminateSupervisor(
+						supSpec,
+						supChildrenSpecs,
+						supRuntimeName,
+						supRscCleanup,
+						supChildren,
+						onTerminate,
+						restartErr,
+					)
+				}
+				continue
+			}
+
 			supChildrenSpecs, supChildren = handleCtrlMsg(
 				supCtx,
 				eventNotifier,
@@ -0,0 +1,100 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestNameSeparatorIsInheritedBySubtrees(t *testing.T) {
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		// sub-trees use the separator of the root supervisor
+		cap.WithNameSeparator(":"),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree), WaitDoneWorker("child2")),
+		[]cap.Opt{cap.WithNameSeparator(".")},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root.subtree.child1"),
+			SupervisorStarted("root.subtree"),
+			WorkerStarted("root.child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root.child2"),
+			WorkerTerminated("root.subtree.child1"),
+			SupervisorTerminated("root.subtree"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestNameSeparatorIsUsedByErrors(t *testing.T) {
+	t.Run("on start", func(t *testing.T) {
+		subtree := cap.NewSupervisorSpec(
+			"subtree",
+			cap.WithNodes(FailStartWorker("child1")),
+		)
+
+		_, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(cap.Subtree(subtree)),
+			cap.WithNameSeparator("."),
+		).Start(context.TODO())
+
+		assert.Error(t, err)
+		assert.True(
+			t,
+			strings.Contains(cap.ExplainError(err), "worker node 'root.subtree.child1' failed to start"),
+			cap.ExplainError(err),
+		)
+	})
+
+	t.Run("on termination", func(t *testing.T) {
+		subtree := cap.NewSupervisorSpec(
+			"subtree",
+			cap.WithNodes(FailTerminationWorker("child1", errors.New("child1 failed"))),
+		)
+
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(cap.Subtree(subtree)),
+			cap.WithNameSeparator("."),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		err = sup.Terminate()
+		assert.Error(t, err)
+		assert.Equal(
+			t,
+			"supervisor terminated with failures (subtree.child1: child1 failed)",
+			err.Error(),
+		)
+		assert.True(
+			t,
+			strings.Contains(cap.ExplainError(err), "worker node 'root.subtree.child1' failed to terminate"),
+			cap.ExplainError(err),
+		)
+	})
+}
+
+func TestNameSeparatorPanicsWhenEmpty(t *testing.T) {
+	assert.Panics(t, func() {
+		cap.WithNameSeparator("")
+	})
+}
@@ -0,0 +1,161 @@
+package s
+
+// This file contains the channel that streams the failure notifications of a
+// supervision tree (see Supervisor.Notifications)
+
+import (
+	"context"
+	"sync"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// NotificationOverflow specifies what happens with the failure notifications
+// of a supervision tree when the consumer of Supervisor.Notifications falls
+// behind and the buffer of the channel is full
+type NotificationOverflow uint32
+
+const (
+	// DropOldestNotification discards the oldest buffered notification to make
+	// room for the new one; supervisors never wait on the consumer
+	DropOldestNotification NotificationOverflow = iota
+	// BlockNotification makes the supervisor of the failed child wait until
+	// the consumer reads a notification; no notification is lost, but the
+	// supervisor doesn't handle the failure until there is room in the buffer
+	BlockNotification
+)
+
+func (o NotificationOverflow) String() string {
+	switch o {
+	case DropOldestNotification:
+		return "DropOldest"
+	case BlockNotification:
+		return "Block"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// defaultNotificationBufferSize is the buffer size of the notifications channel
+// when WithNotificationBuffer is not used
+const defaultNotificationBufferSize = 16
+
+var notificationStreamKey capatazSupKey = "__capataz.supervisor.notification_stream__"
+
+// notificationSettings contains the settings of WithNotificationBuffer
+type notificationSettings struct {
+	bufferSize int
+	overflow   NotificationOverflow
+}
+
+// notificationStream fans out the failure notifications of every supervisor in
+// a supervision tree to a single channel. A single value is shared by the root
+// supervisor and all its sub-trees.
+type notificationStream struct {
+	mu       sync.Mutex
+	ch       chan c.ChildNotification
+	overflow NotificationOverflow
+	closed   bool
+	doneCh   chan struct{}
+}
+
+// newNotificationStream creates the notificationStream of a supervision tree
+// with the settings of the given root supervisor spec
+func newNotificationStream(spec SupervisorSpec) *notificationStream {
+	settings := notificationSettings{
+		bufferSize: defaultNotificationBufferSize,
+		overflow:   DropOldestNotification,
+	}
+	if spec.notifications != nil {
+		settings = *spec.notifications
+	}
+	return &notificationStream{
+		ch:       make(chan c.ChildNotification, settings.bufferSize),
+		overflow: settings.overflow,
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// withNotificationStream sets the notificationStream in the context that is
+// thread-through across all capataz logic
+func withNotificationStream(ctx context.Context, ns *notificationStream) context.Context {
+	return context.WithValue(ctx, notificationStreamKey, ns)
+}
+
+// getNotificationStream returns the notificationStream of the supervision tree,
+// nil when the context doesn't have one
+func getNotificationStream(ctx context.Context) *notificationStream {
+	ns, _ := ctx.Value(notificationStreamKey).(*notificationStream)
+	return ns
+}
+
+// publish sends the given notification to the consumer of the stream. When the
+// stream blocks on overflow, it waits until there is room in the buffer, the
+// given context is done, or the stream is closed.
+func (ns *notificationStream) publish(ctx context.Context, chNotification c.ChildNotification) {
+	if ns == nil {
+		return
+	}
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	if ns.closed {
+		return
+	}
+
+	if ns.overflow == BlockNotification {
+		select {
+		case ns.ch <- chNotification:
+		case <-ctx.Done():
+		case <-ns.doneCh:
+		}
+		return
+	}
+
+	for {
+		select {
+		case ns.ch <- chNotification:
+			return
+		default:
+			// without a buffer, there is no room to make
+			if cap(ns.ch) == 0 {
+				return
+			}
+			// make room for the new notification, the consumer may have read
+			// the oldest one already
+			select {
+			case <-ns.ch:
+			default:
+			}
+		}
+	}
+}
+
+// close releases the publishers that are waiting on the consumer and closes
+// the channel of the stream.
+func (ns *notificationStream) close() {
+	// publishers that hold the lock stop waiting once doneCh is closed
+	close(ns.doneCh)
+
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	ns.closed = true
+	close(ns.ch)
+}
+
+// Notifications returns a channel that receives the notification of every
+// child that fails in this supervision tree, including the children of its
+// sub-trees. Use the Unwrap method of a notification to get the error of the
+// child, and its RuntimeName method to identify the child.
+//
+// The channel is shared by every caller of this method, and it is closed once
+// the supervisor terminates. Check WithNotificationBuffer for the behavior of
+// the channel when its consumer is slow.
+func (sup Supervisor) Notifications() <-chan c.ChildNotification {
+	if sup.notifications == nil {
+		return nil
+	}
+	return sup.notifications.ch
+}
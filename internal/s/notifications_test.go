@@ -0,0 +1,102 @@
+package s_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// failingTimesWorker creates a worker that fails the given number of times
+// before it waits for its supervisor to terminate; the returned channel is
+// closed once the worker stops failing
+func failingTimesWorker(name string, times int) (cap.Node, <-chan struct{}) {
+	stableCh := make(chan struct{})
+	var failures int
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			if failures < times {
+				failures++
+				return fmt.Errorf("failure %d", failures)
+			}
+			close(stableCh)
+			<-ctx.Done()
+			return nil
+		},
+	), stableCh
+}
+
+func TestNotificationsOnNestedFailure(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+	subtree1 := cap.NewSupervisorSpec("subtree1", cap.WithNodes(child1))
+
+	sup, err := cap.NewSupervisorSpec(
+		"root", cap.WithNodes(cap.Subtree(subtree1)),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	failWorker1(true /* done */)
+
+	chNotification := <-sup.Notifications()
+	assert.Equal(t, "root/subtree1/child1", chNotification.RuntimeName())
+	assert.Error(t, chNotification.Unwrap())
+
+	assert.NoError(t, sup.Terminate())
+
+	// the channel is closed once the supervisor terminates
+	_, ok := <-sup.Notifications()
+	assert.False(t, ok)
+}
+
+func TestNotificationsDropOldest(t *testing.T) {
+	child1, stableCh := failingTimesWorker("child1", 3)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1),
+		cap.WithRestartTolerance(10, 5*time.Second),
+		cap.WithNotificationBuffer(1, cap.DropOldestNotification),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	<-stableCh
+	assert.NoError(t, sup.Terminate())
+
+	// only the last failure fits in the buffer
+	var errMsgs []string
+	for chNotification := range sup.Notifications() {
+		errMsgs = append(errMsgs, chNotification.Unwrap().Error())
+	}
+	assert.Equal(t, []string{"failure 3"}, errMsgs)
+}
+
+func TestNotificationsBlock(t *testing.T) {
+	child1, stableCh := failingTimesWorker("child1", 3)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1),
+		cap.WithRestartTolerance(10, 5*time.Second),
+		cap.WithNotificationBuffer(0, cap.BlockNotification),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// the supervisor doesn't restart the worker until the notification of its
+	// failure is consumed, so no notification is lost
+	for i := 1; i <= 3; i++ {
+		chNotification := <-sup.Notifications()
+		assert.Equal(t, fmt.Sprintf("failure %d", i), chNotification.Unwrap().Error())
+	}
+
+	<-stableCh
+	assert.NoError(t, sup.Terminate())
+
+	_, ok := <-sup.Notifications()
+	assert.False(t, ok)
+}
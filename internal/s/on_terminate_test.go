@@ -0,0 +1,170 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestOnTerminateCalledAfterCancel(t *testing.T) {
+	workerDone := make(chan struct{})
+	var cancelledBeforeFinalizer bool
+	var finalizerDeadline bool
+
+	worker := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			close(workerDone)
+			return nil
+		},
+		cap.WithShutdown(cap.Timeout(time.Second)),
+		cap.WithOnTerminate(func(ctx context.Context) error {
+			// the context of the worker is cancelled before the finalizer runs
+			<-workerDone
+			cancelledBeforeFinalizer = true
+			_, finalizerDeadline = ctx.Deadline()
+			return nil
+		}),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(worker),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.True(t, cancelledBeforeFinalizer)
+	assert.True(t, finalizerDeadline)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestOnTerminateError(t *testing.T) {
+	flushErr := errors.New("flush failed")
+
+	worker := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithOnTerminate(func(context.Context) error {
+			return flushErr
+		}),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(worker, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrOnTerminateFailed))
+	assert.True(t, errors.Is(err, flushErr))
+
+	var terminationErr *cap.SupervisorTerminationError
+	assert.True(t, errors.As(err, &terminationErr))
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerFailed("root/child1"),
+			SupervisorFailed("root"),
+		},
+	)
+}
+
+func TestOnTerminateNotCalledOnFailure(t *testing.T) {
+	finalizerCalls := make(chan struct{}, 10)
+	child1, failWorker1 := FailOnSignalWorker(
+		1,
+		"child1",
+		cap.WithOnTerminate(func(context.Context) error {
+			finalizerCalls <- struct{}{}
+			return nil
+		}),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker1(true /* done */)
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+	assert.NoError(t, err)
+
+	// the finalizer is only called when the supervisor terminates the worker
+	assert.Equal(t, 1, len(finalizerCalls))
+}
+
+func TestOnTerminateTimeout(t *testing.T) {
+	releaseCh := make(chan struct{})
+	defer close(releaseCh)
+
+	worker := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithShutdown(cap.Timeout(50*time.Millisecond)),
+		cap.WithOnTerminate(func(context.Context) error {
+			// the finalizer ignores its context
+			<-releaseCh
+			return nil
+		}),
+	)
+
+	startTime := time.Now()
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(worker),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	// the finalizer overrun is reported as a shutdown timeout of the worker
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrShutdownTimeout))
+	assert.False(t, errors.Is(err, cap.ErrOnTerminateFailed))
+	assert.True(t, time.Since(startTime) < time.Second)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerAbandonedOnShutdown("root/child1"),
+			WorkerFailed("root/child1"),
+			SupervisorFailed("root"),
+		},
+	)
+}
@@ -0,0 +1,104 @@
+package s
+
+// This file contains the implementation of the pause and resume of workers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// pauseChildMsg is a message sent from clients to tell a supervisor to pause or
+// resume one of its workers.
+type pauseChildMsg struct {
+	nodeName   string
+	signal     c.PauseSignal
+	resultChan chan<- error
+}
+
+func (pcm pauseChildMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	var err error
+
+	ch, ok := supChildren[pcm.nodeName]
+	if !ok {
+		err = c.WrapSentinel(ErrNodeNotFound, nil, "worker %s not found", pcm.nodeName)
+	} else {
+		ch, err = ch.SendPauseSignal(pcm.signal)
+	}
+
+	if err == nil {
+		supChildren[pcm.nodeName] = ch
+		if ch.IsPaused() {
+			evNotifier.withTags(ch.GetSpec()).workerPaused(ch.GetRuntimeName())
+		} else {
+			evNotifier.withTags(ch.GetSpec()).workerResumed(ch.GetRuntimeName())
+		}
+	}
+
+	// do not block waiting for a read
+	select {
+	case pcm.resultChan <- err:
+	default:
+	}
+
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = pauseChildMsg{}
+
+// sendPauseSignal delivers a pause signal to the given worker through its
+// parent supervisor
+func (sup Supervisor) sendPauseSignal(specName string, signal c.PauseSignal) error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	// we initialize the resultChan with a buffer of 1, we may store the result
+	// before the client is ready to read it.
+	resultChan := make(chan error, 1)
+	msg := pauseChildMsg{
+		nodeName:   specName,
+		signal:     signal,
+		resultChan: resultChan,
+	}
+
+	err := sendCtrlMsg(ctx, sup.ctrlCh, msg)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err = <-resultChan:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("could not get a confirmation from worker %s: %w", specName, ctx.Err())
+	}
+}
+
+// PauseChild sends a PauseRequested signal to the worker with the given spec
+// name. The worker must have been created with NewPausableWorker.
+//
+// A paused worker keeps running (and keeps its allocated resources), and its
+// supervisor doesn't consider it a failure while it is paused. When a paused
+// worker gets restarted, it starts again without being paused.
+func (sup Supervisor) PauseChild(specName string) error {
+	return sup.sendPauseSignal(specName, c.PauseRequested)
+}
+
+// ResumeChild sends a ResumeRequested signal to a worker that was paused with
+// PauseChild.
+func (sup Supervisor) ResumeChild(specName string) error {
+	return sup.sendPauseSignal(specName, c.ResumeRequested)
+}
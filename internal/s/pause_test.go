@@ -0,0 +1,91 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestPauseAndResumeChild(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	signalsCh := make(chan cap.PauseSignal)
+	child1 := cap.NewPausableWorker(
+		"child1",
+		func(ctx context.Context, pauseCh <-chan cap.PauseSignal) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case signal := <-pauseCh:
+					signalsCh <- signal
+				}
+			}
+		},
+	)
+	child2 := WaitDoneWorker("child2")
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1, child2),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+
+	assert.NoError(t, sup.PauseChild("child1"))
+	assert.Equal(t, cap.PauseRequested, <-signalsCh)
+	evIt.WaitTill(WorkerPaused("root/child1"))
+
+	// the worker is already paused
+	err = sup.PauseChild("child1")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrPauseStateUnchanged))
+
+	assert.NoError(t, sup.ResumeChild("child1"))
+	assert.Equal(t, cap.ResumeRequested, <-signalsCh)
+	evIt.WaitTill(WorkerResumed("root/child1"))
+
+	// the worker is not paused
+	assert.Error(t, sup.ResumeChild("child1"))
+	// the worker was not created with NewPausableWorker
+	err = sup.PauseChild("child2")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrNotPausable))
+	// the worker doesn't exist
+	err = sup.PauseChild("child3")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrNodeNotFound))
+
+	assert.NoError(t, sup.Terminate())
+	evIt.WaitTill(SupervisorTerminated("root"))
+
+	// the supervisor is not running
+	err = sup.PauseChild("child1")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrSupervisorUnreachable))
+	assert.True(t, errors.Is(err, cap.ErrSupervisorTerminated))
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerPaused("root/child1"),
+			WorkerResumed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,80 @@
+package s
+
+// This file contains the logic to restart children that have a periodic
+// restart interval (see c.WithPeriodicRestart)
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// handlePeriodicRestartNotification handles the notification of a child that
+// finished because its periodic restart interval expired. The child is started
+// again, regardless of the supervisor strategy, and the restart is not
+// accounted on the restart tolerance of the supervisor.
+//
+// The error reported by the child, if any, is ignored; the child was cancelled
+// by its periodic restart. When the child fails to start again, the start
+// error is handled as a regular child failure.
+func handlePeriodicRestartNotification(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	return restartChildNodeInPlace(
+		supCtx,
+		supTolerance,
+		supSpec, supChildSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		sourceCh,
+		func(evNotifier EventNotifier, newCh c.Child) {
+			evNotifier.childRestartedPeriodically(newCh.GetTag(), newCh.GetRuntimeName())
+		},
+	)
+}
+
+// restartChildNodeInPlace starts again a child that finished on purpose (rather
+// than because of a failure), regardless of the supervisor strategy, and
+// without accounting the restart on the restart tolerance of the supervisor.
+// The given reportRestart function reports the event of the restart.
+//
+// When the child fails to start again, the start error is handled as a regular
+// child failure.
+func restartChildNodeInPlace(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	reportRestart func(EventNotifier, c.Child),
+) (map[string]c.Child, *RestartToleranceReached) {
+	newCh, startErr := startChildNode(
+		supCtx, supSpec, supRuntimeName, supNotifyChan, sourceCh.GetSpec(), supChildren,
+	)
+	if startErr != nil {
+		drainStartFailure(startErr, supNotifyChan)
+		return handleChildNodeError(
+			supCtx,
+			supTolerance,
+			supSpec, supChildSpecs,
+			supRuntimeName, supChildren, supNotifyChan,
+			sourceCh, startErr,
+		)
+	}
+
+	supChildren[newCh.GetName()] = newCh
+	reportRestart(supSpec.getEventNotifier().withTags(newCh.GetSpec()), newCh)
+	return supChildren, nil
+}
@@ -0,0 +1,74 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestPeriodicRestart(t *testing.T) {
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithPeriodicRestart(20*time.Millisecond),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			// periodic restarts only restart the worker, regardless of the
+			// strategy
+			cap.WithStrategy(cap.OneForAll),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// the default restart tolerance (1 restart every 5 seconds) is not
+			// affected by periodic restarts
+			evIt.WaitTill(WorkerRestartedPeriodically("root/child1"))
+			evIt.WaitTill(WorkerRestartedPeriodically("root/child1"))
+			evIt.WaitTill(WorkerRestartedPeriodically("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertPartialMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedPeriodically("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedPeriodically("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedPeriodically("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+
+	for _, ev := range events {
+		// periodic restarts are not reported as failures
+		assert.NotEqual(t, cap.ProcessFailed, ev.GetTag(), ev.String())
+	}
+
+	// the sibling of the recycled worker is never restarted
+	child2Starts := 0
+	for _, ev := range events {
+		if WorkerStarted("root/child2").Call(ev) {
+			child2Starts++
+		}
+	}
+	assert.Equal(t, 1, child2Starts)
+}
@@ -69,18 +69,21 @@ func TestPermanentOneForAllSingleCompleteWorker(t *testing.T) {
 			WorkerTerminated("root/child2"),
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
 			// ^^^ 1st restart
 
 			WorkerCompleted("root/child1"),
 			WorkerTerminated("root/child2"),
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
 			// ^^^ 2nd restart
 
 			WorkerCompleted("root/child1"),
 			WorkerTerminated("root/child2"),
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
 			// ^^^ 3rd restart
 
 			WorkerTerminated("root/child2"),
@@ -139,6 +142,7 @@ func TestPermanentOneForAllSingleFailingWorkerRecovers(t *testing.T) {
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
 			WorkerStarted("root/child3"),
+			SupervisorGroupRestarted("root", "root/child2"),
 			// ^^^ 3) After 1st (re)start we stop
 
 			WorkerTerminated("root/child3"),
@@ -203,6 +207,7 @@ func TestPermanentOneForAllNestedFailingWorkerRecovers(t *testing.T) {
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
 			WorkerStarted("root/subtree1/child3"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child2"),
 			// ^^^ 3) After 1st (re)start we stop
 
 			WorkerTerminated("root/subtree1/child3"),
@@ -281,6 +286,7 @@ func TestPermanentOneForAllSingleFailingWorkerReachThreshold(t *testing.T) {
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
 			WorkerStarted("root/child3"),
+			SupervisorGroupRestarted("root", "root/child3"),
 			// ^^^ first restart
 
 			WorkerFailed("root/child2"),
@@ -289,6 +295,7 @@ func TestPermanentOneForAllSingleFailingWorkerReachThreshold(t *testing.T) {
 			WorkerStarted("root/child1"),
 			WorkerStarted("root/child2"),
 			WorkerStarted("root/child3"),
+			SupervisorGroupRestarted("root", "root/child2"),
 			// ^^^ failWorker2 executes here (2)
 
 			// 3rd err
@@ -386,6 +393,7 @@ func TestPermanentOneForAllNestedFailingWorkerReachThreshold(t *testing.T) {
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
 			WorkerStarted("root/subtree1/child3"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child2"),
 			// ^^^ Wait failWorker2 restarts (1st restart)
 
 			WorkerFailed("root/subtree1/child3"),
@@ -395,6 +403,7 @@ func TestPermanentOneForAllNestedFailingWorkerReachThreshold(t *testing.T) {
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
 			WorkerStarted("root/subtree1/child3"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child3"),
 			// ^^^ Wait failWorker3 restarts (2nd restart)
 
 			WorkerFailed("root/subtree1/child2"),
@@ -492,6 +501,7 @@ func TestPermanentOneForAllNestedFailingWorkerErrorCountResets(t *testing.T) {
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
 			WorkerStarted("root/subtree1/child3"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child3"),
 			// ^^^ Wait failWorker3 restarts
 
 			// 2nd err -- even though we only tolerate one error, the second
@@ -503,6 +513,7 @@ func TestPermanentOneForAllNestedFailingWorkerErrorCountResets(t *testing.T) {
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
 			WorkerStarted("root/subtree1/child3"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child2"),
 			// ^^^ Wait failWorker2 restarts
 
 			WorkerTerminated("root/subtree1/child3"),
@@ -592,6 +603,7 @@ func TestPermanentOneForAllSiblingTerminationFailOnRestart(t *testing.T) {
 			// ^^^ We see failWorker1 causing the error
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child1"),
 			// ^^^ Wait failWorker1 restarts
 
 			// 2nd err
@@ -600,6 +612,7 @@ func TestPermanentOneForAllSiblingTerminationFailOnRestart(t *testing.T) {
 			// ^^^ After 1st (re)start we stop
 			WorkerStarted("root/subtree1/child1"),
 			WorkerStarted("root/subtree1/child2"),
+			SupervisorGroupRestarted("root/subtree1", "root/subtree1/child1"),
 			// ^^^ Wait failWorker1 restarts (2nd)
 
 			// 3rd err
@@ -0,0 +1,79 @@
+package s
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// PipelineStageFn is the function that runs the business logic of a pipeline
+// stage. It receives the channel from where it reads the values of the
+// previous stage, and the channel where it writes the values for the next
+// stage.
+//
+// The in channel is nil on the first stage of the pipeline, and the out
+// channel is nil on the last stage of the pipeline.
+//
+// The channels are owned by the pipeline; a stage must not close them, given
+// they are re-used when a stage gets restarted.
+type PipelineStageFn = func(
+	ctx context.Context, in <-chan interface{}, out chan<- interface{},
+) error
+
+// PipelineStage represents a step of a pipeline built with NewPipeline.
+//
+// Use NewPipelineStage to create values of this type.
+type PipelineStage struct {
+	name  string
+	runFn PipelineStageFn
+	opts  []c.Opt
+}
+
+// NewPipelineStage creates a PipelineStage that runs the given function on a
+// supervised worker goroutine with the given name. The given options are used
+// when building the stage worker.
+func NewPipelineStage(name string, runFn PipelineStageFn, opts ...c.Opt) PipelineStage {
+	return PipelineStage{name: name, runFn: runFn, opts: opts}
+}
+
+// toNode creates the worker Node of a pipeline stage
+func (stage PipelineStage) toNode(in <-chan interface{}, out chan<- interface{}) Node {
+	runFn := stage.runFn
+	return NewWorker(
+		stage.name,
+		func(ctx context.Context) error {
+			return runFn(ctx, in, out)
+		},
+		stage.opts...,
+	)
+}
+
+// NewPipeline creates a sub-tree Node that runs the given stages as workers
+// connected through channels; the values written by a stage are read by the
+// stage that follows it.
+//
+// The stages get started in the given order, and they are supervised with the
+// RestForOne strategy; when a stage fails, the failing stage and all the stages
+// that come after it get restarted, while the stages that come before it keep
+// running.
+//
+// The channels between stages get created every time the pipeline sub-tree
+// starts (or restarts).
+func NewPipeline(name string, stages ...PipelineStage) Node {
+	buildNodes := func() ([]Node, CleanupResourcesFn, error) {
+		nodes := make([]Node, 0, len(stages))
+		var in chan interface{}
+		for i, stage := range stages {
+			var out chan interface{}
+			if i < len(stages)-1 {
+				out = make(chan interface{})
+			}
+			nodes = append(nodes, stage.toNode(in, out))
+			in = out
+		}
+		return nodes, func() error { return nil }, nil
+	}
+	return Subtree(
+		NewSupervisorSpec(name, buildNodes, WithStrategy(RestForOne)),
+	)
+}
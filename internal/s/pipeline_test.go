@@ -0,0 +1,150 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRestForOneRestartsFollowingSiblings(t *testing.T) {
+	child0 := WaitDoneWorker("child0")
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+	child2 := WaitDoneWorker("child2")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child0, child1, child2),
+		[]cap.Opt{cap.WithStrategy(cap.RestForOne)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker1(true /* done */)
+			evIt.WaitTill(WorkerStarted("root/child2"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child0"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			// child0 is not touched given it started before child1
+			WorkerTerminated("root/child2"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child0"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestPipelineSourceFailureRestartsDownstream(t *testing.T) {
+	failSource := make(chan struct{})
+	received := make(chan interface{}, 2)
+
+	source := cap.NewPipelineStage(
+		"source",
+		func(ctx context.Context, _ <-chan interface{}, out chan<- interface{}) error {
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- "hello":
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-failSource:
+				return errors.New("source failure")
+			}
+		},
+	)
+
+	transform := cap.NewPipelineStage(
+		"transform",
+		func(ctx context.Context, in <-chan interface{}, out chan<- interface{}) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case v := <-in:
+					select {
+					case <-ctx.Done():
+						return nil
+					case out <- v.(string) + " world":
+					}
+				}
+			}
+		},
+	)
+
+	sink := cap.NewPipelineStage(
+		"sink",
+		func(ctx context.Context, in <-chan interface{}, _ chan<- interface{}) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case v := <-in:
+					received <- v
+				}
+			}
+		},
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.NewPipeline("pipeline", source, transform, sink)),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			assert.Equal(t, "hello world", <-received)
+
+			failSource <- struct{}{}
+			evIt.WaitTill(WorkerStarted("root/pipeline/sink"))
+			assert.Equal(t, "hello world", <-received)
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/pipeline/source"),
+			WorkerStarted("root/pipeline/transform"),
+			WorkerStarted("root/pipeline/sink"),
+			SupervisorStarted("root/pipeline"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/pipeline/source"),
+			// downstream stages are terminated in reverse order
+			WorkerTerminated("root/pipeline/sink"),
+			WorkerTerminated("root/pipeline/transform"),
+			WorkerStarted("root/pipeline/source"),
+			WorkerStarted("root/pipeline/transform"),
+			WorkerStarted("root/pipeline/sink"),
+			WorkerTerminated("root/pipeline/sink"),
+			WorkerTerminated("root/pipeline/transform"),
+			WorkerTerminated("root/pipeline/source"),
+			SupervisorTerminated("root/pipeline"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
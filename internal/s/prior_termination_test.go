@@ -0,0 +1,99 @@
+package s_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// startedEvents returns the ProcessStarted events of the worker with the given
+// runtime name
+func startedEvents(events []cap.Event, name string) []cap.Event {
+	var result []cap.Event
+	pred := WorkerStarted(name)
+	for _, ev := range events {
+		if pred.Call(ev) {
+			result = append(result, ev)
+		}
+	}
+	return result
+}
+
+func TestPriorTermination(t *testing.T) {
+	// child1 exits cleanly on its first run
+	var child1Runs int32
+	child1 := cap.NewWorker("child1", func(ctx context.Context) error {
+		if atomic.AddInt32(&child1Runs, 1) == 1 {
+			return nil
+		}
+		<-ctx.Done()
+		return nil
+	})
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+	child3 := WaitDoneWorker("child3")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child2, child3, child1),
+		[]cap.Opt{cap.WithStrategy(cap.RestForOne)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			// child1 is restarted after its clean exit
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker2(true /* done */)
+			// child1 is the last sibling restarted by RestForOne
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected []cap.TerminationReason
+	}{
+		// a Permanent worker that exited cleanly, and then got restarted
+		// because of the failure of child2
+		{
+			name: "root/child1",
+			expected: []cap.TerminationReason{
+				cap.TerminationCleanExit, cap.TerminationBySupervisor,
+			},
+		},
+		// a worker that crashed
+		{
+			name:     "root/child2",
+			expected: []cap.TerminationReason{cap.TerminationErrorExit},
+		},
+		// a later sibling restarted because of the failure of child2
+		{
+			name:     "root/child3",
+			expected: []cap.TerminationReason{cap.TerminationBySupervisor},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			started := startedEvents(events, tc.name)
+			if !assert.Len(t, started, len(tc.expected)+1) {
+				return
+			}
+
+			// the first start is not a restart
+			_, ok := started[0].GetPriorTermination()
+			assert.False(t, ok)
+
+			for i, expected := range tc.expected {
+				reason, ok := started[i+1].GetPriorTermination()
+				assert.True(t, ok)
+				assert.Equal(t, expected, reason)
+			}
+		})
+	}
+}
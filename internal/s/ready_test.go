@@ -0,0 +1,49 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestReady(t *testing.T) {
+	t.Run("ready is closed after the tree starts", func(t *testing.T) {
+		subtree := cap.NewSupervisorSpec(
+			"subtree",
+			cap.WithNodes(WaitDoneWorker("child2")),
+		)
+
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1"), cap.Subtree(subtree)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		select {
+		case <-sup.Ready():
+		case <-time.After(time.Second):
+			t.Error("expected ready channel to be closed")
+		}
+
+		assert.NoError(t, sup.Terminate())
+	})
+
+	t.Run("ready is never closed when the start fails", func(t *testing.T) {
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1"), FailStartWorker("child2")),
+		).Start(context.TODO())
+		assert.Error(t, err)
+
+		select {
+		case <-sup.Ready():
+			t.Error("expected ready channel to remain open")
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
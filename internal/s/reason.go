@@ -0,0 +1,130 @@
+package s
+
+// This file contains the reason codes reported on the failure events
+
+import (
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// ReasonCode is a machine-readable code that specifies why a failure Event was
+// reported. Reason codes are stable, and they do not depend on the error
+// messages of the Event.
+type ReasonCode uint32
+
+const (
+	// ReasonNone is the ReasonCode of the Events that do not represent a
+	// failure (e.g. ProcessStarted)
+	ReasonNone ReasonCode = iota
+	// ReasonChildError indicates a process returned an error
+	ReasonChildError
+	// ReasonChildPanic indicates a process that captures panics panicked
+	ReasonChildPanic
+	// ReasonStartError indicates a process failed to start
+	ReasonStartError
+	// ReasonShutdownError indicates a process failed to terminate, either
+	// because it returned an error or because its shutdown timeout expired
+	ReasonShutdownError
+	// ReasonHealthCheckFailed indicates the health probe of a process reported
+	// an error
+	ReasonHealthCheckFailed
+	// ReasonToleranceReached indicates a supervisor gave up restarting its
+	// children because their failures surpassed its restart tolerance
+	ReasonToleranceReached
+	// ReasonStartTimeout indicates a process did not notify its start before
+	// its start timeout expired
+	ReasonStartTimeout
+	// ReasonPanicEscalation indicates a supervisor gave up restarting a child
+	// that panicked as many times as its panic escalation setting allows
+	ReasonPanicEscalation
+	// ReasonTransientBudgetExhausted indicates a supervisor gave up restarting
+	// a Transient child that failed more times than its transient budget allows
+	ReasonTransientBudgetExhausted
+	// ReasonUnexpectedCleanExit indicates a supervisor escalated the clean exit
+	// of a Transient child
+	ReasonUnexpectedCleanExit
+	// ReasonEscalationPropagated indicates a supervisor propagated the
+	// escalation of a sub-tree that gave up on its children
+	ReasonEscalationPropagated
+	// ReasonRestartFailed indicates a supervisor could not build or start its
+	// children again on a full restart
+	ReasonRestartFailed
+)
+
+// String returns a string representation of the current ReasonCode
+func (rc ReasonCode) String() string {
+	switch rc {
+	case ReasonNone:
+		return "NONE"
+	case ReasonChildError:
+		return "CHILD_ERROR"
+	case ReasonChildPanic:
+		return "CHILD_PANIC"
+	case ReasonStartError:
+		return "START_ERROR"
+	case ReasonShutdownError:
+		return "SHUTDOWN_ERROR"
+	case ReasonHealthCheckFailed:
+		return "HEALTHCHECK_FAILED"
+	case ReasonToleranceReached:
+		return "TOLERANCE_REACHED"
+	case ReasonStartTimeout:
+		return "START_TIMEOUT"
+	case ReasonPanicEscalation:
+		return "PANIC_ESCALATION"
+	case ReasonTransientBudgetExhausted:
+		return "TRANSIENT_BUDGET_EXHAUSTED"
+	case ReasonUnexpectedCleanExit:
+		return "UNEXPECTED_CLEAN_EXIT"
+	case ReasonEscalationPropagated:
+		return "ESCALATION_PROPAGATED"
+	case ReasonRestartFailed:
+		return "RESTART_FAILED"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// failureReasonCode returns the ReasonCode of an error reported by a process
+// that finished with a failure
+func failureReasonCode(err error) ReasonCode {
+	switch err := err.(type) {
+	case *RestartToleranceReached:
+		return escalationReasonCode(err.cause)
+	case *SupervisorRestartError:
+		return escalationReasonCode(err.nodeErr.cause)
+	case *SupervisorTerminationError:
+		return ReasonShutdownError
+	}
+	if c.IsPanicError(err) {
+		return ReasonChildPanic
+	}
+	return ReasonChildError
+}
+
+// escalationReasonCode returns the ReasonCode of a supervisor that gave up
+// restarting a child for the given cause
+func escalationReasonCode(cause escalationCause) ReasonCode {
+	switch cause {
+	case panicsSurpassed:
+		return ReasonPanicEscalation
+	case transientBudgetExhausted:
+		return ReasonTransientBudgetExhausted
+	case unexpectedCleanExit:
+		return ReasonUnexpectedCleanExit
+	case escalationPropagated:
+		return ReasonEscalationPropagated
+	case restartFailed:
+		return ReasonRestartFailed
+	default: /* toleranceSurpassed */
+		return ReasonToleranceReached
+	}
+}
+
+// startFailureReasonCode returns the ReasonCode of an error reported by a
+// process that failed to start
+func startFailureReasonCode(err error) ReasonCode {
+	if c.IsStartTimeoutError(err) {
+		return ReasonStartTimeout
+	}
+	return ReasonStartError
+}
@@ -0,0 +1,129 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// findEvent returns the first event that matches the given predicate
+func findEvent(t *testing.T, events []cap.Event, pred EventP) cap.Event {
+	for _, ev := range events {
+		if pred.Call(ev) {
+			return ev
+		}
+	}
+	t.Fatalf("expected event was not found: %s", pred.String())
+	return cap.Event{}
+}
+
+func TestReasonCodeHealthCheckFailed(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithHealthCheck(func(context.Context) error {
+			return errors.New("database is unreachable")
+		}),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	sup.HealthCheck(ctx)
+	evIt.WaitTill(WorkerUnhealthy("root/child1"))
+
+	assert.NoError(t, sup.Terminate())
+	evIt.WaitTill(SupervisorTerminated("root"))
+
+	events := evManager.Snapshot()
+	unhealthyEv := findEvent(t, events, WorkerUnhealthy("root/child1"))
+	assert.Equal(t, cap.ReasonHealthCheckFailed, unhealthyEv.ReasonCode())
+	assert.Equal(t, "HEALTHCHECK_FAILED", unhealthyEv.ReasonCode().String())
+
+	// events that are not failures have no reason code
+	startedEv := findEvent(t, events, WorkerStarted("root/child1"))
+	assert.Equal(t, cap.ReasonNone, startedEv.ReasonCode())
+}
+
+func TestReasonCodeToleranceReached(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithRestartTolerance(1, 10*time.Second),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.Error(t, err)
+
+	workerEv := findEvent(t, events, WorkerFailed("root/child1"))
+	assert.Equal(t, cap.ReasonChildError, workerEv.ReasonCode())
+	assert.Equal(t, "CHILD_ERROR", workerEv.ReasonCode().String())
+
+	supEv := findEvent(t, events, SupervisorFailed("root"))
+	assert.Equal(t, cap.ReasonToleranceReached, supEv.ReasonCode())
+	assert.Equal(t, "TOLERANCE_REACHED", supEv.ReasonCode().String())
+}
+
+func TestReasonCodePanicEscalation(t *testing.T) {
+	child1, panicWorker1 := PanicOnSignalWorker(
+		1, "child1", cap.WithPanicEscalation(1),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{
+			cap.WithRestartTolerance(10, 10*time.Second),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			panicWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.Error(t, err)
+
+	workerEv := findEvent(t, events, WorkerFailed("root/child1"))
+	assert.Equal(t, cap.ReasonChildPanic, workerEv.ReasonCode())
+
+	// the supervisor gave up because of the panics, not its restart tolerance
+	supEv := findEvent(t, events, SupervisorFailed("root"))
+	assert.Equal(t, cap.ReasonPanicEscalation, supEv.ReasonCode())
+	assert.Equal(t, "PANIC_ESCALATION", supEv.ReasonCode().String())
+}
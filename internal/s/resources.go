@@ -0,0 +1,109 @@
+package s
+
+// This file contains the logic to acquire and release the resources declared
+// with WithResources
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ResourceSpec represents a resource that a supervisor acquires before
+// starting its children, and releases after terminating them.
+//
+// Use NewResourceSpec to create values of this type.
+type ResourceSpec struct {
+	name           string
+	acquire        func(context.Context) error
+	release        func(context.Context) error
+	releaseTimeout time.Duration
+}
+
+// NewResourceSpec creates a ResourceSpec. The acquire function is called when
+// the supervisor starts, and the release function is called when the
+// supervisor terminates. The release function receives a context that is done
+// once the given releaseTimeout expires; when the release function does not
+// finish on time, the supervisor reports a timeout error and moves on with the
+// release of the other resources.
+func NewResourceSpec(
+	name string,
+	acquire func(context.Context) error,
+	release func(context.Context) error,
+	releaseTimeout time.Duration,
+) ResourceSpec {
+	if name == "" {
+		panic("Resource cannot have empty name")
+	}
+	return ResourceSpec{
+		name:           name,
+		acquire:        acquire,
+		release:        release,
+		releaseTimeout: releaseTimeout,
+	}
+}
+
+// GetName returns the name of this ResourceSpec
+func (rs ResourceSpec) GetName() string {
+	return rs.name
+}
+
+// doRelease executes the release function of the resource, waiting at most
+// the resource's release timeout.
+func (rs ResourceSpec) doRelease() error {
+	if rs.release == nil {
+		return nil
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), rs.releaseTimeout)
+	defer cancelFn()
+
+	// the buffer allows the release goroutine to finish after a timeout
+	resultCh := make(chan error, 1)
+	go func() {
+		resultCh <- rs.release(ctx)
+	}()
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			return fmt.Errorf("resource %s release failed: %w", rs.name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("resource %s release timed out after %v", rs.name, rs.releaseTimeout)
+	}
+}
+
+// releaseResources releases the given resources in reverse order. All the
+// resources are released even when some of them fail, the first error found is
+// the one that is returned.
+func releaseResources(resources []ResourceSpec) error {
+	var firstErr error
+	for i := len(resources) - 1; i >= 0; i-- {
+		if err := resources[i].doRelease(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// acquireResources acquires the resources of the supervisor in order. If one of
+// the resources fails to be acquired, the already acquired resources are
+// released in reverse order, and the acquire error is returned.
+func (spec SupervisorSpec) acquireResources(ctx context.Context) (CleanupResourcesFn, error) {
+	for i, rs := range spec.resources {
+		if rs.acquire == nil {
+			continue
+		}
+		if err := rs.acquire(ctx); err != nil {
+			// we ignore release errors, the acquire error is more relevant
+			_ = releaseResources(spec.resources[:i])
+			return nil, fmt.Errorf("resource %s acquire failed: %w", rs.name, err)
+		}
+	}
+	resources := spec.resources
+	return func() error {
+		return releaseResources(resources)
+	}, nil
+}
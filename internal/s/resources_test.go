@@ -0,0 +1,199 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// resourceLog keeps track of the acquire and release calls of resources
+type resourceLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (rl *resourceLog) add(entry string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.entries = append(rl.entries, entry)
+}
+
+func (rl *resourceLog) get() []string {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return append([]string{}, rl.entries...)
+}
+
+func loggedResource(rl *resourceLog, name string, acquireErr error) cap.ResourceSpec {
+	return cap.NewResourceSpec(
+		name,
+		func(context.Context) error {
+			rl.add("acquire " + name)
+			return acquireErr
+		},
+		func(context.Context) error {
+			rl.add("release " + name)
+			return nil
+		},
+		time.Second,
+	)
+}
+
+func TestResourcesAcquiredAndReleasedInOrder(t *testing.T) {
+	rl := &resourceLog{}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		[]cap.Opt{
+			cap.WithResources([]cap.ResourceSpec{
+				loggedResource(rl, "r1", nil),
+				loggedResource(rl, "r2", nil),
+				loggedResource(rl, "r3", nil),
+			}),
+		},
+		func(EventManager) {
+			assert.Equal(t, []string{"acquire r1", "acquire r2", "acquire r3"}, rl.get())
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"acquire r1", "acquire r2", "acquire r3",
+			"release r3", "release r2", "release r1",
+		},
+		rl.get(),
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestResourcesAcquireFailureAbortsStart(t *testing.T) {
+	rl := &resourceLog{}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		[]cap.Opt{
+			cap.WithResources([]cap.ResourceSpec{
+				loggedResource(rl, "r1", nil),
+				loggedResource(rl, "r2", nil),
+				loggedResource(rl, "r3", errors.New("r3 is not available")),
+				loggedResource(rl, "r4", nil),
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.Equal(t, "resource r3 acquire failed: r3 is not available", err.Error())
+	assert.Equal(
+		t,
+		[]string{
+			"acquire r1", "acquire r2", "acquire r3",
+			"release r2", "release r1",
+		},
+		rl.get(),
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestResourcesReleasedOnChildStartFailure(t *testing.T) {
+	rl := &resourceLog{}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), FailStartWorker("child2")),
+		[]cap.Opt{
+			cap.WithResources([]cap.ResourceSpec{
+				loggedResource(rl, "r1", nil),
+				loggedResource(rl, "r2", nil),
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"acquire r1", "acquire r2",
+			"release r2", "release r1",
+		},
+		rl.get(),
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStartFailed("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestResourcesReleaseTimeout(t *testing.T) {
+	rl := &resourceLog{}
+
+	slowResource := cap.NewResourceSpec(
+		"slow",
+		func(context.Context) error { return nil },
+		func(ctx context.Context) error {
+			// this release never finishes on time
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			rl.add("release slow")
+			return nil
+		},
+		10*time.Millisecond,
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		[]cap.Opt{
+			cap.WithResources([]cap.ResourceSpec{
+				loggedResource(rl, "fast", nil),
+				slowResource,
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	explanation := cap.ExplainError(err)
+	assert.Contains(t, explanation, "resource slow release timed out after 10ms")
+	// the release of the fast resource is not affected by the slow one
+	assert.Equal(t, []string{"acquire fast", "release fast"}, rl.get())
+}
@@ -0,0 +1,170 @@
+package s
+
+// This file contains the implementation of the full restart of a supervisor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// restartSupervisorMsg is a message sent from clients to tell a supervisor to
+// restart all its children.
+type restartSupervisorMsg struct {
+	resultChan chan<- error
+}
+
+func (rsm restartSupervisorMsg) processMsg(
+	context.Context,
+	EventNotifier,
+	SupervisorSpec,
+	[]c.ChildSpec,
+	string,
+	map[string]c.Child,
+	chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// the restart replaces the resources of the supervisor, and it may make
+	// the supervisor fail; only the monitor loop can do that (see restart)
+	panic("library bug: restartSupervisorMsg must be handled by the monitor loop")
+}
+
+// restart terminates all the children of the supervisor (in termination
+// order) and releases the supervisor resources; then, it builds the children
+// from the supervisor spec again, and starts them. It returns the new children
+// specs, runtime children and resources cleanup of the supervisor, and an error
+// the supervisor must escalate when the children could not be built or started.
+func (rsm restartSupervisorMsg) restart(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supRscCleanup CleanupResourcesFn,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child, CleanupResourcesFn, *RestartToleranceReached) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	nodeErrMap := terminateChildNodes(spec, specChildren, supChildren, noChildSkip)
+	var rscCleanupErr error
+	if supRscCleanup != nil {
+		rscCleanupErr = supRscCleanup()
+	}
+
+	// spawned children cannot be built again from the supervisor spec, they
+	// are discarded
+	var discarded []string
+	for _, chSpec := range specChildren {
+		if !chSpec.IsSpawned() {
+			continue
+		}
+		if ch, ok := supChildren[chSpec.GetName()]; ok {
+			discarded = append(discarded, ch.GetRuntimeName())
+		}
+	}
+
+	if len(discarded) > 0 {
+		evNotifier.dynChildrenDiscarded(supRuntimeName, discarded)
+	}
+
+	var result error
+	if len(nodeErrMap) > 0 || rscCleanupErr != nil {
+		result = &SupervisorTerminationError{
+			supRuntimeName: supRuntimeName,
+			nodeSep:        spec.getNameSeparator(),
+			nodeErrMap:     nodeErrMap,
+			rscCleanupErr:  rscCleanupErr,
+		}
+	}
+
+	var newChildren map[string]c.Child
+	newSpecs, newRscCleanup, startErr := spec.buildChildrenSpecs(supCtx, supRuntimeName)
+	if startErr == nil {
+		newChildren, startErr = startChildNodes(
+			supCtx,
+			spec,
+			newSpecs,
+			supRuntimeName,
+			supNotifyChan,
+			supChildren,
+		)
+	}
+	if newRscCleanup == nil {
+		newRscCleanup = func() error { return nil }
+	}
+
+	var restartErr *RestartToleranceReached
+	if startErr != nil {
+		// the children that started were terminated already, the supervisor
+		// cannot keep running without children
+		result = startErr
+		sourceChRuntimeName, sourceChSpec := failedStartChild(spec, newSpecs, supRuntimeName, startErr)
+		restartErr = NewSupervisorRestartFailed(
+			supRuntimeName, sourceChRuntimeName, sourceChSpec, startErr,
+		)
+		newChildren = make(map[string]c.Child)
+	}
+
+	// do not block waiting for a read
+	select {
+	case rsm.resultChan <- result:
+	default:
+	}
+
+	return newSpecs, newChildren, newRscCleanup, restartErr
+}
+
+var _ ctrlMsg = restartSupervisorMsg{}
+
+// failedStartChild returns the runtime name and the spec of the child that
+// failed to start with the given start error; the runtime name is empty when
+// the error was not reported by one of the given children (e.g. the children
+// could not be built).
+func failedStartChild(
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	startErr error,
+) (string, c.ChildSpec) {
+	var supStartErr *SupervisorStartError
+	if !errors.As(startErr, &supStartErr) || supStartErr.supRuntimeName != supRuntimeName {
+		return "", c.ChildSpec{}
+	}
+	for _, chSpec := range specChildren {
+		if chSpec.GetName() == supStartErr.nodeName {
+			return strings.Join(
+				[]string{supRuntimeName, chSpec.GetName()}, spec.getNameSeparator(),
+			), chSpec
+		}
+	}
+	return "", c.ChildSpec{}
+}
+
+// Restart is a synchronous procedure that terminates all the children of the
+// supervisor (in termination order), releases the supervisor resources, and
+// builds and starts again the children from the supervisor spec.
+//
+// Children that were spawned dynamically (e.g. via DynSupervisor.Spawn) cannot
+// be built again from the spec; they are terminated and discarded, and a
+// DynChildrenDiscarded event with their runtime names is reported.
+//
+// When the children cannot be built or started again, the start error is
+// returned, and the supervisor fails with a SupervisorRestartError.
+func (sup Supervisor) Restart() error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	// we initialize the resultChan with a buffer of 1, we may store the result
+	// before the client is ready to read it.
+	resultChan := make(chan error, 1)
+	err := sendCtrlMsg(ctx, sup.ctrlCh, restartSupervisorMsg{resultChan: resultChan})
+	if err != nil {
+		return err
+	}
+
+	return sup.waitCtrlResult(resultChan)
+}
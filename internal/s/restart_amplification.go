@@ -0,0 +1,425 @@
+package s
+
+// This file contains the logic to observe and dampen restarts that get
+// amplified across the levels of a supervision tree
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// restartStats keeps track of the failures and restarts that happen in a whole
+// supervision tree. A single value is shared by the root supervisor and all its
+// sub-trees.
+type restartStats struct {
+	failures      uint64
+	coalesced     uint64
+	restarts      uint64
+	groupRestarts uint64
+	inBackoff     int64
+}
+
+var restartStatsKey capatazSupKey = "__capataz.supervisor.restart_stats__"
+var restartMarkKey capatazSupKey = "__capataz.node.restart_mark__"
+
+// withRestartStats sets the restartStats in the context that is thread-through
+// across all capataz logic
+func withRestartStats(ctx context.Context, stats *restartStats) context.Context {
+	return context.WithValue(ctx, restartStatsKey, stats)
+}
+
+// getRestartStats returns the restartStats of the supervision tree, when the
+// context doesn't have one, it returns a value that is not reachable from the
+// public API.
+func getRestartStats(ctx context.Context) *restartStats {
+	if stats, ok := ctx.Value(restartStatsKey).(*restartStats); ok {
+		return stats
+	}
+	return &restartStats{}
+}
+
+// withRestartMark indicates that the nodes started with the given context are
+// part of a restart; this mark is inherited by the children of restarted
+// sub-trees, so that their starts are accounted as restarts as well.
+func withRestartMark(ctx context.Context, restarting bool) context.Context {
+	return context.WithValue(ctx, restartMarkKey, restarting)
+}
+
+// isRestartMarked indicates if the nodes started with the given context are
+// part of a restart
+func isRestartMarked(ctx context.Context) bool {
+	restarting, _ := ctx.Value(restartMarkKey).(bool)
+	return restarting
+}
+
+func (rs *restartStats) registerFailure() {
+	atomic.AddUint64(&rs.failures, 1)
+}
+
+func (rs *restartStats) registerCoalescedFailure() {
+	atomic.AddUint64(&rs.coalesced, 1)
+}
+
+func (rs *restartStats) registerRestart() {
+	atomic.AddUint64(&rs.restarts, 1)
+}
+
+func (rs *restartStats) registerGroupRestart() {
+	atomic.AddUint64(&rs.groupRestarts, 1)
+}
+
+func (rs *restartStats) enterBackoff() {
+	atomic.AddInt64(&rs.inBackoff, 1)
+}
+
+func (rs *restartStats) exitBackoff() {
+	atomic.AddInt64(&rs.inBackoff, -1)
+}
+
+// RestartAmplificationReport contains the number of failures and restarts that
+// happened in a supervision tree
+type RestartAmplificationReport struct {
+	failures      uint64
+	coalesced     uint64
+	restarts      uint64
+	groupRestarts uint64
+	inBackoff     int64
+}
+
+// GetFailures returns the number of node failures reported in the supervision
+// tree, including failures of sub-trees.
+func (rar RestartAmplificationReport) GetFailures() uint64 {
+	return rar.failures
+}
+
+// GetCoalescedFailures returns the number of node failures that were merged
+// into an ongoing restart (see WithRestartDampening).
+func (rar RestartAmplificationReport) GetCoalescedFailures() uint64 {
+	return rar.coalesced
+}
+
+// GetRestarts returns the number of nodes that were restarted in the
+// supervision tree. When a sub-tree is restarted, its children are also
+// accounted as restarted nodes.
+func (rar RestartAmplificationReport) GetRestarts() uint64 {
+	return rar.restarts
+}
+
+// GetGroupRestarts returns the number of times a OneForAll supervisor of the
+// tree restarted all its children; the nodes restarted on each of these group
+// restarts are accounted by GetRestarts.
+func (rar RestartAmplificationReport) GetGroupRestarts() uint64 {
+	return rar.groupRestarts
+}
+
+// GetChildrenInBackoff returns the number of nodes that, at the time of the
+// report, are waiting for a restart dampening window to be over before they
+// get restarted (see WithRestartDampening).
+func (rar RestartAmplificationReport) GetChildrenInBackoff() int64 {
+	return rar.inBackoff
+}
+
+// GetRatio returns the number of restarted nodes per failure; a value higher
+// than one indicates failures are causing more restarts than needed.
+func (rar RestartAmplificationReport) GetRatio() float64 {
+	if rar.failures == 0 {
+		return 0
+	}
+	return float64(rar.restarts) / float64(rar.failures)
+}
+
+// String returns a human-readable representation of the report
+func (rar RestartAmplificationReport) String() string {
+	return fmt.Sprintf(
+		"failures: %d, coalesced: %d, restarts: %d, group restarts: %d",
+		rar.failures, rar.coalesced, rar.restarts, rar.groupRestarts,
+	)
+}
+
+// RestartAmplification returns a report with the failures and restarts that
+// happened in the whole supervision tree since it was started.
+func (sup Supervisor) RestartAmplification() RestartAmplificationReport {
+	stats := sup.restartStats
+	if stats == nil {
+		return RestartAmplificationReport{}
+	}
+	return RestartAmplificationReport{
+		failures:      atomic.LoadUint64(&stats.failures),
+		coalesced:     atomic.LoadUint64(&stats.coalesced),
+		restarts:      atomic.LoadUint64(&stats.restarts),
+		groupRestarts: atomic.LoadUint64(&stats.groupRestarts),
+		inBackoff:     atomic.LoadInt64(&stats.inBackoff),
+	}
+}
+
+// restartDampener keeps track of the restart dampening window of a supervisor
+// (see WithRestartDampening). The window opens when a child failure is
+// restarted, the failures that are pending at that point are coalesced into
+// that restart; the failures that arrive while the window is open wait for it
+// to be over, and then they are handled together with a single restart.
+type restartDampener struct {
+	window     time.Duration
+	windowDone <-chan time.Time
+
+	// the notifications and children that wait for the window to be over; the
+	// children are in backoff, they exit the backoff once the window is over,
+	// or when the supervisor is terminated or escalates a failure before that
+	notifications map[string]c.ChildNotification
+	children      map[string]c.Child
+	inBackoff     []c.Child
+}
+
+// newRestartDampener returns the restartDampener of the given supervisor, it
+// returns nil when the supervisor doesn't dampen its restarts
+func newRestartDampener(supSpec SupervisorSpec) *restartDampener {
+	if supSpec.restartDampening <= 0 {
+		return nil
+	}
+	// the restarts of the OneForOne strategy do not affect siblings, there is
+	// nothing to coalesce
+	if rs, ok := supSpec.getRestartStrategy().(Strategy); ok && rs == OneForOne {
+		return nil
+	}
+	return &restartDampener{window: supSpec.restartDampening}
+}
+
+// isWindowOpen indicates if the child failures must wait for the dampening
+// window to be over before they get restarted
+func (rd *restartDampener) isWindowOpen() bool {
+	return rd != nil && rd.windowDone != nil
+}
+
+// openWindow starts a dampening window
+func (rd *restartDampener) openWindow(supSpec SupervisorSpec) {
+	if rd == nil {
+		return
+	}
+	rd.windowDone = supSpec.getClock().After(rd.window)
+}
+
+// getWindowDone returns a channel that receives a value when the dampening
+// window is over; it is nil when there is no window open, which blocks forever
+// on a select statement
+func (rd *restartDampener) getWindowDone() <-chan time.Time {
+	if rd == nil {
+		return nil
+	}
+	return rd.windowDone
+}
+
+// failureHandler returns the function that handles the failure (or the
+// completion) of a child of the supervisor
+func (rd *restartDampener) failureHandler() childNotificationFn {
+	switch {
+	case rd == nil:
+		return handleChildNodeNotification
+	case rd.isWindowOpen():
+		return rd.handleDampenedChildNodeNotification
+	default:
+		// the failures that are pending already are coalesced into this
+		// restart, and the ones that arrive after it are dampened
+		return rd.handleCoalescedChildNodeNotification
+	}
+}
+
+// registerPending registers the given child notification, so that it is
+// handled with the pending restart; the notifications that arrive while there
+// is a restart pending are coalesced into it.
+func (rd *restartDampener) registerPending(
+	supCtx context.Context,
+	supSpec SupervisorSpec,
+	supChildren map[string]c.Child,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (c.Child, *RestartToleranceReached) {
+	var escalationErr *RestartToleranceReached
+
+	if len(rd.children) > 0 {
+		getRestartStats(supCtx).registerCoalescedFailure()
+	} else {
+		rd.notifications = make(map[string]c.ChildNotification)
+		rd.children = make(map[string]c.Child)
+	}
+
+	if chNotification.Unwrap() != nil {
+		sourceCh, escalationErr = registerChildNodeError(
+			supCtx, supSpec, supChildren, sourceCh, chNotification.Unwrap(),
+		)
+	} else {
+		registerChildNodeCompletion(supSpec, sourceCh)
+	}
+
+	rd.notifications[sourceCh.GetName()] = chNotification
+	rd.children[sourceCh.GetName()] = sourceCh
+	return sourceCh, escalationErr
+}
+
+// handleCoalescedChildNodeNotification handles the given child notification
+// when there is no dampening window open; the failures (and completions) of
+// other children that are pending already are coalesced into a single
+// restart, and the dampening window opens.
+func (rd *restartDampener) handleCoalescedChildNodeNotification(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	_, escalationErr := rd.registerPending(
+		supCtx, supSpec, supChildren, sourceCh, chNotification,
+	)
+
+	for drained := false; !drained && escalationErr == nil; {
+		select {
+		case pendingNotification := <-supNotifyChan:
+			pendingCh := receiveChildNotification(
+				supCtx, supSpec, supChildren, pendingNotification,
+			)
+			if handleNotification := notificationHandler(pendingNotification); handleNotification != nil {
+				supChildren, escalationErr = handleNotification(
+					supCtx,
+					supTolerance,
+					supSpec, supChildSpecs,
+					supRuntimeName, supChildren, supNotifyChan,
+					pendingCh, pendingNotification,
+				)
+			} else {
+				_, escalationErr = rd.registerPending(
+					supCtx, supSpec, supChildren, pendingCh, pendingNotification,
+				)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	if escalationErr != nil {
+		rd.notifications, rd.children = nil, nil
+		return supChildren, escalationErr
+	}
+
+	return rd.restartPending(
+		supCtx,
+		supTolerance,
+		supSpec, supChildSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+	)
+}
+
+// handleDampenedChildNodeNotification registers the given child notification,
+// and puts the child in backoff until the dampening window is over.
+func (rd *restartDampener) handleDampenedChildNodeNotification(
+	supCtx context.Context,
+	_ *restartToleranceManager,
+	supSpec SupervisorSpec,
+	_ []c.ChildSpec,
+	_ string,
+	supChildren map[string]c.Child,
+	_ chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	sourceCh, escalationErr := rd.registerPending(
+		supCtx, supSpec, supChildren, sourceCh, chNotification,
+	)
+
+	rd.inBackoff = append(rd.inBackoff, sourceCh)
+	getRestartStats(supCtx).enterBackoff()
+	supSpec.getEventNotifier().withTags(sourceCh.GetSpec()).childEnteredBackoff(
+		sourceCh.GetTag(), sourceCh.GetRuntimeName(),
+	)
+
+	if escalationErr != nil {
+		rd.exitBackoff(supCtx, supSpec)
+	}
+	return supChildren, escalationErr
+}
+
+// exitBackoff takes the children that wait for the dampening window out of
+// their backoff
+func (rd *restartDampener) exitBackoff(supCtx context.Context, supSpec SupervisorSpec) {
+	if rd == nil {
+		return
+	}
+	stats := getRestartStats(supCtx)
+	eventNotifier := supSpec.getEventNotifier()
+	for _, ch := range rd.inBackoff {
+		stats.exitBackoff()
+		eventNotifier.withTags(ch.GetSpec()).childExitedBackoff(ch.GetTag(), ch.GetRuntimeName())
+	}
+	rd.inBackoff = nil
+}
+
+// restartPending handles the notifications that waited for the dampening
+// window to be over with a single restart of the child that gets started
+// first; the other dampened children, and the children their failures affect,
+// are restarted with it. The window is opened again when there was a restart
+// pending, otherwise it is closed.
+func (rd *restartDampener) restartPending(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	dampened, dampenedChildren := rd.notifications, rd.children
+	rd.notifications, rd.children, rd.windowDone = nil, nil, nil
+	if len(dampenedChildren) == 0 {
+		return supChildren, nil
+	}
+	rd.exitBackoff(supCtx, supSpec)
+	rd.openWindow(supSpec)
+
+	// the restart of the child that gets started first restarts the other
+	// dampened children as well; with the built-in strategies they are part of
+	// its affected children already, while custom strategies may affect
+	// disjoint groups of children
+	var restart *restartDecision
+	var coalescedChs []c.Child
+	var escalationErr *RestartToleranceReached
+
+	for _, chSpec := range supSpec.order.sortStart(supChildSpecs) {
+		ch, ok := dampenedChildren[chSpec.GetName()]
+		if !ok {
+			continue
+		}
+		var decision *restartDecision
+		supChildren, decision, escalationErr = decideChildNodeRestart(
+			supCtx, supSpec, supRuntimeName, supChildren, supNotifyChan,
+			ch, dampened[chSpec.GetName()].Unwrap(),
+		)
+		if escalationErr != nil {
+			return supChildren, escalationErr
+		}
+		if decision == nil {
+			continue
+		}
+		if restart == nil {
+			restart = decision
+		} else {
+			coalescedChs = append(coalescedChs, decision.sourceCh)
+		}
+	}
+
+	if restart == nil {
+		return supChildren, nil
+	}
+
+	return execRestartLoop(
+		supCtx,
+		supTolerance,
+		supSpec, supChildSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		restart.sourceCh, restart.toleranceErr, coalescedChs,
+	)
+}
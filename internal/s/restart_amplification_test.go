@@ -0,0 +1,471 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/captest"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRestartDampeningCoalescesFailures(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, child2),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithRestartDampening(time.Hour),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		[]cap.Opt{cap.WithClock(clock)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			// there is no restart to coalesce into, child1 is restarted right
+			// away, and the dampening window opens
+			failWorker1(false)
+			evIt.WaitTill(SupervisorGroupRestarted("root/subtree", "root/subtree/child1"))
+
+			// both children fail while the window is open; without dampening,
+			// each failure would restart both children
+			failWorker1(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/subtree/child1"))
+			failWorker2(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/subtree/child2"))
+
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(SupervisorGroupRestarted("root/subtree", "root/subtree/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorStarted("root/subtree"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerTerminated("root/subtree/child2"),
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorGroupRestarted("root/subtree", "root/subtree/child1"),
+			WorkerFailed("root/subtree/child1"),
+			WorkerEnteredBackoff("root/subtree/child1"),
+			WorkerFailed("root/subtree/child2"),
+			WorkerEnteredBackoff("root/subtree/child2"),
+			WorkerExitedBackoff("root/subtree/child1"),
+			WorkerExitedBackoff("root/subtree/child2"),
+			// a single restart for both failures
+			WorkerStarted("root/subtree/child1"),
+			WorkerStarted("root/subtree/child2"),
+			SupervisorGroupRestarted("root/subtree", "root/subtree/child1"),
+			WorkerTerminated("root/subtree/child2"),
+			WorkerTerminated("root/subtree/child1"),
+			SupervisorTerminated("root/subtree"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestRestartDampeningCoalescesPendingFailures(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, child2),
+		cap.WithStrategy(cap.RestForOne),
+		cap.WithRestartDampening(100*time.Millisecond),
+	)
+
+	var failed, child1Restarted int32
+	var failOnce sync.Once
+	restartedCh := make(chan struct{})
+	notifier := func(ev cap.Event) {
+		switch {
+		case ev.GetTag() == cap.ProcessFailed &&
+			ev.GetProcessRuntimeName() == "root/subtree/child2":
+			failOnce.Do(func() {
+				// child1 fails while the supervisor handles the failure of
+				// child2, there is no restart (nor dampening window) before
+				atomic.StoreInt32(&failed, 1)
+				failWorker1(false)
+				time.Sleep(20 * time.Millisecond)
+			})
+		case ev.GetTag() == cap.ChildEnteredBackoff:
+			t.Errorf("unexpected backoff of %s", ev.GetProcessRuntimeName())
+		case ev.GetTag() == cap.ProcessStarted &&
+			ev.GetProcessRuntimeName() == "root/subtree/child1" &&
+			atomic.LoadInt32(&failed) == 1:
+			atomic.StoreInt32(&child1Restarted, 1)
+		case ev.GetTag() == cap.ProcessStarted &&
+			ev.GetProcessRuntimeName() == "root/subtree/child2" &&
+			atomic.LoadInt32(&child1Restarted) == 1:
+			close(restartedCh)
+		}
+	}
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		cap.WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	failWorker2(false)
+	<-restartedCh
+
+	// the pending failure of child1 is coalesced into the restart of child2;
+	// without it, child2 would be restarted on its own, and then again with
+	// child1 once the dampening window is over
+	report := sup.RestartAmplification()
+	assert.Equal(t, uint64(2), report.GetFailures())
+	assert.Equal(t, uint64(1), report.GetCoalescedFailures())
+	assert.Equal(t, uint64(2), report.GetRestarts())
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestRestartAmplification(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(1, "child1")
+	child2 := WaitDoneWorker("child2")
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, child2),
+		cap.WithStrategy(cap.OneForAll),
+	)
+
+	restartedCh := make(chan struct{}, 1)
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.ProcessStarted &&
+			ev.GetProcessRuntimeName() == "root/subtree/child2" {
+			select {
+			case restartedCh <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		cap.WithNotifier(notifier),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// drain the notification of the initial start
+	<-restartedCh
+	assert.Equal(t, uint64(0), sup.RestartAmplification().GetRestarts())
+
+	failWorker1(true /* done */)
+	<-restartedCh
+
+	report := sup.RestartAmplification()
+	assert.Equal(t, uint64(1), report.GetFailures())
+	// child1 failure restarted both children of the subtree
+	assert.Equal(t, uint64(2), report.GetRestarts())
+	assert.Equal(t, float64(2), report.GetRatio())
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestRestartDampeningBackoffGauge(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithRestartDampening(time.Hour),
+	)
+
+	backoffCh := make(chan cap.EventTag, 2)
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.ChildEnteredBackoff || ev.GetTag() == cap.ChildExitedBackoff {
+			assert.Equal(t, "root/subtree/child1", ev.GetProcessRuntimeName())
+			backoffCh <- ev.GetTag()
+		}
+	}
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		cap.WithNotifier(notifier),
+		cap.WithClock(clock),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// the first failure is restarted right away, the second one waits for the
+	// dampening window to be over
+	failWorker1(false)
+	failWorker1(false)
+	assert.Equal(t, cap.ChildEnteredBackoff, <-backoffCh)
+	assert.Equal(t, int64(1), sup.RestartAmplification().GetChildrenInBackoff())
+
+	// the supervisor keeps serving requests while the child is in backoff
+	ctx, cancelFn := context.WithTimeout(context.TODO(), time.Second)
+	defer cancelFn()
+	_, err = sup.Snapshot(ctx)
+	assert.NoError(t, err)
+
+	// the dampening window never gets over, the supervisor termination takes
+	// the child out of the backoff
+	assert.NoError(t, sup.Terminate())
+	assert.Equal(t, cap.ChildExitedBackoff, <-backoffCh)
+	assert.Equal(t, int64(0), sup.RestartAmplification().GetChildrenInBackoff())
+}
+
+func TestRestartDampeningCircuitBreaker(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2, "child1", cap.WithCircuitBreaker(2, 2*time.Hour),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithStrategy(cap.OneForAll),
+			cap.WithRestartDampening(time.Hour),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			failWorker1(false)
+			evIt.WaitTill(SupervisorGroupRestarted("root", "root/child1"))
+
+			// the second failure is dampened, it is accounted by the circuit
+			// breaker once the window is over
+			failWorker1(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/child1"))
+
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerCircuitOpened("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	opened := false
+	for _, ev := range events {
+		if WorkerCircuitOpened("root/child1").Call(ev) {
+			opened = true
+		}
+	}
+	assert.True(t, opened)
+}
+
+func TestRestartDampeningSubtreeEscalation(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(child1))
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree), child2),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithStrategy(cap.OneForAll),
+			cap.WithRestartDampening(time.Hour),
+			cap.WithEscalationPolicy(cap.StopSubtree),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			// the restart of child2 opens the dampening window
+			failWorker2(false)
+			evIt.WaitTill(SupervisorGroupRestarted("root", "root/child2"))
+
+			// the sub-tree gives up on its child while the window is open
+			failWorker1(false)
+			evIt.WaitTill(WorkerStarted("root/subtree/child1"))
+			failWorker1(true)
+			evIt.WaitTill(SupervisorEnteredBackoff("root/subtree"))
+
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(SubtreeEscalated("root/subtree"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	// the sub-tree is stopped rather than restarted once the window is over
+	escalated := false
+	for _, ev := range events {
+		if SubtreeEscalated("root/subtree").Call(ev) {
+			escalated = true
+			continue
+		}
+		if escalated {
+			assert.False(t, SupervisorStarted("root/subtree").Call(ev))
+		}
+	}
+	assert.True(t, escalated)
+}
+
+func TestRestartDampeningCustomStrategy(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2, "child1", cap.WithTags(map[string]string{"group": "a"}),
+	)
+	child2 := taggedWorker("child2", "b")
+	child3 := taggedWorker("child3", "a")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, child2, child3),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithRestartStrategy(groupStrategy{}),
+			cap.WithRestartDampening(time.Hour),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			failWorker1(false)
+			evIt.WaitTill(WorkerStarted("root/child3"))
+
+			// the failure happens while the window is open, the restart waits
+			// for it to be over
+			failWorker1(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/child1"))
+
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerStarted("root/child3"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child3"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child3"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child3"),
+			WorkerFailed("root/child1"),
+			WorkerEnteredBackoff("root/child1"),
+			WorkerExitedBackoff("root/child1"),
+			WorkerTerminated("root/child3"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child3"),
+			WorkerTerminated("root/child3"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestRestartDampeningCustomStrategyDisjointGroups(t *testing.T) {
+	clock := captest.NewFakeClock(time.Now())
+	child1, failWorker1 := FailOnSignalWorker(
+		2, "child1", cap.WithTags(map[string]string{"group": "a"}),
+	)
+	child2, failWorker2 := FailOnSignalWorker(
+		1, "child2", cap.WithTags(map[string]string{"group": "b"}),
+	)
+	child3 := taggedWorker("child3", "a")
+	child4 := taggedWorker("child4", "b")
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, child2, child3, child4),
+		[]cap.Opt{
+			cap.WithClock(clock),
+			cap.WithRestartStrategy(groupStrategy{}),
+			cap.WithRestartDampening(time.Hour),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+
+			failWorker1(false)
+			evIt.WaitTill(WorkerStarted("root/child3"))
+
+			// children of both groups fail while the window is open
+			failWorker1(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/child1"))
+			failWorker2(false)
+			evIt.WaitTill(WorkerEnteredBackoff("root/child2"))
+
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+			evIt.WaitTill(WorkerStarted("root/child4"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child3"),
+			WorkerStarted("root/child4"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child3"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child3"),
+			WorkerFailed("root/child1"),
+			WorkerEnteredBackoff("root/child1"),
+			WorkerFailed("root/child2"),
+			WorkerEnteredBackoff("root/child2"),
+			WorkerExitedBackoff("root/child1"),
+			WorkerExitedBackoff("root/child2"),
+			// a single restart of the affected children of both groups
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child3"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child3"),
+			WorkerStarted("root/child4"),
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child3"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,95 @@
+package s
+
+// This file contains the implementation of the manual restart of a child
+
+import (
+	"context"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// restartChildMsg is a message sent from clients to tell a supervisor to
+// restart one of its children.
+type restartChildMsg struct {
+	nodeName   string
+	resultChan chan<- error
+}
+
+func (rcm restartChildMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	ch, ok := supChildren[rcm.nodeName]
+	if !ok {
+		err := c.WrapSentinel(ErrNodeNotFound, nil, "child %s not found", rcm.nodeName)
+		// do not block waiting for a read
+		select {
+		case rcm.resultChan <- err:
+		default:
+		}
+		return specChildren, supChildren
+	}
+
+	// the restart is intentional, it is not accounted on the restart tolerance
+	// of the supervisor
+	result := terminateChildNode(spec, ch)
+
+	newCh, startErr := startChildNode(
+		supCtx, spec, supRuntimeName, supNotifyChan, ch.GetSpec(), supChildren,
+	)
+	if startErr != nil {
+		drainStartFailure(startErr, supNotifyChan)
+		delete(supChildren, rcm.nodeName)
+		result = startErr
+	} else {
+		supChildren[rcm.nodeName] = newCh
+		evNotifier.withTags(newCh.GetSpec()).childRestartedManually(newCh.GetTag(), newCh.GetRuntimeName())
+	}
+
+	// do not block waiting for a read
+	select {
+	case rcm.resultChan <- result:
+	default:
+	}
+
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = restartChildMsg{}
+
+// RestartChild is a synchronous procedure that terminates the child with the
+// given spec name and starts it again, without affecting its siblings. It
+// allows a child to re-read its configuration on its start function.
+//
+// Given the restart is intentional, it does not count against the restart
+// tolerance of the supervisor; a ChildRestartedManually event is reported once
+// the child is running again. When the child fails to start, it is removed
+// from the supervisor and the start error is returned.
+//
+// An error is returned when the supervisor doesn't have a running child with
+// the given name.
+func (sup Supervisor) RestartChild(specName string) error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	// we initialize the resultChan with a buffer of 1, we may store the result
+	// before the client is ready to read it.
+	resultChan := make(chan error, 1)
+	msg := restartChildMsg{nodeName: specName, resultChan: resultChan}
+
+	err := sendCtrlMsg(ctx, sup.ctrlCh, msg)
+	if err != nil {
+		return err
+	}
+
+	return sup.waitCtrlResult(resultChan)
+}
@@ -0,0 +1,135 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRestartChild(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	// the worker reads its configuration on every start
+	var configReads int32
+	child1 := cap.NewWorker("child1", func(ctx context.Context) error {
+		atomic.AddInt32(&configReads, 1)
+		<-ctx.Done()
+		return nil
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		cap.WithStrategy(cap.OneForAll),
+		// manual restarts do not count against the tolerance
+		cap.WithRestartTolerance(1, time.Minute),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	assert.NoError(t, sup.RestartChild("child1"))
+	assert.NoError(t, sup.RestartChild("child1"))
+
+	assert.NoError(t, sup.Terminate())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&configReads))
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			// siblings are not affected, even with the OneForAll strategy
+			WorkerTerminated("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedManually("root/child1"),
+			WorkerTerminated("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartedManually("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestRestartChildNotFound(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	err = sup.RestartChild("unknown")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrNodeNotFound))
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestRestartChildStartFailure(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	var starts int32
+	child1 := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			if atomic.AddInt32(&starts, 1) > 1 {
+				startErr := errors.New("invalid configuration")
+				notifyStart(startErr)
+				return startErr
+			}
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		},
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	err = sup.RestartChild("child1")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid configuration", err.Error())
+
+	// the supervisor keeps running without the child
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child1"),
+			WorkerStartFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,72 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+var errConnReset = errors.New("connection reset")
+
+func TestRestartDecider(t *testing.T) {
+	// the worker returns the errors it receives on errCh
+	errCh := make(chan error)
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-errCh:
+				return err
+			}
+		},
+		cap.WithRestart(cap.Permanent),
+		cap.WithRestartDecider(func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			errCh <- errConnReset
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			// the decider discards this error, the worker is not restarted
+			// even though it is Permanent
+			errCh <- context.Canceled
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,42 @@
+package s
+
+// This file contains the logic to restart children that requested their own
+// restart (see c.RequestRestart)
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// handleRestartRequestNotification handles the notification of a child that
+// finished because it requested its own restart. The child is started again,
+// regardless of the supervisor strategy, and the restart is not accounted on
+// the restart tolerance of the supervisor.
+//
+// The error reported by the child, if any, is ignored; the child was cancelled
+// because of its request.
+func handleRestartRequestNotification(
+	supCtx context.Context,
+	supTolerance *restartToleranceManager,
+	supSpec SupervisorSpec,
+	supChildSpecs []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	sourceCh c.Child,
+	chNotification c.ChildNotification,
+) (map[string]c.Child, *RestartToleranceReached) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	return restartChildNodeInPlace(
+		supCtx,
+		supTolerance,
+		supSpec, supChildSpecs,
+		supRuntimeName, supChildren, supNotifyChan,
+		sourceCh,
+		func(evNotifier EventNotifier, newCh c.Child) {
+			evNotifier.childRestartRequested(newCh.GetTag(), newCh.GetRuntimeName())
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRequestRestart(t *testing.T) {
+	var runs int
+	child1 := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			runs++
+			if runs <= 3 {
+				assert.True(t, cap.RequestRestart(ctx))
+				<-ctx.Done()
+				// the error of a worker that requested its restart is ignored
+				return ctx.Err()
+			}
+			<-ctx.Done()
+			return nil
+		},
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			// requested restarts don't restart siblings
+			cap.WithStrategy(cap.OneForAll),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// more restarts than the default restart tolerance allows
+			evIt.WaitTill(WorkerRestartRequested("root/child1"))
+			evIt.WaitTill(WorkerRestartRequested("root/child1"))
+			evIt.WaitTill(WorkerRestartRequested("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1"),
+			WorkerRestartRequested("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartRequested("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerRestartRequested("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestRequestRestartOutsideOfWorker(t *testing.T) {
+	assert.False(t, cap.RequestRestart(context.Background()))
+}
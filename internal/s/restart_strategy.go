@@ -0,0 +1,252 @@
+package s
+
+// This file contains the implementation of the RestartStrategy extension point
+
+import (
+	"context"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// ChildInfo describes a child of a supervisor to a RestartStrategy
+type ChildInfo struct {
+	name       string
+	tag        c.ChildTag
+	tags       map[string]string
+	dependsOn  []string
+	startIndex int
+}
+
+// newChildInfo creates the ChildInfo of the given child spec, which is at the
+// given index of the supervisor start order
+func newChildInfo(chSpec c.ChildSpec, startIndex int) ChildInfo {
+	return ChildInfo{
+		name:       chSpec.GetName(),
+		tag:        chSpec.GetTag(),
+		tags:       chSpec.GetTags(),
+		dependsOn:  chSpec.GetDependsOn(),
+		startIndex: startIndex,
+	}
+}
+
+// GetName returns the spec name of the child
+func (ci ChildInfo) GetName() string {
+	return ci.name
+}
+
+// GetTag returns the c.ChildTag of the child
+func (ci ChildInfo) GetTag() c.ChildTag {
+	return ci.tag
+}
+
+// GetTags returns the tags of the child (see WithTags)
+func (ci ChildInfo) GetTags() map[string]string {
+	return ci.tags
+}
+
+// GetDependsOn returns the names of the siblings the child depends on (see
+// WithDependsOn)
+func (ci ChildInfo) GetDependsOn() []string {
+	return ci.dependsOn
+}
+
+// GetStartIndex returns the position of the child in the start order of its
+// supervisor, the first child to start has index zero
+func (ci ChildInfo) GetStartIndex() int {
+	return ci.startIndex
+}
+
+// RestartStrategy decides which children of a supervisor are restarted when
+// one of them fails. The built-in Strategy values (OneForOne, OneForAll and
+// RestForOne) implement this interface.
+type RestartStrategy interface {
+	// AffectedChildren returns the children that must be restarted when the
+	// given child fails. The siblings are given in start order, and they
+	// include the failed child. The failed child is always restarted, even
+	// when it is not returned.
+	AffectedChildren(failed ChildInfo, siblings []ChildInfo) []ChildInfo
+}
+
+// AffectedChildren returns the children that the strategy restarts when the
+// given child fails
+func (s Strategy) AffectedChildren(failed ChildInfo, siblings []ChildInfo) []ChildInfo {
+	switch s {
+	case OneForAll:
+		return siblings
+	case RestForOne:
+		affected := make([]ChildInfo, 0, len(siblings))
+		for _, sibling := range siblings {
+			if sibling.GetStartIndex() >= failed.GetStartIndex() {
+				affected = append(affected, sibling)
+			}
+		}
+		return affected
+	default:
+		return []ChildInfo{failed}
+	}
+}
+
+var _ RestartStrategy = OneForOne
+
+// getRestartStrategy returns the RestartStrategy of the supervisor; it is the
+// one given with WithRestartStrategy, or the built-in Strategy otherwise.
+func (spec SupervisorSpec) getRestartStrategy() RestartStrategy {
+	if spec.restartStrategy != nil {
+		return spec.restartStrategy
+	}
+	return spec.strategy
+}
+
+// affectedChildren returns the names of the children the given RestartStrategy
+// restarts when the given child fails; the failed child and the
+// siblings that depend on it (see WithDependsOn) are always part of it.
+//
+// The new versions of swapped children are restarted on their own while they
+// run next to the old ones (see Supervisor.SwapChild); they are not siblings
+// of the other children until the swap completes.
+func (spec SupervisorSpec) affectedChildren(
+	rs RestartStrategy, supChildrenSpecs []c.ChildSpec, sourceCh c.Child,
+) map[string]struct{} {
+	affected := map[string]struct{}{sourceCh.GetName(): {}}
+	if spec.overlapChildren.has(sourceCh.GetName()) {
+		return affected
+	}
+
+	sortedSpecs := spec.order.sortStart(supChildrenSpecs)
+	siblings := make([]ChildInfo, 0, len(sortedSpecs))
+	var failed ChildInfo
+	for _, chSpec := range sortedSpecs {
+		if spec.overlapChildren.has(chSpec.GetName()) {
+			continue
+		}
+		info := newChildInfo(chSpec, len(siblings))
+		if chSpec.GetName() == sourceCh.GetName() {
+			failed = info
+		}
+		siblings = append(siblings, info)
+	}
+
+	for _, info := range rs.AffectedChildren(failed, siblings) {
+		affected[info.GetName()] = struct{}{}
+	}
+	for _, chSpec := range dependentsOf(supChildrenSpecs, sourceCh.GetName()) {
+		if !spec.overlapChildren.has(chSpec.GetName()) {
+			affected[chSpec.GetName()] = struct{}{}
+		}
+	}
+	return affected
+}
+
+// strategyRestart is the restart procedure of every RestartStrategy, built-in
+// or custom. The affected children are terminated in termination order, and
+// started again in start order; a GroupRestarted event is reported when the
+// strategy is OneForAll. The children that failed with the source child while
+// their restarts were dampened are restarted along with their affected
+// children as well.
+var strategyRestart strategyRestartFn = func(
+	supCtx context.Context,
+	spec SupervisorSpec, supChildrenSpecs []c.ChildSpec,
+
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+
+	sourceCh c.Child,
+	coalescedChs []c.Child,
+) (map[string]c.Child, error) {
+	rs := spec.getRestartStrategy()
+	affected := spec.affectedChildren(rs, supChildrenSpecs, sourceCh)
+
+	failed := map[string]struct{}{sourceCh.GetName(): {}}
+	for _, ch := range coalescedChs {
+		failed[ch.GetName()] = struct{}{}
+		for chName := range spec.affectedChildren(rs, supChildrenSpecs, ch) {
+			affected[chName] = struct{}{}
+		}
+	}
+
+	isGroupRestart := rs == OneForAll
+	eventNotifier := spec.getEventNotifier()
+	if isGroupRestart && spec.groupRestartsOnly {
+		spec.eventNotifier = skipSiblingEvents(eventNotifier, sourceCh)
+	}
+
+	// the failed children are not running anymore, there is nothing to
+	// terminate
+	isUnaffected := func(_ int, chSpec c.ChildSpec) bool {
+		_, isAffected := affected[chSpec.GetName()]
+		_, isFailed := failed[chSpec.GetName()]
+		return !isAffected || isFailed
+	}
+
+	// we do not want to stop the restart procedure if a termination fails,
+	// nonetheless, this error is not going unnoticed given the event
+	// notifier gets called on child termination.
+	_ /* nodeErrMap */ = terminateChildNodes(
+		spec, supChildrenSpecs, supChildren, isUnaffected,
+	)
+
+	restartSpecs := make([]c.ChildSpec, 0, len(affected))
+	for _, chSpec := range supChildrenSpecs {
+		if _, ok := affected[chSpec.GetName()]; ok {
+			restartSpecs = append(restartSpecs, chSpec)
+		}
+	}
+
+	restartedChildren, restartErr := startChildNodes(
+		supCtx,
+		spec,
+		restartSpecs,
+		supRuntimeName,
+		supNotifyChan,
+		supChildren,
+	)
+
+	if restartErr != nil {
+		// Very important! the affected children are not running, but we keep
+		// their previous entries so that a retry restarts them with their
+		// bookkeeping (fallback, restart and panic counts, circuit state);
+		// terminating them again is a no-op.
+		return supChildren, restartErr
+	}
+
+	for chName, ch := range restartedChildren {
+		supChildren[chName] = ch
+	}
+
+	// the supervisor is terminating and some of the children were not
+	// restarted (see WithMaxConcurrentRestarts), the group was not restarted
+	if len(restartedChildren) < len(restartSpecs) {
+		return supChildren, nil
+	}
+
+	if isGroupRestart {
+		restarted := make([]string, 0, len(restartedChildren))
+		for _, chSpec := range spec.order.sortStart(restartSpecs) {
+			ch, ok := restartedChildren[chSpec.GetName()]
+			if !ok || chSpec.GetName() == sourceCh.GetName() {
+				continue
+			}
+			restarted = append(restarted, ch.GetRuntimeName())
+		}
+
+		getRestartStats(supCtx).registerGroupRestart()
+		eventNotifier.groupRestarted(supRuntimeName, sourceCh.GetRuntimeName(), restarted)
+	}
+
+	return supChildren, nil
+}
+
+// skipSiblingEvents returns an EventNotifier that doesn't report the
+// termination and start events of the siblings of the given child; these are
+// aggregated in the GroupRestarted event (see WithGroupRestartEventsOnly).
+func skipSiblingEvents(en EventNotifier, sourceCh c.Child) EventNotifier {
+	return func(ev Event) {
+		isSibling := ev.processRuntimeName != sourceCh.GetRuntimeName()
+		isRestartEv := ev.tag == ProcessTerminated || ev.tag == ProcessStarted
+		if isSibling && isRestartEv {
+			return
+		}
+		en(ev)
+	}
+}
@@ -0,0 +1,160 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// groupStrategy restarts the failed child together with the siblings that
+// share its "group" tag
+type groupStrategy struct{}
+
+func (groupStrategy) AffectedChildren(
+	failed cap.ChildInfo, siblings []cap.ChildInfo,
+) []cap.ChildInfo {
+	group := failed.GetTags()["group"]
+	affected := []cap.ChildInfo{}
+	for _, sibling := range siblings {
+		if sibling.GetTags()["group"] == group {
+			affected = append(affected, sibling)
+		}
+	}
+	return affected
+}
+
+// taggedWorker creates a worker that blocks until it is terminated, and that
+// belongs to the given group
+func taggedWorker(name, group string) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithTags(map[string]string{"group": group}),
+	)
+}
+
+func TestRestartStrategy(t *testing.T) {
+	t.Run("custom strategy restarts the affected children", func(t *testing.T) {
+		child1, failWorker1 := FailOnSignalWorker(
+			1, "child1", cap.WithTags(map[string]string{"group": "a"}),
+		)
+		child2 := taggedWorker("child2", "b")
+		child3 := taggedWorker("child3", "a")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1, child2, child3),
+			[]cap.Opt{
+				cap.WithRestartStrategy(groupStrategy{}),
+			},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				failWorker1(true /* done */)
+				evIt.WaitTill(WorkerStarted("root/child3"))
+			},
+		)
+
+		assert.NoError(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				WorkerStarted("root/child3"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child1"),
+				WorkerTerminated("root/child3"),
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child3"),
+				WorkerTerminated("root/child3"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+
+	t.Run("built-in strategies as a RestartStrategy", func(t *testing.T) {
+		child1 := WaitDoneWorker("child1")
+		child2, failWorker2 := FailOnSignalWorker(1, "child2")
+		child3 := WaitDoneWorker("child3")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1, child2, child3),
+			[]cap.Opt{
+				cap.WithRestartStrategy(cap.RestForOne),
+			},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				failWorker2(true /* done */)
+				evIt.WaitTill(WorkerStarted("root/child3"))
+			},
+		)
+
+		assert.NoError(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				WorkerStarted("root/child3"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child2"),
+				WorkerTerminated("root/child3"),
+				WorkerStarted("root/child2"),
+				WorkerStarted("root/child3"),
+				WorkerTerminated("root/child3"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+
+	t.Run("OneForAll as a RestartStrategy reports group restarts", func(t *testing.T) {
+		child1 := WaitDoneWorker("child1")
+		child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(child1, child2),
+			[]cap.Opt{
+				cap.WithRestartStrategy(cap.OneForAll),
+			},
+			func(em EventManager) {
+				evIt := em.Iterator()
+				evIt.WaitTill(SupervisorStarted("root"))
+				failWorker2(true /* done */)
+				evIt.WaitTill(SupervisorGroupRestarted("root", "root/child2"))
+			},
+		)
+
+		assert.NoError(t, err)
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				SupervisorStarted("root"),
+				WorkerFailed("root/child2"),
+				WorkerTerminated("root/child1"),
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				SupervisorGroupRestarted("root", "root/child2"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				SupervisorTerminated("root"),
+			},
+		)
+	})
+}
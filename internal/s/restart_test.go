@@ -0,0 +1,158 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestDynSupervisorRestart(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	var discardedEv cap.Event
+	notifier := evManager.EventCollector(ctx)
+
+	dyn, err := cap.NewDynSupervisorWithNodes(
+		ctx,
+		"root",
+		cap.WithNodes(WaitDoneWorker("static1"), WaitDoneWorker("static2")),
+		cap.WithNotifier(func(ev cap.Event) {
+			if ev.GetTag() == cap.DynChildrenDiscarded {
+				discardedEv = ev
+			}
+			notifier(ev)
+		}),
+	)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	_, err = dyn.Spawn(WaitDoneWorker("dyn1"))
+	assert.NoError(t, err)
+	_, err = dyn.Spawn(WaitDoneWorker("dyn2"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dyn.Restart())
+	evIt.WaitTill(WorkerStarted("root/static2"))
+
+	assert.Equal(t, []string{"root/dyn1", "root/dyn2"}, discardedEv.GetDiscardedChildren())
+
+	assert.NoError(t, dyn.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/static1"),
+			WorkerStarted("root/static2"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/dyn1"),
+			WorkerStarted("root/dyn2"),
+			// restart terminates all children in reverse start order
+			WorkerTerminated("root/dyn2"),
+			WorkerTerminated("root/dyn1"),
+			WorkerTerminated("root/static2"),
+			WorkerTerminated("root/static1"),
+			DynChildrenDiscarded("root"),
+			// only the static children come back
+			WorkerStarted("root/static1"),
+			WorkerStarted("root/static2"),
+			// dynamic children are gone on termination
+			WorkerTerminated("root/static2"),
+			WorkerTerminated("root/static1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestSupervisorRestartTerminated(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.NoError(t, sup.Terminate())
+
+	assert.Error(t, sup.Restart())
+}
+
+func TestSupervisorRestartRebuildsFromSpec(t *testing.T) {
+	var builds, cleanups int32
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		func() ([]cap.Node, cap.CleanupResourcesFn, error) {
+			atomic.AddInt32(&builds, 1)
+			cleanup := func() error {
+				atomic.AddInt32(&cleanups, 1)
+				return nil
+			}
+			return []cap.Node{WaitDoneWorker("child1")}, cleanup, nil
+		},
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.NoError(t, sup.Restart())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&builds))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cleanups))
+
+	assert.NoError(t, sup.Terminate())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&cleanups))
+}
+
+func TestSupervisorRestartStartFailure(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	var builds int32
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		func() ([]cap.Node, cap.CleanupResourcesFn, error) {
+			// the children cannot start after a restart
+			if atomic.AddInt32(&builds, 1) > 1 {
+				return []cap.Node{FailStartWorker("child1")}, nil, nil
+			}
+			return []cap.Node{WaitDoneWorker("child1")}, nil, nil
+		},
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	assert.Error(t, sup.Restart())
+
+	// the supervisor fails instead of running without children
+	err = sup.Wait()
+	var restartErr *cap.SupervisorRestartError
+	assert.True(t, errors.As(err, &restartErr))
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child1"),
+			WorkerStartFailed("root/child1"),
+			SupervisorFailed("root"),
+		},
+	)
+}
@@ -2,6 +2,8 @@ package s
 
 import (
 	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
 )
 
 // restartToleranceResult indicates the result of a error tolerance check
@@ -35,18 +37,23 @@ type restartTolerance struct {
 	RestartWindow   time.Duration
 }
 
-func (rt restartTolerance) isWithinRestartWindow(createdAt time.Time) bool {
+func (rt restartTolerance) isWithinRestartWindow(createdAt, now time.Time) bool {
 	// when errWindow is 0, it means we never forget errors happened
-	return time.Since(createdAt) < rt.RestartWindow || rt.RestartWindow == 0
+	return now.Sub(createdAt) < rt.RestartWindow || rt.RestartWindow == 0
 }
 
 func (rt restartTolerance) didSurpassMaxRestartCount(restartCount uint32) bool {
 	return rt.MaxRestartCount < restartCount
 }
 
-// check verifies if the error tolerance has been reached with the given input values
-func (rt restartTolerance) check(restartCount uint32, createdAt time.Time) restartToleranceResult {
-	if createdAt == (time.Time{}) || rt.isWithinRestartWindow(createdAt) {
+// check verifies if the error tolerance has been reached at the given time with
+// the given input values
+func (rt restartTolerance) check(
+	restartCount uint32,
+	createdAt time.Time,
+	now time.Time,
+) restartToleranceResult {
+	if createdAt == (time.Time{}) || rt.isWithinRestartWindow(createdAt, now) {
 		if rt.MaxRestartCount == 0 || rt.didSurpassMaxRestartCount(restartCount+1) {
 			return restartToleranceSurpassed
 		}
@@ -54,3 +61,43 @@ func (rt restartTolerance) check(restartCount uint32, createdAt time.Time) resta
 	}
 	return resetRestartCount
 }
+
+// registerChildRestart keeps track of the restarts each child contributes to
+// the restart window, when reset is true the window was started again
+func (mgr *restartToleranceManager) registerChildRestart(
+	chName string, reset bool, weight uint32,
+) {
+	if mgr.healDuration == 0 {
+		return
+	}
+	if reset || mgr.childRestarts == nil {
+		mgr.childRestarts = make(map[string]uint32)
+	}
+	mgr.childRestarts[chName] += weight
+}
+
+// heal forgets the restarts contributed to the restart window by the children
+// that have been running for the heal duration of the supervisor (see
+// WithHealDuration). The running time of a child that failed is the time it
+// was running before its failure.
+func (mgr *restartToleranceManager) heal(supChildren map[string]c.Child, now time.Time) {
+	if mgr.healDuration == 0 {
+		return
+	}
+	for chName, contribution := range mgr.childRestarts {
+		ch, ok := supChildren[chName]
+		if !ok || now.Sub(ch.GetCreatedAt()) < mgr.healDuration {
+			continue
+		}
+		if contribution > mgr.restartCount {
+			contribution = mgr.restartCount
+		}
+		mgr.restartCount -= contribution
+		delete(mgr.childRestarts, chName)
+	}
+	if mgr.restartCount == 0 {
+		// every contribution was forgiven, the next failure starts a new
+		// restart window
+		mgr.reset()
+	}
+}
@@ -52,7 +52,7 @@ func TestErrTolerance(t *testing.T) {
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			et := restartTolerance{MaxRestartCount: tc.maxErrCount, RestartWindow: tc.errWindow}
-			result := et.check(tc.errCount, tc.createdAt)
+			result := et.check(tc.errCount, tc.createdAt, time.Now())
 			require.True(t, tc.result == result, result.String())
 		})
 	}
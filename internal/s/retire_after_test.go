@@ -0,0 +1,63 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRetireAfter(t *testing.T) {
+	retireAt := time.Now().Add(100 * time.Millisecond)
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithRetireAfter(retireAt),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			// before the retirement time, the child is restarted
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			time.Sleep(time.Until(retireAt) + 10*time.Millisecond)
+
+			// after the retirement time, the child stays down
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerRetired("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerRetired("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -31,11 +31,23 @@ func buildRuntimeName(spec SupervisorSpec, parentName string) string {
 		// We are the root supervisor, no need to add prefix
 		runtimeName = spec.GetName()
 	} else {
-		runtimeName = strings.Join([]string{parentName, spec.GetName()}, "/")
+		runtimeName = strings.Join(
+			[]string{parentName, spec.GetName()},
+			spec.getNameSeparator(),
+		)
 	}
 	return runtimeName
 }
 
+// getNameSeparator returns the token used to build the runtime names of the
+// supervision tree
+func (spec SupervisorSpec) getNameSeparator() string {
+	if spec.nameSeparator == "" {
+		return NodeSepToken
+	}
+	return spec.nameSeparator
+}
+
 type capatazSupKey string
 
 var eventNotifierKey capatazSupKey = "__capataz.node.event_notifier__"
@@ -46,6 +58,36 @@ func withEventNotifier(ctx context.Context, evNotifier EventNotifier) context.Co
 	return context.WithValue(ctx, eventNotifierKey, evNotifier)
 }
 
+// withLogger sets the Logger of this supervisor in the given context, so that
+// the children loggers are derived from it. When the supervisor has no Logger,
+// the one inherited from the parent supervisor is kept.
+func (spec SupervisorSpec) withLogger(ctx context.Context) context.Context {
+	if spec.logger == nil {
+		return ctx
+	}
+	return c.WithRootLogger(ctx, spec.logger)
+}
+
+// withPanicRecovery sets in the given context if the children of this
+// supervisor recover from panics. When the supervisor doesn't specify it, the
+// setting inherited from the parent supervisor is kept.
+func (spec SupervisorSpec) withPanicRecovery(ctx context.Context) context.Context {
+	if spec.panicRecovery == nil {
+		return ctx
+	}
+	return c.WithPanicRecovery(ctx, *spec.panicRecovery)
+}
+
+// withSeed sets the seed of the random sources of the supervision tree in the
+// given context; when the supervisor doesn't specify one, the sources of the
+// nodes are seeded with the current time.
+func (spec SupervisorSpec) withSeed(ctx context.Context) context.Context {
+	if spec.seed == nil {
+		return ctx
+	}
+	return c.WithRootSeed(ctx, *spec.seed)
+}
+
 // rootStart is routine that contains the main logic of a Supervisor. This
 // function:
 //
@@ -78,16 +120,57 @@ func (spec SupervisorSpec) rootStart(
 
 	supRuntimeName := buildRuntimeName(spec, parentName)
 
+	// history retains the events of all the sub-trees of this supervisor
+	history := newEventHistory(spec)
+	if history != nil {
+		spec.eventNotifier = history.record(spec.getEventNotifier())
+	}
+
+	// subscriptions receive the events of all the sub-trees of this supervisor
+	subscriptions := newEventSubscriptions(supRuntimeName)
+	spec.eventNotifier = subscriptions.dispatch(spec.getEventNotifier())
+
 	eventNotifier := spec.getEventNotifier()
 	supCtx = withEventNotifier(supCtx, eventNotifier)
 
+	// stats is shared with all the sub-trees of this supervisor
+	stats := &restartStats{}
+	supCtx = withRestartStats(supCtx, stats)
+
+	// deadline is shared with all the sub-trees of this supervisor
+	deadline := &terminationDeadline{}
+	supCtx = withTerminationDeadline(supCtx, deadline)
+
+	// abandoned children are tracked for all the sub-trees of this supervisor
+	abandoned := &abandonedChildren{}
+	supCtx = withAbandonedChildren(supCtx, abandoned)
+
+	// notifications are streamed from all the sub-trees of this supervisor
+	notifications := newNotificationStream(spec)
+	supCtx = withNotificationStream(supCtx, notifications)
+
+	supCtx = spec.withLogger(supCtx)
+	supCtx = spec.withPanicRecovery(supCtx)
+
+	// the separator of the root supervisor is used across all the sub-trees
+	supCtx = c.WithNodeSeparator(supCtx, spec.getNameSeparator())
+
+	// the seed of the root supervisor is used across all the sub-trees
+	supCtx = spec.withSeed(supCtx)
+
+	// the creation time and the timers of children are measured with the
+	// supervisor clock
+	supCtx = c.WithClockNow(supCtx, spec.getClock().Now)
+	supCtx = c.WithClockAfter(supCtx, spec.getClock().After)
+
 	// Build childrenSpec and resource cleanup
-	childrenSpecs, supRscCleanup, rscAllocError := spec.buildChildrenSpecs(supRuntimeName)
+	childrenSpecs, supRscCleanup, rscAllocError := spec.buildChildrenSpecs(supCtx, supRuntimeName)
 
 	// Do not even start the monitor loop if we find an error on the resource
 	// allocation logic
 	if rscAllocError != nil {
 		cancelFn()
+		notifications.close()
 		eventNotifier.supervisorStartFailed(supRuntimeName, rscAllocError)
 		return Supervisor{}, rscAllocError
 	}
@@ -101,6 +184,15 @@ func (spec SupervisorSpec) rootStart(
 
 		terminateCh:      terminateCh,
 		terminateManager: tm,
+		restartStats:     stats,
+
+		terminationDeadline: deadline,
+		notifications:       notifications,
+		eventHistory:        history,
+		subscriptions:       subscriptions,
+		abandonedChildren:   abandoned,
+		readyCh:             make(chan struct{}),
+		doneCh:              make(chan struct{}),
 
 		spec:     spec,
 		children: make(map[string]c.Child, len(childrenSpecs)),
@@ -122,6 +214,7 @@ func (spec SupervisorSpec) rootStart(
 			_, supErr := getCrashError(
 				true, /* block */
 				eventNotifier,
+				spec.getClock(),
 				supRuntimeName,
 				terminateCh,
 				tm,
@@ -139,6 +232,9 @@ func (spec SupervisorSpec) rootStart(
 	onStart := func(err startNodeError) {
 		if err != nil {
 			startCh <- err
+		} else {
+			// every child acknowledged its start at this point
+			close(sup.readyCh)
 		}
 		close(startCh)
 	}
@@ -151,13 +247,21 @@ func (spec SupervisorSpec) rootStart(
 		close(terminateCh)
 	}
 
-	supTolerance := &restartToleranceManager{restartTolerance: spec.restartTolerance}
+	supTolerance := &restartToleranceManager{
+		restartTolerance: spec.restartTolerance,
+		clock:            spec.getClock(),
+		healDuration:     spec.healDuration,
+	}
 
 	// spawn goroutine with supervisor monitorLoop
 	go func() {
 		// NOTE: we ignore the returned error as that is being handled by the
 		// onStart and onTerminate callbacks
-		startTime := time.Now()
+		defer notifications.close()
+		// clients waiting on a control message result stop waiting once the
+		// supervisor goroutine is gone
+		defer close(sup.doneCh)
+		startTime := spec.getClock().Now()
 		_ = runMonitorLoop(
 			supCtx,
 			spec,
@@ -183,7 +287,7 @@ func (spec SupervisorSpec) rootStart(
 	if startErr != nil {
 		// Let's wait for the supervisor to stop all children before returning the
 		// final error
-		stopingTime := time.Now()
+		stopingTime := spec.getClock().Now()
 		_ /* err */ = sup.wait(stopingTime, startErr)
 
 		return Supervisor{}, startErr
@@ -0,0 +1,78 @@
+package s
+
+// This file contains the implementation of the Run and RunWithSignals APIs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Run starts a supervision tree with the given spec, and blocks the current
+// goroutine until the tree terminates. It is meant to be the last call of a
+// program's main function.
+//
+// Cancelling the given context terminates the supervision tree gracefully:
+// children are stopped in the reverse order of their start order, and Run
+// returns nil when every child honored its Shutdown setting. Run returns an
+// error when the tree fails to start, when the tree terminates because its
+// restart tolerance was surpassed, or when the termination of a child failed.
+func Run(ctx context.Context, spec SupervisorSpec) error {
+	sup, err := spec.Start(ctx)
+	if err != nil {
+		return err
+	}
+	// the supervisor's context is derived from ctx, a cancellation of ctx
+	// triggers the same termination as a call to Terminate
+	return sup.Wait()
+}
+
+// RunWithSignals behaves like Run, with the difference that the supervision
+// tree terminates gracefully when the program receives any of the given OS
+// signals. When no signals are given, os.Interrupt and syscall.SIGTERM are
+// used.
+//
+// A second signal forces the termination: supervisors stop waiting for the
+// children that did not terminate yet (like TerminateWithTimeout does when its
+// timeout expires), and RunWithSignals returns right away with an error that
+// matches ErrForcedTermination. The goroutines of the abandoned children may
+// still be running when this function returns.
+func RunWithSignals(spec SupervisorSpec, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	sup, err := spec.Start(context.Background())
+	if err != nil {
+		return err
+	}
+
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- sup.Wait()
+	}()
+
+	select {
+	case err = <-waitCh:
+		return err
+	case <-sigCh:
+		// graceful termination, children honor their Shutdown settings
+		sup.cancel()
+	}
+
+	select {
+	case err = <-waitCh:
+		return err
+	case sig := <-sigCh:
+		// forced termination, do not wait for the remaining children
+		sup.terminationDeadline.set(time.Now())
+		return fmt.Errorf("%w: received signal %v", ErrForcedTermination, sig)
+	}
+}
@@ -0,0 +1,157 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestRunCancelledContext(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+
+	evManager := NewEventManager()
+	evManager.StartCollector(context.TODO())
+	spec := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), WaitDoneWorker("child2")),
+		cap.WithNotifier(evManager.EventCollector(context.TODO())),
+	)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- cap.Run(ctx, spec)
+	}()
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+	cancelFn()
+
+	// a cancelled context is a clean termination
+	assert.NoError(t, <-errCh)
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestRunFatalError(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(2, "child1")
+
+	spec := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(child1),
+		cap.WithRestartTolerance(1, 5*time.Second),
+	)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- cap.Run(context.TODO(), spec)
+	}()
+
+	failWorker1(false /* done */)
+	failWorker1(false /* done */)
+
+	err := <-errCh
+	var toleranceErr *cap.RestartToleranceReached
+	assert.True(t, errors.As(err, &toleranceErr))
+}
+
+func TestRunStartError(t *testing.T) {
+	spec := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(FailStartWorker("child1")),
+	)
+	assert.Error(t, cap.Run(context.TODO(), spec))
+}
+
+func TestWaitConcurrentWithTerminate(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root", cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	waitCh := make(chan error)
+	go func() {
+		waitCh <- sup.Wait()
+	}()
+
+	assert.NoError(t, sup.Terminate())
+	assert.NoError(t, <-waitCh)
+}
+
+// slowShutdownWorker creates a worker that takes the given duration to
+// terminate after its supervisor cancels it; the returned channel is closed
+// once the worker started
+func slowShutdownWorker(name string, d time.Duration) (cap.Node, <-chan struct{}) {
+	startedCh := make(chan struct{})
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			close(startedCh)
+			<-ctx.Done()
+			time.Sleep(d)
+			return nil
+		},
+		cap.WithShutdown(cap.Timeout(d*2)),
+	), startedCh
+}
+
+func sendSignal(t *testing.T, sig os.Signal) {
+	proc, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, proc.Signal(sig))
+}
+
+func TestRunWithSignalsGraceful(t *testing.T) {
+	child1, startedCh := slowShutdownWorker("child1", 50*time.Millisecond)
+	spec := cap.NewSupervisorSpec("root", cap.WithNodes(child1))
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- cap.RunWithSignals(spec, syscall.SIGHUP)
+	}()
+
+	<-startedCh
+	sendSignal(t, syscall.SIGHUP)
+
+	assert.NoError(t, <-errCh)
+}
+
+func TestRunWithSignalsForced(t *testing.T) {
+	child1, startedCh := slowShutdownWorker("child1", 5*time.Second)
+	spec := cap.NewSupervisorSpec("root", cap.WithNodes(child1))
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- cap.RunWithSignals(spec, syscall.SIGHUP)
+	}()
+
+	<-startedCh
+	sendSignal(t, syscall.SIGHUP)
+	// give some room to handle the first signal, a signal that is sent before
+	// the previous one was read is discarded
+	time.Sleep(50 * time.Millisecond)
+	sendSignal(t, syscall.SIGHUP)
+
+	select {
+	case err := <-errCh:
+		assert.True(t, errors.Is(err, cap.ErrForcedTermination))
+	case <-time.After(time.Second):
+		t.Fatal("RunWithSignals didn't return after the second signal")
+	}
+}
@@ -0,0 +1,66 @@
+package s_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+)
+
+// drawValues runs a supervision tree with the given options, and returns the
+// first value each worker draws from its random source, keyed by worker name
+func drawValues(t *testing.T, opts ...cap.Opt) map[string]int64 {
+	var mu sync.Mutex
+	values := make(map[string]int64)
+
+	randWorker := func(name string) cap.Node {
+		return cap.NewWorkerWithNotifyStart(
+			name,
+			func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+				value := cap.RandFromContext(ctx).Int63()
+				mu.Lock()
+				values[name] = value
+				mu.Unlock()
+				notifyStart(nil)
+				<-ctx.Done()
+				return nil
+			},
+		)
+	}
+
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(randWorker("child2")))
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(randWorker("child1"), cap.Subtree(subtree)),
+		opts...,
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.NoError(t, sup.Terminate())
+
+	mu.Lock()
+	defer mu.Unlock()
+	return values
+}
+
+func TestSeed(t *testing.T) {
+	t.Run("the same seed draws the same values", func(t *testing.T) {
+		first := drawValues(t, cap.WithSeed(42))
+		second := drawValues(t, cap.WithSeed(42))
+
+		assert.Len(t, first, 2)
+		assert.Equal(t, first, second)
+		// every node draws from its own source
+		assert.NotEqual(t, first["child1"], first["child2"])
+	})
+
+	t.Run("different seeds draw different values", func(t *testing.T) {
+		first := drawValues(t, cap.WithSeed(42))
+		second := drawValues(t, cap.WithSeed(43))
+
+		assert.NotEqual(t, first["child1"], second["child1"])
+		assert.NotEqual(t, first["child2"], second["child2"])
+	})
+}
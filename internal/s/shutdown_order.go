@@ -0,0 +1,65 @@
+package s
+
+// This file contains the logic for the termination order declared with
+// WithShutdownOrder
+
+import (
+	"fmt"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// sortTermination returns children sorted for the supervisor stop. The
+// children listed with WithShutdownOrder are stopped first, in the given
+// order; the rest of the children are stopped in the reverse of the start
+// order.
+func (spec SupervisorSpec) sortTermination(input []c.ChildSpec) []c.ChildSpec {
+	sorted := spec.order.sortTermination(input)
+	if len(spec.shutdownOrder) == 0 {
+		return sorted
+	}
+
+	byName := make(map[string]c.ChildSpec, len(sorted))
+	for _, chSpec := range sorted {
+		byName[chSpec.GetName()] = chSpec
+	}
+
+	output := make([]c.ChildSpec, 0, len(sorted))
+	listed := make(map[string]struct{}, len(spec.shutdownOrder))
+	for _, name := range spec.shutdownOrder {
+		// the given children may be a subset of the supervisor children (e.g.
+		// on a restart)
+		if chSpec, ok := byName[name]; ok {
+			output = append(output, chSpec)
+			listed[name] = struct{}{}
+		}
+	}
+	for _, chSpec := range sorted {
+		if _, ok := listed[chSpec.GetName()]; !ok {
+			output = append(output, chSpec)
+		}
+	}
+	return output
+}
+
+// validateShutdownOrder checks that every name given to WithShutdownOrder
+// belongs to one of the given children, and that no name is listed twice.
+func validateShutdownOrder(shutdownOrder []string, children []c.ChildSpec) error {
+	names := make(map[string]struct{}, len(children))
+	for _, chSpec := range children {
+		names[chSpec.GetName()] = struct{}{}
+	}
+
+	listed := make(map[string]struct{}, len(shutdownOrder))
+	for _, name := range shutdownOrder {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("shutdown order references '%s', which is not a child", name)
+		}
+		if _, ok := listed[name]; ok {
+			return fmt.Errorf("shutdown order lists child '%s' more than once", name)
+		}
+		listed[name] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child2"),
+			WaitDoneWorker("child3"),
+			WaitDoneWorker("child4"),
+		),
+		[]cap.Opt{
+			cap.WithShutdownOrder([]string{"child2", "child1"}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child3"),
+			WorkerStarted("root/child4"),
+			SupervisorStarted("root"),
+			// listed children first, then the rest in reverse start order
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child3"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestShutdownOrderUnknownChild(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), WaitDoneWorker("child2")),
+		[]cap.Opt{
+			cap.WithShutdownOrder([]string{"child2", "chlid1"}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+
+	var buildErr *cap.SupervisorBuildError
+	assert.True(t, errors.As(err, &buildErr))
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> shutdown order references 'chlid1', which is not a child",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
@@ -0,0 +1,152 @@
+package s
+
+// This file contains the implementation of the supervision tree snapshots
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// TreeSnapshot represents the state of a node of a running supervision tree at
+// some point in time. When the node is a supervisor, the snapshot contains the
+// snapshots of its running children, and of the children that wait for their
+// lazy start trigger.
+type TreeSnapshot struct {
+	name        string
+	runtimeName string
+	tag         c.ChildTag
+	lazyPending bool
+	children    []TreeSnapshot
+}
+
+// GetName returns the spec name of the node
+func (ts TreeSnapshot) GetName() string {
+	return ts.name
+}
+
+// GetRuntimeName returns the runtime name of the node
+func (ts TreeSnapshot) GetRuntimeName() string {
+	return ts.runtimeName
+}
+
+// GetTag returns the c.ChildTag of the node
+func (ts TreeSnapshot) GetTag() c.ChildTag {
+	return ts.tag
+}
+
+// IsLazyPending returns true when the node is registered on its supervisor,
+// but it is not running because its lazy start trigger didn't fire yet (see
+// WithLazyStart)
+func (ts TreeSnapshot) IsLazyPending() bool {
+	return ts.lazyPending
+}
+
+// GetChildren returns the snapshots of the running children of the node, in
+// start order
+func (ts TreeSnapshot) GetChildren() []TreeSnapshot {
+	return ts.children
+}
+
+// CountNodes returns the number of nodes in this snapshot, including this node
+func (ts TreeSnapshot) CountNodes() int {
+	count := 1
+	for _, child := range ts.children {
+		count += child.CountNodes()
+	}
+	return count
+}
+
+// treeSnapshotJSON is the JSON representation of a TreeSnapshot
+type treeSnapshotJSON struct {
+	Name        string         `json:"name"`
+	RuntimeName string         `json:"runtime_name"`
+	Tag         string         `json:"tag"`
+	LazyPending bool           `json:"lazy_pending,omitempty"`
+	Children    []TreeSnapshot `json:"children,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of the snapshot
+func (ts TreeSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(treeSnapshotJSON{
+		Name:        ts.name,
+		RuntimeName: ts.runtimeName,
+		Tag:         ts.tag.String(),
+		LazyPending: ts.lazyPending,
+		Children:    ts.children,
+	})
+}
+
+// snapshotChildren returns the snapshots of the given children, sub-trees
+// that are not reachable (e.g. they are restarting) are reported without
+// children
+func snapshotChildren(trees []childTree) []TreeSnapshot {
+	snapshots := make([]TreeSnapshot, 0, len(trees))
+	for _, tree := range trees {
+		snapshots = append(snapshots, TreeSnapshot{
+			name:        tree.spec.GetName(),
+			runtimeName: tree.runtimeName,
+			tag:         tree.spec.GetTag(),
+			lazyPending: tree.lazyPending,
+			children:    snapshotChildren(tree.children),
+		})
+	}
+	return snapshots
+}
+
+// Snapshot returns a TreeSnapshot of the running supervision tree. It fails
+// when the supervisor is not running, or when the given context is done before
+// the snapshot is complete.
+func (sup Supervisor) Snapshot(ctx context.Context) (TreeSnapshot, error) {
+	trees, err := walkChildren(ctx, sup.ctrlCh)
+	if err != nil {
+		return TreeSnapshot{}, err
+	}
+	return TreeSnapshot{
+		name:        sup.GetName(),
+		runtimeName: sup.runtimeName,
+		tag:         c.Supervisor,
+		children:    snapshotChildren(trees),
+	}, nil
+}
+
+// withoutLazyPending returns a copy of the snapshot without the nodes that are
+// waiting for their lazy start trigger
+func (ts TreeSnapshot) withoutLazyPending() TreeSnapshot {
+	children := make([]TreeSnapshot, 0, len(ts.children))
+	for _, child := range ts.children {
+		if !child.lazyPending {
+			children = append(children, child.withoutLazyPending())
+		}
+	}
+	ts.children = children
+	return ts
+}
+
+// ActiveChildCount returns the number of children that are running on this
+// supervisor; when recursive is true, the children of its sub-trees (at any
+// depth) are accounted as well. Sub-trees count as children of their parent
+// supervisor, and children waiting for their lazy start trigger are not
+// accounted.
+//
+// This function returns zero once the supervisor is terminated, so that it can
+// be used (e.g. after Wait) to assert a supervision tree leaves no running
+// children behind. It also returns zero when the supervisor doesn't reply
+// within a second (e.g. because it is in the middle of a restart).
+func (sup Supervisor) ActiveChildCount(recursive bool) int {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	snapshot, err := sup.Snapshot(ctx)
+	if err != nil {
+		return 0
+	}
+	snapshot = snapshot.withoutLazyPending()
+	if !recursive {
+		return len(snapshot.children)
+	}
+	// the supervisor is not one of its children
+	return snapshot.CountNodes() - 1
+}
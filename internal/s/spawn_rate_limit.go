@@ -0,0 +1,42 @@
+package s
+
+// This file contains the implementation of the rate limit of spawned children
+// (see WithSpawnRateLimit)
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// spawnRateLimit contains the settings of WithSpawnRateLimit
+type spawnRateLimit struct {
+	limit rate.Limit
+	burst int
+}
+
+// newSpawnLimiter creates the limiter of the children spawned on a supervisor
+// with this spec, nil when the spec doesn't have a spawn rate limit
+func (spec SupervisorSpec) newSpawnLimiter() *rate.Limiter {
+	if spec.spawnRateLimit == nil {
+		return nil
+	}
+	return rate.NewLimiter(spec.spawnRateLimit.limit, spec.spawnRateLimit.burst)
+}
+
+// waitSpawnLimiter blocks until the given limiter allows a new spawn, or until
+// the given context is done
+func waitSpawnLimiter(ctx context.Context, limiter *rate.Limiter) error {
+	if limiter == nil {
+		return nil
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return c.WrapSentinel(
+			ErrSpawnRateLimited, err,
+			"spawn cancelled while waiting for the spawn rate limit: %v", err,
+		)
+	}
+	return nil
+}
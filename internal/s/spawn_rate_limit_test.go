@@ -0,0 +1,83 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestSpawnRateLimitThrottles(t *testing.T) {
+	dyn, err := cap.NewDynSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithSpawnRateLimit(rate.Every(50*time.Millisecond), 1),
+	)
+	assert.NoError(t, err)
+
+	startTime := time.Now()
+	for _, name := range []string{"child1", "child2", "child3"} {
+		_, err := dyn.Spawn(WaitDoneWorker(name))
+		assert.NoError(t, err)
+	}
+	// the first spawn uses the burst, the other two wait for a token
+	assert.True(t, time.Since(startTime) >= 90*time.Millisecond)
+
+	assert.NoError(t, dyn.Terminate())
+}
+
+func TestSpawnRateLimitContextDone(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancelFn()
+
+	dyn, err := cap.NewDynSupervisor(
+		ctx,
+		"root",
+		cap.WithSpawnRateLimit(rate.Every(time.Hour), 1),
+	)
+	assert.NoError(t, err)
+
+	_, err = dyn.Spawn(WaitDoneWorker("child1"))
+	assert.NoError(t, err)
+
+	// the next token is not available before the context deadline
+	_, err = dyn.Spawn(WaitDoneWorker("child2"))
+	assert.True(t, errors.Is(err, cap.ErrSpawnRateLimited))
+
+	assert.NoError(t, dyn.Terminate())
+}
+
+func TestSpawnRateLimitDynSubtree(t *testing.T) {
+	spawnedCh := make(chan struct{})
+	spawnErrCh := make(chan error, 1)
+
+	subtree := cap.NewDynSubtree(
+		"dyn",
+		func(ctx context.Context, spawner cap.Spawner) error {
+			if _, err := spawner.Spawn(WaitDoneWorker("child1")); err != nil {
+				return err
+			}
+			close(spawnedCh)
+			// blocks until the spawner is terminated
+			_, err := spawner.Spawn(WaitDoneWorker("child2"))
+			spawnErrCh <- err
+			<-ctx.Done()
+			return nil
+		},
+		[]cap.Opt{cap.WithSpawnRateLimit(rate.Every(time.Hour), 1)},
+	)
+
+	sup, err := cap.NewSupervisorSpec("root", cap.WithNodes(subtree)).Start(context.TODO())
+	assert.NoError(t, err)
+
+	<-spawnedCh
+	assert.NoError(t, sup.Terminate())
+
+	assert.True(t, errors.Is(<-spawnErrCh, cap.ErrSpawnRateLimited))
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/capatazlib/go-capataz/internal/c"
@@ -24,36 +26,38 @@ const (
 	RightToLeft
 )
 
-// sortStart returns children sorted for the supervisor start
+// sortStart returns children sorted for the supervisor start; children are
+// sorted by start phase first (see WithStartPhase), and by this Order within a
+// phase. Children always start after the siblings they depend on (see
+// WithDependsOn).
 func (o Order) sortStart(input0 []c.ChildSpec) []c.ChildSpec {
 	input := append(input0[:0:0], input0...)
 	switch o {
 	case LeftToRight:
-		return input
 	case RightToLeft:
 		for i, j := 0, len(input)-1; i < j; i, j = i+1, j-1 {
 			input[i], input[j] = input[j], input[i]
 		}
-		return input
 	default:
 		panic("Invalid cap.Order value")
 	}
+	sort.SliceStable(input, func(i, j int) bool {
+		if input[i].GetStartPhase() != input[j].GetStartPhase() {
+			return input[i].GetStartPhase() < input[j].GetStartPhase()
+		}
+		return input[i].GetStartPriority() > input[j].GetStartPriority()
+	})
+	return sortByDependencies(input)
 }
 
-// sortTermination returns children sorted for the supervisor stop
+// sortTermination returns children sorted for the supervisor stop, which is the
+// reverse of the start order
 func (o Order) sortTermination(input0 []c.ChildSpec) []c.ChildSpec {
-	input := append(input0[:0:0], input0...)
-	switch o {
-	case LeftToRight:
-		for i, j := 0, len(input)-1; i < j; i, j = i+1, j-1 {
-			input[i], input[j] = input[j], input[i]
-		}
-		return input
-	case RightToLeft:
-		return input
-	default:
-		panic("Invalid cap.Order value")
+	input := o.sortStart(input0)
+	for i, j := 0, len(input)-1; i < j; i, j = i+1, j-1 {
+		input[i], input[j] = input[j], input[i]
 	}
+	return input
 }
 
 // Strategy specifies how children get restarted when one of them reports an
@@ -67,7 +71,9 @@ const (
 	// OneForAll is an Strategy that tells the Supervisor to restart all the
 	// siblings of a failed child process
 	OneForAll
-	// RestForOne
+	// RestForOne is an Strategy that tells the Supervisor to restart the failed
+	// child process and all the siblings that were started after it
+	RestForOne
 )
 
 // getEventNotifier returns the configured EventNotifier or emptyEventNotifier
@@ -109,6 +115,38 @@ type SupervisorSpec struct {
 	strategy         Strategy
 	shutdownTimeout  time.Duration
 	eventNotifier    EventNotifier
+
+	startupConcurrency int
+	restartDampening   time.Duration
+	resources          []ResourceSpec
+	setup              func(context.Context) (CleanupResourcesFn, error)
+	cleanup            func() error
+	clock              Clock
+	deadLetter         func(string, error)
+	logger             c.Logger
+	nameSeparator      string
+	seed               *int64
+	panicRecovery      *bool
+	childDefaults      []c.Opt
+	notifications      *notificationSettings
+	shutdownOrder      []string
+	groupRestartsOnly  bool
+	spawnRateLimit     *spawnRateLimit
+	cleanExitAction    *CleanExitAction
+	escalationPolicy   *EscalationPolicy
+	eventHistory       int
+	watchdog           *watchdogSettings
+	restartConcurrency int
+	healDuration       time.Duration
+	restartStrategy    RestartStrategy
+	startMiddleware    []StartMiddleware
+
+	terminationDeadline *terminationDeadline
+	workerPools         *workerPools
+	stallWatchdog       *stallWatchdog
+	childAliases        childAliases
+	overlapChildren     overlapChildren
+	abandonedChildren   *abandonedChildren
 }
 
 // reliableBuildNodes capture panics returned from the buildNodes client
@@ -130,21 +168,161 @@ func reliableBuildNodes(
 	return
 }
 
+// buildChildSpec builds the given node with the child defaults and the start
+// middleware of the supervisor
+func (spec SupervisorSpec) buildChildSpec(node Node) c.ChildSpec {
+	chSpec := node(spec).ApplyDefaults(spec.childDefaults)
+	return spec.applyStartMiddleware(chSpec)
+}
+
 // buildChildren constructs the childSpec records that the Supervisor is going
-// to monitor at runtime.
+// to monitor at runtime. The resources declared with WithResources are acquired
+// and the WithSetup function is executed before the children get built; they
+// are released in reverse order after the returned cleanup function runs the
+// cleanup of the BuildNodesFn function.
 func (spec SupervisorSpec) buildChildrenSpecs(
+	ctx context.Context,
 	supRuntimeName string,
 ) ([]c.ChildSpec, CleanupResourcesFn, error) {
+	releaseResources, err := spec.acquireResources(ctx)
+	if err != nil {
+		return []c.ChildSpec{}, nil, err
+	}
+
+	setupCleanup, err := spec.runSetup(ctx, supRuntimeName)
+	if err != nil {
+		_ = releaseResources()
+		return []c.ChildSpec{}, nil, err
+	}
+
 	nodes, cleanup, err := reliableBuildNodes(supRuntimeName, spec)
 	if err != nil {
+		_ = setupCleanup()
+		_ = releaseResources()
 		return []c.ChildSpec{}, cleanup, err
 	}
 
 	children := make([]c.ChildSpec, 0, len(nodes))
-	for _, buildChildSpec := range nodes {
-		children = append(children, buildChildSpec(spec))
+	for _, node := range nodes {
+		chSpec := spec.buildChildSpec(node)
+		if isPoolRegistration(chSpec) {
+			continue
+		}
+		children = append(children, chSpec)
+	}
+
+	err = validateChildSpecs(children)
+	if err == nil {
+		err = validateDependencies(children)
+	}
+	if err == nil {
+		err = validateShutdownOrder(spec.shutdownOrder, children)
 	}
-	return children, cleanup, nil
+	if err != nil {
+		if cleanup != nil {
+			_ = cleanup()
+		}
+		_ = setupCleanup()
+		_ = releaseResources()
+		return []c.ChildSpec{}, nil, &SupervisorBuildError{
+			supRuntimeName: supRuntimeName,
+			buildNodesErr:  err,
+			duplicateNames: findDuplicateNames(children),
+		}
+	}
+
+	if len(spec.resources) == 0 && spec.cleanup == nil && spec.setup == nil {
+		return children, cleanup, nil
+	}
+
+	return children, func() error {
+		var cleanupErr error
+		if cleanup != nil {
+			cleanupErr = cleanup()
+		}
+		if spec.cleanup != nil {
+			// the cleanup hook runs even when the nodes cleanup fails
+			if err := spec.cleanup(); err != nil && cleanupErr == nil {
+				cleanupErr = err
+			}
+		}
+		if err := setupCleanup(); err != nil && cleanupErr == nil {
+			cleanupErr = err
+		}
+		releaseErr := releaseResources()
+		if cleanupErr != nil {
+			return cleanupErr
+		}
+		return releaseErr
+	}, nil
+}
+
+// findDuplicateNames returns the names that are used by more than one of the
+// given children, sorted alphabetically
+func findDuplicateNames(children []c.ChildSpec) []string {
+	var duplicates []string
+	nameCount := make(map[string]int, len(children))
+	for _, chSpec := range children {
+		if chSpec.GetName() == "" {
+			continue
+		}
+		nameCount[chSpec.GetName()]++
+		// duplicates are reported once per name
+		if nameCount[chSpec.GetName()] == 2 {
+			duplicates = append(duplicates, chSpec.GetName())
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+// validateChildSpecs checks the settings of every given child, as well as the
+// uniqueness of their names; it returns an error that lists every invalid child.
+func validateChildSpecs(children []c.ChildSpec) error {
+	var problems []string
+
+	for _, chSpec := range children {
+		if err := chSpec.Validate(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if duplicates := findDuplicateNames(children); len(duplicates) > 0 {
+		problems = append(
+			problems,
+			fmt.Sprintf("duplicate child names '%s'", strings.Join(duplicates, "', '")),
+		)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return c.WrapSentinel(
+		c.ErrInvalidChildSpec, nil, "invalid child specs: %s", strings.Join(problems, "; "),
+	)
+}
+
+// runSetup executes the function specified with WithSetup, it returns the
+// cleanup function of the setup, which is never nil.
+func (spec SupervisorSpec) runSetup(
+	ctx context.Context,
+	supRuntimeName string,
+) (CleanupResourcesFn, error) {
+	noCleanup := func() error { return nil }
+	if spec.setup == nil {
+		return noCleanup, nil
+	}
+	setupCleanup, err := spec.setup(ctx)
+	if err != nil {
+		return nil, &SupervisorSetupError{
+			supRuntimeName: supRuntimeName,
+			setupErr:       err,
+		}
+	}
+	if setupCleanup == nil {
+		return noCleanup, nil
+	}
+	return setupCleanup, nil
 }
 
 // NewSupervisorSpec creates a SupervisorSpec. It requires the name of the
@@ -254,6 +432,7 @@ func NewSupervisorSpec(name string, buildNodes BuildNodesFn, opts ...Opt) Superv
 		buildNodes:       buildNodes,
 		shutdownTimeout:  defaultSupShutdownTimeout,
 		eventNotifier:    emptyEventNotifier,
+		workerPools:      newWorkerPools(),
 	}
 
 	// Check name cannot be empty
@@ -271,6 +450,22 @@ func NewSupervisorSpec(name string, buildNodes BuildNodesFn, opts ...Opt) Superv
 	return spec
 }
 
+// NewSupervisorSpecWithDefaults behaves like NewSupervisorSpec, with the
+// difference that the given default options (e.g. c.WithRestart or
+// c.WithShutdown) are applied to every child node returned by the given
+// buildNodes function. The options of each child take precedence over the
+// defaults when both set the same setting.
+func NewSupervisorSpecWithDefaults(
+	name string,
+	defaults []c.Opt,
+	buildNodes BuildNodesFn,
+	opts ...Opt,
+) SupervisorSpec {
+	spec := NewSupervisorSpec(name, buildNodes, opts...)
+	spec.childDefaults = append([]c.Opt{}, defaults...)
+	return spec
+}
+
 // Start creates a Supervisor from this SupervisorSpec.
 //
 // A Supervisor is a tree of workers and/or sub-trees. The Start algorithm
@@ -0,0 +1,105 @@
+package s_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+type middlewareKey struct{}
+
+func TestStartMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call)
+	}
+
+	tracing := func(label string) cap.StartMiddleware {
+		return func(next cap.StartFn) cap.StartFn {
+			return func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+				record(label)
+				return next(context.WithValue(ctx, middlewareKey{}, label), notifyStart)
+			}
+		}
+	}
+
+	worker := func(name string) cap.Node {
+		return cap.NewWorker(name, func(ctx context.Context) error {
+			label, _ := ctx.Value(middlewareKey{}).(string)
+			record(name + ":" + label)
+			<-ctx.Done()
+			return nil
+		})
+	}
+
+	subtree1 := cap.NewSupervisorSpec("subtree1", cap.WithNodes(worker("child2")))
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(worker("child1"), cap.Subtree(subtree1)),
+		cap.WithStartMiddleware(tracing("outer"), tracing("inner")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.NoError(t, sup.Terminate())
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the first middleware is the outermost, and workers of sub-trees are not
+	// affected
+	assert.Equal(t, []string{"outer", "inner", "child1:inner", "child2:"}, calls)
+}
+
+func TestStartMiddlewareDynamicSpawn(t *testing.T) {
+	started := make(chan string, 1)
+	mw := func(next cap.StartFn) cap.StartFn {
+		return func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			started <- "wrapped"
+			return next(ctx, notifyStart)
+		}
+	}
+
+	dyn, err := cap.NewDynSupervisor(
+		context.TODO(), "root", cap.WithStartMiddleware(mw),
+	)
+	assert.NoError(t, err)
+
+	_, err = dyn.Spawn(WaitDoneWorker("child1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapped", <-started)
+
+	assert.NoError(t, dyn.Terminate())
+}
+
+func TestStartMiddlewareResizedPool(t *testing.T) {
+	started := make(chan string, 2)
+	mw := func(next cap.StartFn) cap.StartFn {
+		return func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			started <- "wrapped"
+			return next(ctx, notifyStart)
+		}
+	}
+
+	pool := cap.NewWorkerPool("worker", 1, func(int) cap.Node {
+		return WaitDoneWorker("ignored")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root", cap.WithNodes(pool...), cap.WithStartMiddleware(mw),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapped", <-started)
+
+	// the workers spawned when the pool grows are wrapped as well
+	assert.NoError(t, sup.ResizePool("worker", 2))
+	assert.Equal(t, "wrapped", <-started)
+
+	assert.NoError(t, sup.Terminate())
+}
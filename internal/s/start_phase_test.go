@@ -0,0 +1,161 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func phaseWorker(name string, phase int) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithStartPhase(phase),
+	)
+}
+
+func TestStartPhases(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			phaseWorker("child1", 1),
+			phaseWorker("child2", 0),
+			phaseWorker("child3", 1),
+			phaseWorker("child4", 0),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child2"),
+			WorkerStarted("root/child4"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child3"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child3"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestStartPhasesWithStartupConcurrency(t *testing.T) {
+	var phase0, phase1 sync.WaitGroup
+	phase0.Add(2)
+	phase1.Add(2)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			barrierWorker("child1", &phase1, cap.WithStartPhase(1)),
+			barrierWorker("child2", &phase1, cap.WithStartPhase(1)),
+			barrierWorker("child3", &phase0),
+			barrierWorker("child4", &phase0),
+		),
+		[]cap.Opt{
+			// the children of each phase only start when they run at the same
+			// time
+			cap.WithStartupConcurrency(4),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	// the children of the second phase are started after the children of the
+	// first phase
+	startIndex := func(name string) int {
+		for i, ev := range events {
+			if WorkerStarted(name).Call(ev) {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, phase0Name := range []string{"root/child3", "root/child4"} {
+		for _, phase1Name := range []string{"root/child1", "root/child2"} {
+			assert.Less(t, startIndex(phase0Name), startIndex(phase1Name))
+		}
+	}
+	assert.NotEqual(t, -1, startIndex("root/child3"))
+	assert.NotEqual(t, -1, startIndex("root/child4"))
+
+	// phases are terminated in reverse order
+	AssertPartialMatch(t, events,
+		[]EventP{
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child3"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestStartPriorities(t *testing.T) {
+	priorityWorker := func(name string, phase, priority int) cap.Node {
+		return cap.NewWorker(
+			name,
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			cap.WithStartPhase(phase),
+			cap.WithStartPriority(priority),
+		)
+	}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			priorityWorker("child1", 0, 0),
+			priorityWorker("child2", 1, 10),
+			priorityWorker("child3", 0, 5),
+			priorityWorker("child4", 0, 0),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	// priorities order the children within a phase, but they don't cross
+	// phases; children with the same priority keep the supervisor start order
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child3"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child4"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child4"),
+			WorkerTerminated("root/child1"),
+			WorkerTerminated("root/child3"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,114 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// hungStartWorker never notifies its start; it finishes once its context is
+// done, closing the given channel
+func hungStartWorker(name string, doneCh chan struct{}, opts ...cap.WorkerOpt) cap.Node {
+	return cap.NewWorkerWithNotifyStart(
+		name,
+		func(ctx context.Context, _ cap.NotifyStartFn) error {
+			<-ctx.Done()
+			close(doneCh)
+			return nil
+		},
+		opts...,
+	)
+}
+
+func TestStartTimeout(t *testing.T) {
+	doneCh := make(chan struct{})
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			hungStartWorker("child2", doneCh, cap.WithStartTimeout(10*time.Millisecond)),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	var startErr *cap.SupervisorStartError
+	assert.True(t, errors.As(err, &startErr))
+
+	// the worker context got cancelled before the start failure was reported
+	select {
+	case <-doneCh:
+	default:
+		t.Error("worker was not done after its start timeout")
+	}
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStartFailed("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorStartFailed("root"),
+		},
+	)
+
+	failedEv := findEvent(t, events, WorkerStartFailed("root/child2"))
+	assert.Equal(t, cap.ReasonStartTimeout, failedEv.ReasonCode())
+	assert.True(t, errors.Is(failedEv.Err(), cap.ErrStartTimeout))
+}
+
+func TestStartTimeoutNotReached(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			cap.NewWorker(
+				"child1",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithStartTimeout(time.Second),
+			),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestStartTimeoutOnSpawn(t *testing.T) {
+	dyn, err := cap.NewDynSupervisor(context.TODO(), "root")
+	assert.NoError(t, err)
+
+	doneCh := make(chan struct{})
+	_, err = dyn.Spawn(
+		hungStartWorker("child1", doneCh, cap.WithStartTimeout(10*time.Millisecond)),
+	)
+	assert.True(t, errors.Is(err, cap.ErrStartTimeout))
+	<-doneCh
+
+	// the supervisor keeps working after the failed spawn
+	_, err = dyn.Spawn(WaitDoneWorker("child2"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dyn.Terminate())
+}
@@ -0,0 +1,143 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// barrierWorker creates a worker that only notifies its start after all the
+// workers sharing the given WaitGroup are running at the same time
+func barrierWorker(name string, wg *sync.WaitGroup, opts ...cap.WorkerOpt) cap.Node {
+	return cap.NewWorkerWithNotifyStart(
+		name,
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			wg.Done()
+
+			barrierCh := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(barrierCh)
+			}()
+
+			select {
+			case <-barrierCh:
+			case <-time.After(time.Second):
+				err := fmt.Errorf("%s did not see its siblings starting", name)
+				notifyStart(err)
+				return err
+			}
+
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		},
+		opts...,
+	)
+}
+
+func TestStartupConcurrency(t *testing.T) {
+	t.Run("starts children in parallel", func(t *testing.T) {
+		var wg sync.WaitGroup
+		wg.Add(3)
+
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(
+				barrierWorker("child0", &wg),
+				barrierWorker("child1", &wg),
+				barrierWorker("child2", &wg),
+			),
+			[]cap.Opt{
+				cap.WithStartupConcurrency(3),
+			},
+			func(EventManager) {},
+		)
+
+		assert.NoError(t, err)
+
+		// the workers start at the same time, but their events are reported
+		// in start order, and they are stopped sequentially in reverse order
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child0"),
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				SupervisorStarted("root"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				WorkerTerminated("root/child0"),
+				SupervisorTerminated("root"),
+			})
+	})
+
+	t.Run("terminates started siblings on start failure", func(t *testing.T) {
+		events, err := ObserveSupervisor(
+			context.TODO(),
+			"root",
+			cap.WithNodes(
+				WaitDoneWorker("child0"),
+				// NOTE: delay the start failure so that all the siblings are
+				// dispatched by the supervisor
+				cap.NewWorkerWithNotifyStart(
+					"child1",
+					func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+						time.Sleep(20 * time.Millisecond)
+						err := errors.New("child1 start failure")
+						notifyStart(err)
+						return err
+					},
+				),
+				WaitDoneWorker("child2"),
+			),
+			[]cap.Opt{
+				cap.WithStartupConcurrency(3),
+			},
+			func(EventManager) {},
+		)
+
+		assert.Error(t, err)
+
+		var startErr *cap.SupervisorStartError
+		assert.True(t, errors.As(err, &startErr))
+		assert.Equal(t, "child1", startErr.KVs()["supervisor.start.node.name"])
+
+		// both healthy siblings start before the failure gets handled, they
+		// must be terminated in reverse order
+		evIndex := make(map[string]int)
+		for i, ev := range events {
+			evIndex[fmt.Sprintf("%s %s", ev.GetTag(), ev.GetProcessRuntimeName())] = i
+		}
+
+		child2Stop, ok := evIndex["ProcessTerminated root/child2"]
+		assert.True(t, ok)
+		child0Stop, ok := evIndex["ProcessTerminated root/child0"]
+		assert.True(t, ok)
+		assert.Less(t, child2Stop, child0Stop)
+
+		assert.True(t, SupervisorStartFailed("root").Call(events[len(events)-1]))
+	})
+}
+
+func TestWithStartupConcurrencyInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		cap.WithStartupConcurrency(0)
+	})
+	assert.Panics(t, func() {
+		cap.WithStartupConcurrency(-1)
+	})
+}
@@ -0,0 +1,189 @@
+package s
+
+// This file contains the implementation of Supervisor.Subscribe
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// subscriptionBufferSize is the buffer size of the channel of every
+// subscription
+const subscriptionBufferSize = 64
+
+// EventFilter specifies which events of a supervision tree are delivered to a
+// subscription (see Supervisor.Subscribe). An event must match every non-empty
+// field of the filter; the zero value matches every event.
+type EventFilter struct {
+	// RuntimeNamePrefix matches the events of the processes whose runtime name
+	// starts with the given prefix (e.g. "root/subtree1")
+	RuntimeNamePrefix string
+	// Tags matches the events of the children that have every one of the given
+	// tags (see WithTags)
+	Tags map[string]string
+	// EventTags matches the events that have any of the given EventTag values
+	// (e.g. ProcessFailed)
+	EventTags []EventTag
+}
+
+// Match returns true when the given event satisfies this filter
+func (f EventFilter) Match(ev Event) bool {
+	if !strings.HasPrefix(ev.GetProcessRuntimeName(), f.RuntimeNamePrefix) {
+		return false
+	}
+
+	for k, v := range f.Tags {
+		if tv, ok := ev.tags[k]; !ok || tv != v {
+			return false
+		}
+	}
+
+	if len(f.EventTags) == 0 {
+		return true
+	}
+	for _, tag := range f.EventTags {
+		if ev.GetTag() == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// subscription is the state of a single Supervisor.Subscribe call
+type subscription struct {
+	filter EventFilter
+	ch     chan Event
+}
+
+// deliver sends the given event to the subscriber; when the buffer of the
+// subscriber is full, the oldest buffered event is discarded to make room for
+// the new one.
+func (sub *subscription) deliver(ev Event) {
+	for {
+		select {
+		case sub.ch <- ev:
+			return
+		default:
+			// make room for the new event, the subscriber may have read the
+			// oldest one already
+			select {
+			case <-sub.ch:
+			default:
+			}
+		}
+	}
+}
+
+// eventSubscriptions keeps track of the subscribers of a supervision tree. A
+// single value is shared by the root supervisor and all its sub-trees.
+type eventSubscriptions struct {
+	mu          sync.Mutex
+	rootName    string
+	subscribers map[uint64]*subscription
+	nextID      uint64
+	closed      bool
+}
+
+// newEventSubscriptions creates the eventSubscriptions of the supervision tree
+// with the given root runtime name
+func newEventSubscriptions(rootName string) *eventSubscriptions {
+	return &eventSubscriptions{
+		rootName:    rootName,
+		subscribers: make(map[uint64]*subscription),
+	}
+}
+
+// dispatch returns an EventNotifier that delivers every event to the matching
+// subscribers before it is given to the given EventNotifier. The channels of
+// the subscribers are closed once the termination of the root supervisor is
+// reported.
+func (es *eventSubscriptions) dispatch(en EventNotifier) EventNotifier {
+	return func(ev Event) {
+		es.mu.Lock()
+		if !es.closed {
+			for _, sub := range es.subscribers {
+				if sub.filter.Match(ev) {
+					sub.deliver(ev)
+				}
+			}
+			if es.isRootTermination(ev) {
+				es.closeSubscribers()
+			}
+		}
+		es.mu.Unlock()
+		en(ev)
+	}
+}
+
+// isRootTermination returns true when the given event is the last event the
+// root supervisor reports
+func (es *eventSubscriptions) isRootTermination(ev Event) bool {
+	if ev.GetNodeTag() != c.Supervisor || ev.GetProcessRuntimeName() != es.rootName {
+		return false
+	}
+	switch ev.GetTag() {
+	case ProcessTerminated, ProcessFailed, ProcessStartFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSubscribers closes the channel of every subscriber; it must be called
+// with the lock held
+func (es *eventSubscriptions) closeSubscribers() {
+	es.closed = true
+	for id, sub := range es.subscribers {
+		close(sub.ch)
+		delete(es.subscribers, id)
+	}
+}
+
+// subscribe registers a new subscriber with the given filter
+func (es *eventSubscriptions) subscribe(filter EventFilter) (<-chan Event, func()) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	sub := &subscription{filter: filter, ch: make(chan Event, subscriptionBufferSize)}
+	if es.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	id := es.nextID
+	es.nextID++
+	es.subscribers[id] = sub
+
+	cancel := func() {
+		es.mu.Lock()
+		defer es.mu.Unlock()
+		if _, ok := es.subscribers[id]; ok {
+			close(sub.ch)
+			delete(es.subscribers, id)
+		}
+	}
+	return sub.ch, cancel
+}
+
+// Subscribe returns a channel that receives the events of this supervision tree
+// (including the events of its sub-trees) that match the given filter, and a
+// function that cancels the subscription and closes the channel.
+//
+// Every subscriber gets its own copy of the matching events in a channel with
+// a buffer of 64 events. Supervisors never wait on a slow subscriber: when the
+// buffer of a subscriber is full, its oldest buffered event is discarded to
+// make room for the new one, without affecting other subscribers.
+//
+// Events emitted before the call are not delivered (see WithEventHistory for
+// that). The channel is closed after the event that reports the termination of
+// the supervisor (when Wait or Terminate are called) is delivered.
+func (sup Supervisor) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	if sup.subscriptions == nil {
+		ch := make(chan Event)
+		close(ch)
+		return ch, func() {}
+	}
+	return sup.subscriptions.subscribe(filter)
+}
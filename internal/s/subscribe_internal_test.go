@@ -0,0 +1,39 @@
+package s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+func TestSubscriptionDropsOldestEvents(t *testing.T) {
+	es := newEventSubscriptions("root")
+	slowCh, _ := es.subscribe(EventFilter{})
+	en := es.dispatch(emptyEventNotifier)
+
+	total := subscriptionBufferSize + 6
+	for i := 0; i < total; i++ {
+		en(Event{tag: ProcessStarted, nodeTag: c.Worker, processRuntimeName: fmt.Sprintf("root/child%d", i)})
+	}
+	// the root termination closes the subscription
+	en(Event{tag: ProcessTerminated, nodeTag: c.Supervisor, processRuntimeName: "root"})
+
+	var events []Event
+	for ev := range slowCh {
+		events = append(events, ev)
+	}
+
+	// the subscriber keeps the newest events
+	if assert.Len(t, events, subscriptionBufferSize) {
+		assert.Equal(t, "root/child7", events[0].GetProcessRuntimeName())
+		assert.Equal(t, ProcessTerminated, events[len(events)-1].GetTag())
+	}
+
+	// a subscription created after the termination is closed
+	lateCh, _ := es.subscribe(EventFilter{})
+	_, ok := <-lateCh
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// collectEvents reads the given channel until it is closed
+func collectEvents(t *testing.T, ch <-chan cap.Event) []cap.Event {
+	var events []cap.Event
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return events
+			}
+			events = append(events, ev)
+		case <-time.After(time.Second):
+			t.Error("expected subscription channel to be closed")
+			return events
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("every subscriber gets the events that match its filter", func(t *testing.T) {
+		child2, failWorker2 := FailOnSignalWorker(
+			1, "child2", cap.WithTags(map[string]string{"group": "a"}),
+		)
+		subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(child2))
+
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(taggedWorker("child1", "b"), cap.Subtree(subtree)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		byPrefix, _ := sup.Subscribe(cap.EventFilter{RuntimeNamePrefix: "root/subtree"})
+		byTags, _ := sup.Subscribe(cap.EventFilter{Tags: map[string]string{"group": "a"}})
+		byEventTag, _ := sup.Subscribe(
+			cap.EventFilter{EventTags: []cap.EventTag{cap.ProcessFailed}},
+		)
+		all, _ := sup.Subscribe(cap.EventFilter{})
+
+		failWorker2(true /* done */)
+
+		// wait for the restart of child2
+		var tagged []cap.Event
+		for ev := range byTags {
+			tagged = append(tagged, ev)
+			if ev.GetTag() == cap.ProcessStarted {
+				break
+			}
+		}
+
+		assert.NoError(t, sup.Terminate())
+		tagged = append(tagged, collectEvents(t, byTags)...)
+
+		assertEvents := func(events []cap.Event, preds ...EventP) {
+			if assert.Len(t, events, len(preds)) {
+				for i, pred := range preds {
+					assert.True(t, pred.Call(events[i]), "%s: %v", pred.String(), events[i])
+				}
+			}
+		}
+
+		assertEvents(
+			tagged,
+			WorkerFailed("root/subtree/child2"),
+			WorkerStarted("root/subtree/child2"),
+			WorkerTerminated("root/subtree/child2"),
+		)
+
+		assertEvents(
+			collectEvents(t, byPrefix),
+			WorkerFailed("root/subtree/child2"),
+			WorkerStarted("root/subtree/child2"),
+			WorkerTerminated("root/subtree/child2"),
+			SupervisorTerminated("root/subtree"),
+		)
+
+		assertEvents(
+			collectEvents(t, byEventTag),
+			WorkerFailed("root/subtree/child2"),
+		)
+
+		allEvents := collectEvents(t, all)
+		if assert.NotEmpty(t, allEvents) {
+			assert.True(t, SupervisorTerminated("root").Call(allEvents[len(allEvents)-1]))
+		}
+	})
+
+	t.Run("cancel closes the channel", func(t *testing.T) {
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1")),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		evCh, cancel := sup.Subscribe(cap.EventFilter{})
+		cancel()
+		// cancelling twice is a no-op
+		cancel()
+		assert.Empty(t, collectEvents(t, evCh))
+
+		assert.NoError(t, sup.Terminate())
+	})
+
+	t.Run("subscribing to a terminated supervisor", func(t *testing.T) {
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1")),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+		assert.NoError(t, sup.Terminate())
+
+		evCh, cancel := sup.Subscribe(cap.EventFilter{})
+		defer cancel()
+		assert.Empty(t, collectEvents(t, evCh))
+	})
+}
@@ -24,8 +24,20 @@ func (spec SupervisorSpec) run(
 	onStart c.NotifyStartFn,
 	ctrlChan chan ctrlMsg,
 ) error {
+	ctx = spec.withLogger(ctx)
+	ctx = spec.withPanicRecovery(ctx)
+
+	// the separator of the root supervisor is used across all the sub-trees
+	spec.nameSeparator = c.GetNodeSeparator(ctx)
+
+	// the creation time and the timers of children are measured with the
+	// clock of the sub-tree, which is the one of its parent unless it has its
+	// own
+	ctx = c.WithClockNow(ctx, spec.getClock().Now)
+	ctx = c.WithClockAfter(ctx, spec.getClock().After)
+
 	// Build childrenSpec and resource cleanup
-	supChildrenSpecs, supRscCleanup, rscAllocError := spec.buildChildrenSpecs(supRuntimeName)
+	supChildrenSpecs, supRscCleanup, rscAllocError := spec.buildChildrenSpecs(ctx, supRuntimeName)
 
 	// Do not even start the monitor loop if we find an error on the resource
 	// allocation logic
@@ -39,9 +51,13 @@ func (spec SupervisorSpec) run(
 
 	onTerminate := func(err terminateNodeError) {}
 
-	supTolerance := &restartToleranceManager{restartTolerance: spec.restartTolerance}
+	supTolerance := &restartToleranceManager{
+		restartTolerance: spec.restartTolerance,
+		clock:            spec.getClock(),
+		healDuration:     spec.healDuration,
+	}
 
-	startTime := time.Now()
+	startTime := spec.getClock().Now()
 	// spawn goroutine with supervisor monitorLoop
 	return runMonitorLoop(
 		ctx,
@@ -89,6 +105,9 @@ func (spec SupervisorSpec) subtree(
 	copts0 ...c.Opt,
 ) c.ChildSpec {
 	subtreeSpec.eventNotifier = spec.eventNotifier
+	if subtreeSpec.clock == nil {
+		subtreeSpec.clock = spec.clock
+	}
 
 	// NOTE: Child goroutines that are running a sub-tree supervisor must always
 	// have a timeout of Infinity, as specified in the documentation from OTP
@@ -100,11 +119,14 @@ func (spec SupervisorSpec) subtree(
 		c.WithTolerance(1, 5*time.Second),
 	)
 
-	return c.NewWithNotifyStart(
+	chSpec := c.NewWithNotifyStart(
 		subtreeSpec.GetName(),
 		subtreeMain(subtreeSpec, ctrlChan),
 		copts...,
 	)
+	// allow the parent supervisor to reach the sub-tree supervisor (e.g.
+	// when running health checks)
+	return chSpec.AttachSubtreeCtrl(ctrlChan)
 }
 
 // Subtree transforms SupervisorSpec into a Node. This function allows you to
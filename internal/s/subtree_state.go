@@ -0,0 +1,50 @@
+package s
+
+// This file contains the implementation of the WithSubtreeState BuildNodesFn
+
+import "sync"
+
+// WithSubtreeState creates a BuildNodesFn that carries a state value of type S
+// across the restarts of the supervisor that uses it. The state is given to
+// the buildNodes function every time the supervisor (re)starts its children
+// nodes.
+//
+// The init function is called the first time the supervisor starts, and its
+// result becomes the initial state; if init fails, the error is returned as a
+// build error, and init is called again on the next start attempt.
+//
+// The onRestart function is called on every subsequent start of the
+// supervisor (e.g. when it is restarted by its parent supervisor after a
+// crash), it receives the state of the previous run and returns the state
+// for the new one. It is never called on the initial build.
+//
+// The state belongs to the returned BuildNodesFn value, a SupervisorSpec
+// created with it must not be started more than once concurrently.
+func WithSubtreeState[S any](
+	init func() (S, error),
+	onRestart func(old S) S,
+	buildNodes func(S) ([]Node, CleanupResourcesFn, error),
+) BuildNodesFn {
+	var mux sync.Mutex
+	var state S
+	var initialized bool
+
+	return func() ([]Node, CleanupResourcesFn, error) {
+		mux.Lock()
+		if !initialized {
+			st, err := init()
+			if err != nil {
+				mux.Unlock()
+				return nil, nil, err
+			}
+			state = st
+			initialized = true
+		} else {
+			state = onRestart(state)
+		}
+		current := state
+		mux.Unlock()
+
+		return buildNodes(current)
+	}
+}
@@ -0,0 +1,99 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestSubtreeStateCarriedAcrossRestarts(t *testing.T) {
+	var initCalls, restartCalls int
+	builtWith := make(chan int, 10)
+
+	buildNodes := cap.WithSubtreeState(
+		func() (int, error) {
+			initCalls++
+			return 1, nil
+		},
+		func(old int) int {
+			restartCalls++
+			return old + 1
+		},
+		func(st int) ([]cap.Node, cap.CleanupResourcesFn, error) {
+			builtWith <- st
+			worker := cap.NewWorker("worker", func(ctx context.Context) error {
+				if st == 1 {
+					// crash the subtree on its first run only
+					return errors.New("first run failure")
+				}
+				<-ctx.Done()
+				return nil
+			})
+			return []cap.Node{worker}, func() error { return nil }, nil
+		},
+	)
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		buildNodes,
+		cap.WithRestartTolerance(0, time.Minute),
+	)
+
+	_, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorFailed("root/subtree"))
+			evIt.WaitTill(SupervisorStarted("root/subtree"))
+		},
+	)
+	assert.NoError(t, err)
+
+	close(builtWith)
+	var states []int
+	for st := range builtWith {
+		states = append(states, st)
+	}
+
+	assert.Equal(t, []int{1, 2}, states)
+	assert.Equal(t, 1, initCalls)
+	assert.Equal(t, 1, restartCalls)
+}
+
+func TestSubtreeStateInitError(t *testing.T) {
+	buildCalled := false
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithSubtreeState(
+			func() (string, error) {
+				return "", errors.New("state init failed")
+			},
+			func(old string) string { return old },
+			func(string) ([]cap.Node, cap.CleanupResourcesFn, error) {
+				buildCalled = true
+				return []cap.Node{}, nil, nil
+			},
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.False(t, buildCalled)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		})
+}
@@ -19,16 +19,18 @@ import (
 // the risk of getting a panic error.
 type terminationManager struct {
 	mux          *sync.Mutex
+	waitMux      *sync.Mutex
 	terminated   bool
 	terminateErr error
 }
 
 // newTerminationManager creates a new terminationManager
 func newTerminationManager() *terminationManager {
-	var mux sync.Mutex
+	var mux, waitMux sync.Mutex
 
 	return &terminationManager{
 		mux:          &mux,
+		waitMux:      &waitMux,
 		terminated:   false,
 		terminateErr: nil,
 	}
@@ -63,37 +65,60 @@ type restartToleranceManager struct {
 	restartTolerance restartTolerance
 	restartCount     uint32
 	restartBeginTime time.Time
+	clock            Clock
+	healDuration     time.Duration
+	childRestarts    map[string]uint32
 }
 
-// checkToleranceExceeded adds a new failure on the error tolerance calculation, if the
-// number of errors is enough to surpass tolerance, it will return false,
-// otherwise it will modify it's restart count and return true.
-func (mgr *restartToleranceManager) checkToleranceExceeded(err error) bool {
+// checkToleranceExceeded adds a new failure of the given child on the error
+// tolerance calculation, the failure accounts for weight restarts (see
+// c.WithMinRuntime). If the number of errors is enough to surpass tolerance,
+// it will return false, otherwise it will modify it's restart count and return
+// true.
+func (mgr *restartToleranceManager) checkToleranceExceeded(
+	chName string, err error, weight uint32,
+) bool {
+	now := mgr.clock.Now()
 	if mgr.restartBeginTime == (time.Time{}) {
 		mgr.sourceErr = err
-		mgr.restartBeginTime = time.Now()
+		mgr.restartBeginTime = now
 	}
 
 	restartTolerance := mgr.restartTolerance
-	check := restartTolerance.check(mgr.restartCount, mgr.restartBeginTime)
+	check := restartTolerance.check(mgr.restartCount+weight-1, mgr.restartBeginTime, now)
 
 	switch check {
 	case restartToleranceSurpassed:
 		return false
 	case incRestartCount:
-		mgr.restartCount++
+		mgr.restartCount += weight
+		mgr.registerChildRestart(chName, false, weight)
 		return true
 	case resetRestartCount:
+		if restartTolerance.didSurpassMaxRestartCount(weight) {
+			// the failure alone surpasses the tolerance of a new window
+			return false
+		}
 		// not zero given we need to account for the error that just happened
 		mgr.sourceErr = err
-		mgr.restartCount = 1
-		mgr.restartBeginTime = time.Now()
+		mgr.restartCount = weight
+		mgr.restartBeginTime = now
+		mgr.registerChildRestart(chName, true, weight)
 		return true
 	default:
 		panic("Invalid implementation of restartTolerance values")
 	}
 }
 
+// reset forgets the failures accounted so far, the next failure starts a new
+// restart window
+func (mgr *restartToleranceManager) reset() {
+	mgr.sourceErr = nil
+	mgr.restartCount = 0
+	mgr.restartBeginTime = time.Time{}
+	mgr.childRestarts = nil
+}
+
 // Supervisor represents the root of a tree of goroutines. A Supervisor may have
 // leaf or sub-tree children, where each of the nodes in the tree represent a
 // goroutine that gets automatic restart abilities as soon as the parent
@@ -105,7 +130,15 @@ type Supervisor struct {
 	ctrlCh      chan ctrlMsg
 	terminateCh chan error
 
-	terminateManager        *terminationManager
+	terminateManager    *terminationManager
+	restartStats        *restartStats
+	terminationDeadline *terminationDeadline
+	notifications       *notificationStream
+	eventHistory        *eventHistory
+	subscriptions       *eventSubscriptions
+	abandonedChildren   *abandonedChildren
+	readyCh             chan struct{}
+	doneCh              chan struct{}
 
 	spec     SupervisorSpec
 	children map[string]c.Child
@@ -120,28 +153,57 @@ const defaultSupShutdownTimeout = 5 * time.Second
 
 // Terminate is a synchronous procedure that halts the execution of the whole
 // supervision tree.
+//
+// Children are stopped strictly in the reverse order of their start order (as
+// specified with WithStartOrder), and the termination of a child happens only
+// after the previous child has finished, either because it honored its
+// Shutdown setting, or because the Shutdown timeout expired. This guarantee
+// holds for every sub-tree, and it does not change when a child was restarted
+// while the supervisor was running.
 func (sup Supervisor) Terminate() error {
-	stopingTime := time.Now()
+	stopingTime := sup.spec.getClock().Now()
 	sup.cancel()
 	err := sup.wait(stopingTime, nil /* no startErr */)
 	return err
 }
 
 // Wait blocks the execution of the current goroutine until the Supervisor
-// finishes it execution.
+// finishes it execution, and returns the termination error of the Supervisor.
+//
+// It is safe to call Wait concurrently with Terminate (e.g. Wait on the main
+// goroutine, and Terminate on a signal handler); every caller gets the same
+// result.
 func (sup Supervisor) Wait() error {
 	return sup.wait(time.Time{}, nil /* no startErr */)
 }
 
+// Ready returns a channel that is closed once every node of the supervision
+// tree has started. When the start of the tree fails, the channel is never
+// closed; the error is reported by the Start call instead.
+//
+// This channel is useful to implement readiness checks (e.g. a Kubernetes
+// readiness probe) in a select statement.
+func (sup Supervisor) Ready() <-chan struct{} {
+	return sup.readyCh
+}
+
 // GetName returns the name of the Spec used to start this Supervisor
 func (sup Supervisor) GetName() string {
 	return sup.spec.GetName()
 }
 
+// RuntimeName returns the name of this Supervisor in the supervision tree, which
+// includes the names of its parent supervisors (e.g. "root/subtree1"); the
+// runtime name of a root supervisor is the name of its Spec.
+func (sup Supervisor) RuntimeName() string {
+	return sup.runtimeName
+}
+
 // storeTerminationError is responsible of registering the final state of the
 // supervisor and to signal the event notifications system
 func storeTerminationErr(
 	eventNotifier EventNotifier,
+	clock Clock,
 	supRuntimeName string,
 	tm *terminationManager,
 	err error,
@@ -156,10 +218,11 @@ func storeTerminationErr(
 	// stopingTime is only relevant when we call the internal wait function
 	// from the Terminate() public API; if we just called from Wait(), we don't
 	// need to keep track of the stop duration
-	if stopingTime == (time.Time{}) {
-		stopingTime = time.Now()
+	var stopDuration time.Duration
+	if stopingTime != (time.Time{}) {
+		stopDuration = clock.Now().Sub(stopingTime)
 	}
-	eventNotifier.supervisorTerminated(supRuntimeName, stopingTime)
+	eventNotifier.supervisorTerminated(supRuntimeName, stopDuration)
 }
 
 // getCrashError will return an error if the supervisor crashed, otherwise
@@ -167,6 +230,7 @@ func storeTerminationErr(
 func getCrashError(
 	block bool,
 	eventNotifier EventNotifier,
+	clock Clock,
 	supRuntimeName string,
 	terminateCh <-chan error,
 	tm *terminationManager,
@@ -178,9 +242,20 @@ func getCrashError(
 	}
 
 	if block {
+		// only one caller may receive the termination error of the supervisor,
+		// other callers (e.g. Wait and Terminate invoked concurrently) get the
+		// registered result once the first caller is done
+		tm.waitMux.Lock()
+		defer tm.waitMux.Unlock()
+
+		if terminatedVal, terminateErrVal := tm.getTerminateErr(); terminatedVal {
+			return terminatedVal, terminateErrVal
+		}
+
 		terminateErr := <-terminateCh
 		storeTerminationErr(
 			eventNotifier,
+			clock,
 			supRuntimeName,
 			tm,
 			terminateErr,
@@ -189,10 +264,17 @@ func getCrashError(
 		return true, terminateErr
 	}
 
+	// a blocked caller is already receiving the termination error
+	if !tm.waitMux.TryLock() {
+		return false, nil
+	}
+	defer tm.waitMux.Unlock()
+
 	select {
 	case terminateErr := <-terminateCh:
 		storeTerminationErr(
 			eventNotifier,
+			clock,
 			supRuntimeName,
 			tm,
 			terminateErr,
@@ -212,6 +294,7 @@ func (sup Supervisor) GetCrashError(block bool) (bool, error) {
 	return getCrashError(
 		false, /* block */
 		sup.spec.eventNotifier,
+		sup.spec.getClock(),
 		sup.runtimeName,
 		sup.terminateCh,
 		sup.terminateManager,
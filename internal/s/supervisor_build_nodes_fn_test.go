@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -75,6 +76,88 @@ func TestSupervisorWithErroredBuildNodesFn(t *testing.T) {
 	})
 }
 
+func TestSupervisorWithInvalidChildSpecs(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child2"),
+			WaitDoneWorker("child1"),
+			cap.NewWorker(
+				"child3",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithStartTimeout(-1*time.Second),
+			),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrInvalidChildSpec))
+
+	var buildErr *cap.SupervisorBuildError
+	assert.True(t, errors.As(err, &buildErr))
+
+	// every invalid child is reported
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> invalid child specs: child 'child3' is invalid: negative start timeout -1s; "+
+			"duplicate child names 'child1'",
+		explanation,
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
+func TestSupervisorWithDuplicateNodeNames(t *testing.T) {
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child2"),
+			WaitDoneWorker("child2"),
+			WaitDoneWorker("child1"),
+			WaitDoneWorker("child1"),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.Error(t, err)
+
+	var buildErr *cap.SupervisorBuildError
+	if assert.True(t, errors.As(err, &buildErr)) {
+		assert.Equal(t, "child1, child2", buildErr.KVs()["supervisor.build.duplicate_names"])
+	}
+
+	explanation := cap.ExplainError(err)
+	assert.Equal(
+		t,
+		"supervisor 'root' build nodes function failed\n"+
+			"\t> invalid child specs: duplicate child names 'child1', 'child2'",
+		explanation,
+	)
+
+	// no child is started
+	AssertExactMatch(t, events,
+		[]EventP{
+			SupervisorStartFailed("root"),
+		},
+	)
+}
+
 func TestSupervisorWithPanicBuildNodesFnOnSingleTree(t *testing.T) {
 	events, err := ObserveSupervisor(
 		context.TODO(),
@@ -174,7 +257,7 @@ func TestSupervisorWithErroredCleanupResourcesFnOnSingleTree(t *testing.T) {
 	assert.Error(t, err)
 	errKvs := err.(cap.ErrKVs)
 	kvs := errKvs.KVs()
-	assert.Equal(t, "supervisor terminated with failures", err.Error())
+	assert.Equal(t, "supervisor terminated with failures (cleanup: cleanup resources err)", err.Error())
 	assert.Equal(t, "root", kvs["supervisor.name"])
 	assert.Equal(
 		t,
@@ -228,7 +311,7 @@ func TestSupervisorWithErroredCleanupResourcesFnOnNestedTree(t *testing.T) {
 	assert.Error(t, err)
 	errKvs := err.(cap.ErrKVs)
 	kvs := errKvs.KVs()
-	assert.Equal(t, "supervisor terminated with failures", err.Error())
+	assert.Equal(t, "supervisor terminated with failures (subtree2/cleanup: cleanup resources err)", err.Error())
 	assert.Equal(t, "root", kvs["supervisor.name"])
 	assert.Equal(t, "root/subtree2", kvs["supervisor.subtree.0.name"])
 	assert.Equal(
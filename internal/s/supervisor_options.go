@@ -1,7 +1,12 @@
 package s
 
 import (
+	"context"
 	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/capatazlib/go-capataz/internal/c"
 )
 
 // Opt is a type used to configure a SupervisorSpec
@@ -35,14 +40,40 @@ var WithOrder = WithStartOrder
 //
 // * OneForOne -- Only restart the failing child
 //
-// * OneForAll (Not Implemented Yet) -- Restart the failing child and all its
-// siblings[*]
+// * OneForAll -- Restart the failing child and all its siblings[*]
+//
+// * RestForOne -- Restart the failing child and all the siblings that were
+// started after it[**]
 //
 // [*] This option may come handy when all the other siblings depend on one another
 // to work correctly.
+//
+// [**] This option may come handy when a sibling depends on the siblings that
+// were started before it (e.g. stages of a pipeline).
 func WithStrategy(s Strategy) Opt {
 	return func(spec *SupervisorSpec) {
 		spec.strategy = s
+		spec.restartStrategy = nil
+	}
+}
+
+// WithRestartStrategy is an Opt that specifies a custom RestartStrategy, which
+// decides which children of the supervisor are restarted when one of them
+// fails. The affected children are terminated in the reverse of their start
+// order, and then started again in start order; the built-in strategies
+// implement RestartStrategy, and they are restarted the same way. The siblings
+// that depend on the failed child (see WithDependsOn) are always restarted
+// with it.
+//
+// The built-in Strategy values may be given to this function as well, in which
+// case it behaves like WithStrategy.
+func WithRestartStrategy(rs RestartStrategy) Opt {
+	if s, ok := rs.(Strategy); ok {
+		return WithStrategy(s)
+	}
+	return func(spec *SupervisorSpec) {
+		spec.strategy = OneForOne
+		spec.restartStrategy = rs
 	}
 }
 
@@ -70,6 +101,20 @@ func WithNodes(nodes ...Node) BuildNodesFn {
 	}
 }
 
+// WithShutdownOrder is an Opt that specifies the order in which the supervisor
+// stops its children, overriding the default of stopping them in the reverse
+// of their start order. The children that are not listed are stopped after the
+// listed ones, in the reverse of their start order.
+//
+// The given names must belong to children of the supervisor, and they must not
+// be repeated; otherwise the supervisor fails to build with a
+// SupervisorBuildError.
+func WithShutdownOrder(specNames []string) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.shutdownOrder = append([]string{}, specNames...)
+	}
+}
+
 // WithRestartTolerance is a Opt that specifies how many errors the supervisor
 // should be willing to tolerate before giving up restarting and fail.
 //
@@ -92,3 +137,373 @@ func WithRestartTolerance(maxErrCount uint32, errWindow time.Duration) Opt {
 		}
 	}
 }
+
+// WithStartupConcurrency is an Opt that specifies how many children of a
+// supervisor may be started at the same time. Children are still dispatched
+// in start order, and the supervisor waits for all of them to notify their
+// start before reporting itself as started.
+//
+// If any child fails to start, the already started siblings are terminated in
+// reverse start order, like it happens with a sequential start.
+//
+// By default children start sequentially (a concurrency of 1).
+//
+// This function panics when the given number is less than 1.
+func WithStartupConcurrency(n int) Opt {
+	if n < 1 {
+		panic("Supervisor cannot have a startup concurrency less than one")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.startupConcurrency = n
+	}
+}
+
+// WithMaxConcurrentRestarts is an Opt that specifies how many of the children
+// restarted together by the supervisor (e.g. when a OneForAll supervisor
+// restarts all its children) are started at the same time; the rest of the
+// children wait for their turn in start order. It lowers the
+// WithStartupConcurrency setting on restarts, bounding the resources used when
+// many children are restarted during an outage of a dependency; restarts never
+// go beyond the startup concurrency. The supervisor handles the failures of
+// its children one at a time, so this setting doesn't bound restarts that
+// happen on different failures.
+//
+// When the supervisor is terminated in the middle of a restart, the children
+// that are waiting for their turn are not restarted, and the restart is not
+// reported as a GroupRestarted event; this happens with the default startup
+// concurrency as well.
+//
+// By default, restarts use the WithStartupConcurrency setting.
+//
+// This function panics when the given number is less than 1.
+func WithMaxConcurrentRestarts(n int) Opt {
+	if n < 1 {
+		panic("Supervisor cannot have less than one concurrent restart")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.restartConcurrency = n
+	}
+}
+
+// WithHealDuration is an Opt that makes the supervisor forgive the restarts of
+// a child once the child has been running continuously for the given duration.
+// The restarts of the child stop accounting for the restart tolerance of the
+// supervisor (see WithRestartTolerance) before the restart window is over, so
+// that sporadic failures accumulated over a long time don't make a healthy
+// supervisor give up.
+//
+// The running time of a child is measured with the wall clock, from its last
+// (re)start. By default, restarts are only forgotten when the restart window
+// is over.
+func WithHealDuration(d time.Duration) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.healDuration = d
+	}
+}
+
+// WithRestartDampening is an Opt that makes the supervisor coalesce the failures
+// of its children that happen within the given window into a single restart.
+//
+// When a child fails, the supervisor restarts it right away, along with the
+// children whose failures are pending already, and opens a window of the given
+// duration. The children that fail (or complete) while the window is open wait
+// for it to be over, and they are restarted with a single restart, rather than
+// causing a restart on their own; the supervisor keeps serving requests in the
+// meantime. This setting is only effective with the
+// OneForAll and RestForOne strategies, and with custom strategies (see
+// WithRestartStrategy), given children are restarted independently with
+// OneForOne.
+func WithRestartDampening(window time.Duration) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.restartDampening = window
+	}
+}
+
+// WithResources is an Opt that specifies resources the supervisor must acquire
+// before starting its children, and release after terminating them.
+//
+// Resources are acquired in the given order and released in reverse order,
+// each release waits at most the release timeout of its ResourceSpec. When a
+// resource fails to be acquired, the supervisor start is aborted and the
+// resources that were already acquired get released.
+//
+// Resources are acquired again every time the supervisor gets restarted.
+func WithResources(resources []ResourceSpec) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.resources = resources
+	}
+}
+
+// WithSetup is an Opt that specifies a function that the supervisor calls
+// before it builds and starts its children. It is useful to allocate values
+// shared by the children (e.g. a database pool); the returned cleanup function
+// (which may be nil) is called once all the children have stopped.
+//
+// When the setup function fails, the supervisor doesn't start any children and
+// fails with a SupervisorSetupError. The setup function runs after the
+// resources declared with WithResources are acquired, and it runs again every
+// time the supervisor gets restarted.
+func WithSetup(setup func(context.Context) (CleanupResourcesFn, error)) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.setup = setup
+	}
+}
+
+// WithCleanup is an Opt that specifies a function that the supervisor calls
+// once all its children have stopped, both on a regular termination and when
+// the supervisor crashes because its restart tolerance was surpassed. It is
+// useful to release values shared by the children (e.g. a database pool).
+//
+// The cleanup function is called once every time the supervisor terminates,
+// before the resources declared with WithResources are released. A cleanup
+// error is reported on the supervisor termination error.
+func WithCleanup(cleanup func() error) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.cleanup = cleanup
+	}
+}
+
+// WithClock is an Opt that specifies the Clock the supervisor uses to measure
+// time (e.g. the restart tolerance window). It is useful to control the time
+// of a supervisor in tests.
+//
+// Sub-trees inherit the Clock of their parent supervisor, unless they specify
+// one of their own.
+func WithClock(clock Clock) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.clock = clock
+	}
+}
+
+// WithDeadLetter is an Opt that specifies a callback that the supervisor calls
+// once when it permanently gives up on a failing child: when the child
+// surpasses the restart tolerance of the supervisor, when it panics as many
+// times as its panic escalation setting allows, when it exhausts its
+// transient budget, or when it fails to start on a full restart of the
+// supervisor (see Supervisor.Restart). The callback receives the runtime name
+// of the child and a RestartToleranceReached error, which wraps the last error
+// of the child and offers the failure details via its KVs method.
+//
+// The callback is called from the supervisor goroutine, it must not block.
+func WithDeadLetter(deadLetter func(runtimeName string, lastErr error)) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.deadLetter = deadLetter
+	}
+}
+
+// WithLogger is an Opt that specifies the Logger of the supervision tree. Every
+// node started by this supervisor (and its sub-trees) gets a Logger derived from
+// the given one, which prefixes messages with the node's runtime name. Nodes
+// retrieve it from their start context with LoggerFromContext.
+//
+// Sub-trees inherit the Logger of their parent supervisor, unless they specify
+// one of their own.
+func WithLogger(logger c.Logger) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.logger = logger
+	}
+}
+
+// WithPanicRecovery is an Opt that specifies if the panics raised on the
+// goroutines of this supervisor's children are recovered and handled as
+// regular child failures (defaults to true). The error reported by a recovered
+// child is a c.PanicError, which carries the panic value and the stack trace
+// of the goroutine.
+//
+// Recovery applies to every child, regardless of its c.WithCapturePanic
+// setting; when disabled, a panic on a child goroutine crashes the program.
+// Sub-trees inherit the setting of their parent supervisor, unless they
+// specify one of their own.
+func WithPanicRecovery(enabled bool) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.panicRecovery = &enabled
+	}
+}
+
+// WithNameSeparator is an Opt that specifies the token used to join the names
+// of supervisors and their children when building runtime names (defaults to
+// "/"). The separator affects every runtime name of the supervision tree, and
+// as such the names reported on events, errors and loggers; event consumers
+// that correlate events by runtime name must use the same separator.
+//
+// This option is only honored on the root supervisor; sub-trees always inherit
+// the separator of the root supervisor.
+//
+// This function panics when the given separator is empty.
+func WithNameSeparator(sep string) Opt {
+	if sep == "" {
+		panic("Supervisor cannot have an empty name separator")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.nameSeparator = sep
+	}
+}
+
+// WithSeed is an Opt that specifies the seed of the random sources of the
+// supervision tree. Every node gets a source derived from the seed and its
+// runtime name (see RandFromContext), so a run of a tree (e.g. a chaos test)
+// can be reproduced with the seed of a previous run, without contention
+// between the sources of different nodes.
+//
+// The seed doesn't make the timing of goroutines deterministic; unless the
+// supervisor also uses a fake Clock (see WithClock), the real clock still
+// introduces nondeterminism.
+//
+// This option is only honored on the root supervisor; sub-trees always inherit
+// the seed of the root supervisor.
+func WithSeed(seed int64) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.seed = &seed
+	}
+}
+
+// WithNotificationBuffer is an Opt that specifies the buffer size of the
+// channel returned by Supervisor.Notifications (defaults to 16), and what
+// happens when the buffer is full because its consumer is slow (defaults to
+// DropOldestNotification).
+//
+// This option is only honored on the root supervisor; sub-trees always publish
+// their notifications on the channel of the root supervisor.
+//
+// This function panics when the given buffer size is negative.
+func WithNotificationBuffer(size int, overflow NotificationOverflow) Opt {
+	if size < 0 {
+		panic("Supervisor cannot have a negative notification buffer size")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.notifications = &notificationSettings{bufferSize: size, overflow: overflow}
+	}
+}
+
+// WithEventHistory is an Opt that makes the supervisor retain the given number
+// of its most recent events (including the events of its sub-trees), so that
+// they can be fetched with Supervisor.ReplayEvents; e.g. by a consumer that was
+// attached after the supervision tree started. By default, no events are
+// retained.
+//
+// This option is only honored on the root supervisor.
+//
+// This function panics when the given size is negative.
+func WithEventHistory(size int) Opt {
+	if size < 0 {
+		panic("Supervisor cannot have a negative event history size")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.eventHistory = size
+	}
+}
+
+// WithWatchdog is an Opt that reports when an operation of the supervisor
+// loop takes longer than the given duration (e.g. an event notifier or a
+// resource cleanup that blocks). The onStall callback is called once for every
+// stalled operation, with a label of the operation: "notifier", "child-start",
+// "child-terminate" or "resource-cleanup". The callback is called on a
+// separate goroutine.
+//
+// The watchdog is a diagnostic tool, it doesn't interrupt the stalled
+// operation. It only watches the loop of this supervisor, sub-trees need their
+// own watchdog.
+//
+// This function panics when the given duration is not positive.
+func WithWatchdog(d time.Duration, onStall func(where string)) Opt {
+	if d <= 0 {
+		panic("Supervisor cannot have a non-positive watchdog duration")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.watchdog = &watchdogSettings{timeout: d, onStall: onStall}
+	}
+}
+
+// WithGroupRestartEventsOnly is an Opt that reports the restarts of a OneForAll
+// supervisor with a single GroupRestarted event; the ProcessTerminated and
+// ProcessStarted events of the siblings that are restarted together with the
+// failed child are not reported. By default, both the GroupRestarted event and
+// the events of every sibling are reported.
+//
+// Only the events this supervisor reports about its children are omitted; a
+// restarted sub-tree still reports the events of its own children.
+func WithGroupRestartEventsOnly() Opt {
+	return func(spec *SupervisorSpec) {
+		spec.groupRestartsOnly = true
+	}
+}
+
+// WithSpawnRateLimit is an Opt that bounds how fast children are spawned on a
+// DynSupervisor, or with the Spawner of a NewDynSubtree node (when given on its
+// spawnerOpts). Spawn calls block until the rate limit allows them, allowing
+// bursts of up to the given number of spawns. By default, spawns are not rate
+// limited.
+//
+// A DynSupervisor waits with the context given on its construction, the
+// Spawner of a NewDynSubtree waits with the context of the spawner worker; when
+// the context is done before the spawn is allowed, the spawn call returns an
+// error that matches ErrSpawnRateLimited.
+//
+// This function panics when the given burst is lower than 1.
+func WithSpawnRateLimit(r rate.Limit, burst int) Opt {
+	if burst < 1 {
+		panic("Supervisor cannot have a spawn rate limit burst lower than 1")
+	}
+	return func(spec *SupervisorSpec) {
+		spec.spawnRateLimit = &spawnRateLimit{limit: r, burst: burst}
+	}
+}
+
+// WithStartMiddleware is an Opt that wraps the start function of every worker
+// of the supervisor with the given middleware, including the workers spawned
+// dynamically. The middleware are composed outermost-first: the first one
+// given is the first one to run when a worker starts. Sub-trees are not
+// affected, use this option on their own spec instead.
+//
+// The fallback start function of a worker (see c.WithFallback) is wrapped as
+// well.
+func WithStartMiddleware(mw ...StartMiddleware) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.startMiddleware = append(spec.startMiddleware, mw...)
+	}
+}
+
+// applyStartMiddleware wraps the start functions of the given worker spec with
+// the start middleware of the supervisor
+func (spec SupervisorSpec) applyStartMiddleware(chSpec c.ChildSpec) c.ChildSpec {
+	if len(spec.startMiddleware) == 0 || !chSpec.IsWorker() {
+		return chSpec
+	}
+	wrap := func(startFn StartFn) StartFn {
+		for i := len(spec.startMiddleware) - 1; i >= 0; i-- {
+			startFn = spec.startMiddleware[i](startFn)
+		}
+		return startFn
+	}
+	return chSpec.WrapStart(wrap)
+}
+
+// WithUnexpectedCleanExit is an Opt that makes the supervisor treat the clean
+// exit of a Transient child (which otherwise stops the child silently) as an
+// anomaly. The supervisor reports a ChildExitedUnexpectedly event, and then it
+// takes the given action: CleanExitNotify leaves the child stopped,
+// CleanExitRestart restarts it, and CleanExitEscalate makes the supervisor
+// fail, regardless of its restart tolerance.
+//
+// The clean exits of Permanent children (always restarted) and Temporary
+// children (never restarted) are not affected by this option.
+func WithUnexpectedCleanExit(action CleanExitAction) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.cleanExitAction = &action
+	}
+}
+
+// WithEscalationPolicy is an Opt that specifies what the supervisor does when
+// one of its sub-trees fails with a SupervisorRestartError (i.e. the sub-tree
+// gave up on one of its children). The supervisor reports a SubtreeEscalated
+// event with the applied policy, and then it applies it: RestartSubtree
+// restarts the sub-tree, StopSubtree leaves it stopped, and Propagate makes the
+// supervisor fail, regardless of its restart tolerance.
+//
+// Without this option, failed sub-trees are restarted as any other child, and
+// no SubtreeEscalated event is reported.
+func WithEscalationPolicy(policy EscalationPolicy) Opt {
+	return func(spec *SupervisorSpec) {
+		spec.escalationPolicy = &policy
+	}
+}
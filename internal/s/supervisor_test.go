@@ -10,7 +10,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -101,6 +103,75 @@ func TestStartMutlipleChildrenRightToLeft(t *testing.T) {
 	})
 }
 
+// Test that children are stopped strictly in the reverse order of their
+// start, and that a child termination begins only after the previous child
+// finished its own termination
+func TestTerminateChildrenInReverseStartOrder(t *testing.T) {
+	var mux sync.Mutex
+	stopLog := make([]string, 0, 6)
+
+	record := func(entry string) {
+		mux.Lock()
+		defer mux.Unlock()
+		stopLog = append(stopLog, entry)
+	}
+
+	slowStopWorker := func(name string) cap.Node {
+		return cap.NewWorker(name, func(ctx context.Context) error {
+			<-ctx.Done()
+			record(name + " stopping")
+			// give some room to the supervisor to (wrongly) move to the next
+			// child before this one finished
+			time.Sleep(10 * time.Millisecond)
+			record(name + " stopped")
+			return nil
+		})
+	}
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(
+			slowStopWorker("child0"),
+			slowStopWorker("child1"),
+			slowStopWorker("child2"),
+		),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+
+	t.Run("waits for each child before stopping the next one", func(t *testing.T) {
+		assert.Equal(
+			t,
+			[]string{
+				"child2 stopping",
+				"child2 stopped",
+				"child1 stopping",
+				"child1 stopped",
+				"child0 stopping",
+				"child0 stopped",
+			},
+			stopLog,
+		)
+	})
+
+	t.Run("reports terminations in reverse start order", func(t *testing.T) {
+		AssertExactMatch(t, events,
+			[]EventP{
+				WorkerStarted("root/child0"),
+				WorkerStarted("root/child1"),
+				WorkerStarted("root/child2"),
+				SupervisorStarted("root"),
+				WorkerTerminated("root/child2"),
+				WorkerTerminated("root/child1"),
+				WorkerTerminated("root/child0"),
+				SupervisorTerminated("root"),
+			})
+	})
+}
+
 // Test a supervision tree with two sub-trees start and stop children in the
 // default order _always_ (LeftToRight)
 func TestStartNestedSupervisors(t *testing.T) {
@@ -354,7 +425,7 @@ func TestTerminateFailedChild(t *testing.T) {
 	assert.Error(t, err)
 	errKVs := err.(cap.ErrKVs)
 	kvs := errKVs.KVs()
-	assert.Equal(t, "supervisor terminated with failures", err.Error())
+	assert.Equal(t, "supervisor terminated with failures (branch1/child2: child shutdown timeout)", err.Error())
 	assert.Equal(t, "root", kvs["supervisor.name"])
 	assert.Equal(t, "root/branch1", kvs["supervisor.subtree.0.name"])
 	assert.Equal(t, "child2", kvs["supervisor.subtree.0.termination.node.0.name"])
@@ -384,6 +455,7 @@ func TestTerminateFailedChild(t *testing.T) {
 			// NOTE: From here, the stop of the supervisor begins
 			WorkerTerminated("root/branch1/child3"),
 			// NOTE: the child2 never stops and fails with a timeout
+			WorkerAbandonedOnShutdown("root/branch1/child2"),
 			WorkerFailed("root/branch1/child2"),
 			// NOTE: The supervisor branch1 fails because of child2 timeout
 			SupervisorFailed("root/branch1"),
@@ -448,7 +520,7 @@ func TestFailedTerminationOnOneLevelTree(t *testing.T) {
 	assert.Error(t, err)
 	errKVs := err.(cap.ErrKVs)
 	kvs := errKVs.KVs()
-	assert.Equal(t, "supervisor terminated with failures", err.Error())
+	assert.Equal(t, "supervisor terminated with failures (child1: child1 failed)", err.Error())
 	assert.Equal(t, "root", kvs["supervisor.name"])
 	assert.Equal(t, "child1", kvs["supervisor.termination.node.0.name"])
 	assert.Equal(t, "child1 failed", fmt.Sprint(kvs["supervisor.termination.node.0.error"]))
@@ -524,3 +596,13 @@ func TestNamesOnDeeplyNestedTrees(t *testing.T) {
 
 	AssertExactMatch(t, events, expectedEvents)
 }
+
+func TestSupervisorRuntimeName(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root", cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "root", sup.RuntimeName())
+	assert.NoError(t, sup.Terminate())
+}
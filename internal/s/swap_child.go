@@ -0,0 +1,240 @@
+package s
+
+// This file contains the implementation of the swap of a child spec
+
+import (
+	"context"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// swapNameSuffix is appended to the spec name of a child to get the temporary
+// name of its new version while it is being swapped
+const swapNameSuffix = ".next"
+
+// childAliases maps the temporary names of swapped children to their spec
+// names; the children started under a temporary name keep reporting
+// notifications with it until they are restarted.
+type childAliases map[string]string
+
+// resolve returns the spec name of the child that reported a notification
+// with the given name
+func (aliases childAliases) resolve(name string) string {
+	specName, ok := aliases[name]
+	if !ok {
+		return name
+	}
+	// the child reported its termination, it is restarted with its spec name
+	delete(aliases, name)
+	return specName
+}
+
+// overlapChildren holds the temporary names of the new versions of swapped
+// children while they run next to the old ones; the restarts of their siblings
+// do not affect them.
+type overlapChildren map[string]struct{}
+
+// has indicates if the child with the given name is the new version of a
+// swapped child that is still running next to the old one
+func (overlap overlapChildren) has(name string) bool {
+	_, ok := overlap[name]
+	return ok
+}
+
+// startSwapMsg is a message sent from clients to tell a supervisor to start the
+// new version of a child next to the running one.
+type startSwapMsg struct {
+	nodeName   string
+	node       Node
+	resultChan chan<- error
+}
+
+func (ssm startSwapMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	var result error
+	defer func() {
+		// do not block waiting for a read
+		select {
+		case ssm.resultChan <- result:
+		default:
+		}
+	}()
+
+	ch, ok := supChildren[ssm.nodeName]
+	if !ok {
+		result = c.WrapSentinel(ErrNodeNotFound, nil, "child %s not found", ssm.nodeName)
+		return specChildren, supChildren
+	}
+
+	swapName := ssm.nodeName + swapNameSuffix
+	if _, ok := supChildren[swapName]; ok {
+		result = c.WrapSentinel(
+			ErrSwapInProgress, nil, "child %s is being swapped already", ssm.nodeName,
+		)
+		return specChildren, supChildren
+	}
+
+	newSpec := spec.applyStartMiddleware(ssm.node(spec))
+	newSpec.Name = swapName
+
+	// the new version is not part of a restart, even when this supervisor was
+	// restarted
+	startCtx := withRestartMark(supCtx, false)
+	newCh, startErr := startChildNode(
+		startCtx, spec, supRuntimeName, supNotifyChan, newSpec, nil,
+	)
+	if startErr != nil {
+		drainStartFailure(startErr, supNotifyChan)
+		result = startErr
+		return specChildren, supChildren
+	}
+
+	// the new version is terminated with the rest of the children if the
+	// supervisor stops before the swap is completed, but it is not restarted
+	// along with its siblings
+	specChildren = append(specChildren, newSpec)
+	supChildren[swapName] = newCh
+	spec.overlapChildren[swapName] = struct{}{}
+
+	evNotifier.withTags(newSpec).childSwapStarted(ch.GetTag(), ch.GetRuntimeName())
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = startSwapMsg{}
+
+// completeSwapMsg is a message sent from clients to tell a supervisor to
+// terminate the old version of a swapped child, and to give its name to the
+// new version.
+type completeSwapMsg struct {
+	nodeName   string
+	resultChan chan<- error
+}
+
+func (csm completeSwapMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	var result error
+	defer func() {
+		// do not block waiting for a read
+		select {
+		case csm.resultChan <- result:
+		default:
+		}
+	}()
+
+	swapName := csm.nodeName + swapNameSuffix
+	delete(spec.overlapChildren, swapName)
+
+	// the temporary spec of the new version is not needed anymore
+	newSpecs := make([]c.ChildSpec, 0, len(specChildren))
+	for _, chSpec := range specChildren {
+		if chSpec.GetName() != swapName {
+			newSpecs = append(newSpecs, chSpec)
+		}
+	}
+
+	newCh, ok := supChildren[swapName]
+	if !ok {
+		// the new version finished while both versions were running, and it
+		// was not restarted
+		result = c.WrapSentinel(ErrNodeNotFound, nil, "child %s not found", swapName)
+		return newSpecs, supChildren
+	}
+	delete(supChildren, swapName)
+
+	if oldCh, ok := supChildren[csm.nodeName]; ok {
+		result = terminateChildNode(spec, oldCh)
+	}
+
+	renamedCh := newCh.Rename(csm.nodeName)
+	supChildren[csm.nodeName] = renamedCh
+	spec.childAliases[swapName] = csm.nodeName
+
+	// the new version takes the place of the old one in the start order
+	replaced := false
+	for i, chSpec := range newSpecs {
+		if chSpec.GetName() == csm.nodeName {
+			newSpecs[i] = renamedCh.GetSpec()
+			replaced = true
+		}
+	}
+	if !replaced {
+		newSpecs = append(newSpecs, renamedCh.GetSpec())
+	}
+
+	evNotifier.withTags(renamedCh.GetSpec()).childSwapCompleted(
+		renamedCh.GetTag(), renamedCh.GetRuntimeName(),
+	)
+	return newSpecs, supChildren
+}
+
+var _ ctrlMsg = completeSwapMsg{}
+
+// SwapChild replaces the child with the given spec name with a new version
+// built from the given Node, without a gap in between: the new version is
+// started under a temporary name (the spec name with a ".next" suffix), both
+// versions run for the given overlap duration, and then the old version is
+// terminated and the new one takes its name. The name of the given Node is
+// ignored. While both versions run, the new version is not restarted along with
+// its siblings by the restart strategy of the supervisor.
+//
+// A ChildSwapStarted event is reported once the new version is running, and a
+// ChildSwapCompleted event once it took the place of the old version.
+//
+// When the new version fails to start, the old version keeps running and the
+// start error is returned. The swap is not permanent: if the supervisor is
+// restarted by its parent, it starts the children returned by its
+// BuildNodesFn again.
+func (sup Supervisor) SwapChild(specName string, node Node, overlap time.Duration) error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	startResultChan := make(chan error, 1)
+	err := sup.sendSwapMsg(
+		startSwapMsg{nodeName: specName, node: node, resultChan: startResultChan},
+		startResultChan,
+	)
+	if err != nil {
+		return err
+	}
+
+	if overlap > 0 {
+		sup.spec.getClock().Sleep(overlap)
+	}
+
+	completeResultChan := make(chan error, 1)
+	return sup.sendSwapMsg(
+		completeSwapMsg{nodeName: specName, resultChan: completeResultChan},
+		completeResultChan,
+	)
+}
+
+// sendSwapMsg sends a message of the swap procedure to the supervisor, and
+// waits for its result
+func (sup Supervisor) sendSwapMsg(msg ctrlMsg, resultChan <-chan error) error {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	err := sendCtrlMsg(ctx, sup.ctrlCh, msg)
+	if err != nil {
+		return err
+	}
+
+	return sup.waitCtrlResult(resultChan)
+}
@@ -0,0 +1,174 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	"github.com/capatazlib/go-capataz/cap/captest"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestSwapChild(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	// the new version fails once it receives a signal
+	failCh := make(chan struct{}, 1)
+	newChild1 := cap.NewWorker("ignored", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-failCh:
+			return errors.New("new version failed")
+		}
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), WaitDoneWorker("child2")),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	assert.NoError(t, sup.SwapChild("child1", newChild1, 10*time.Millisecond))
+
+	// failures of the new version are handled with the spec name of the child
+	failCh <- struct{}{}
+	evIt.WaitTill(WorkerStarted("root/child1"))
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1.next"),
+			WorkerSwapStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			WorkerSwapCompleted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			// the new version keeps the place of the old one in the start order
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestSwapChildStartFailure(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	// the old version keeps running when the new one fails to start
+	assert.Error(t, sup.SwapChild("child1", FailStartWorker("ignored"), 0))
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerStartFailed("root/child1.next"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestSwapChildNotFound(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1")),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	err = sup.SwapChild("unknown", WaitDoneWorker("ignored"), 0)
+	assert.True(t, errors.Is(err, cap.ErrNodeNotFound))
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestSwapChildSiblingFailureDuringOverlap(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	clock := captest.NewFakeClock(time.Now())
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	child2, failWorker2 := FailOnSignalWorker(1, "child2")
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), child2),
+		cap.WithStrategy(cap.OneForAll),
+		cap.WithClock(clock),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	evIt := evManager.Iterator()
+	evIt.WaitTill(SupervisorStarted("root"))
+
+	swapErrCh := make(chan error, 1)
+	go func() {
+		swapErrCh <- sup.SwapChild("child1", WaitDoneWorker("ignored"), time.Hour)
+	}()
+	evIt.WaitTill(WorkerSwapStarted("root/child1"))
+
+	// the new version is not restarted with the siblings of the failed child
+	failWorker2(false /* done */)
+	evIt.WaitTill(SupervisorGroupRestarted("root", "root/child2"))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.NoError(t, <-swapErrCh)
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/child1.next"),
+			WorkerSwapStarted("root/child1"),
+			WorkerFailed("root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child2"),
+			WorkerTerminated("root/child1"),
+			WorkerSwapCompleted("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,105 @@
+package s_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestTagsAreReportedOnEvents(t *testing.T) {
+	tags := map[string]string{"team": "payments", "tier": "critical"}
+	child1, failWorker1 := FailOnSignalWorker(1, "child1", cap.WithTags(tags))
+
+	// the worker keeps the tags it was built with
+	tags["tier"] = "best-effort"
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			failWorker1(true /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	expected := map[string]string{"team": "payments", "tier": "critical"}
+	for _, ev := range events {
+		switch ev.GetProcessRuntimeName() {
+		case "root/child1":
+			assert.Equal(t, expected, ev.GetTags(), ev.GetTag().String())
+		default:
+			assert.Nil(t, ev.GetTags())
+		}
+	}
+
+	// the tags of an event cannot be modified
+	startEv := findEvent(t, events, WorkerStarted("root/child1"))
+	startEv.GetTags()["tier"] = "best-effort"
+	assert.Equal(t, expected, startEv.GetTags())
+}
+
+func TestTagsAreReportedOnTopology(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(
+			cap.NewWorker(
+				"child1",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithTags(map[string]string{"team": "payments"}),
+				cap.WithShutdown(cap.Timeout(time.Second)),
+			),
+		),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	topology, err := sup.Topology()
+	assert.NoError(t, err)
+
+	if assert.Len(t, topology.GetChildren(), 1) {
+		assert.Equal(
+			t,
+			map[string]string{"team": "payments"},
+			topology.GetChildren()[0].GetTags(),
+		)
+	}
+
+	output, err := json.Marshal(topology)
+	assert.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{
+		  "name": "root",
+		  "runtime_name": "root",
+		  "tag": "Supervisor",
+		  "children": [
+		    {
+		      "name": "child1",
+		      "runtime_name": "root/child1",
+		      "tag": "Worker",
+		      "restart": "Permanent",
+		      "shutdown": "Timeout(1s)",
+		      "tags": {"team": "payments"}
+		    }
+		  ]
+		}`,
+		string(output),
+	)
+
+	assert.NoError(t, sup.Terminate())
+}
@@ -0,0 +1,78 @@
+package s
+
+// This file contains the implementation of the termination with timeout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// terminationDeadline keeps track of the time after which the supervisors of a
+// tree stop waiting for their children to terminate.
+type terminationDeadline struct {
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// set registers the termination deadline of the supervision tree
+func (td *terminationDeadline) set(deadline time.Time) {
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	td.deadline = deadline
+}
+
+// get returns the termination deadline of the supervision tree, the zero value
+// indicates there is no deadline
+func (td *terminationDeadline) get() time.Time {
+	if td == nil {
+		return time.Time{}
+	}
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	return td.deadline
+}
+
+// terminationDeadlineKey is the key used to store the terminationDeadline of a
+// supervision tree on the supervisor context
+var terminationDeadlineKey capatazSupKey = "__capataz.supervisor.termination_deadline__"
+
+// withTerminationDeadline sets the terminationDeadline in the context that is
+// thread-through the supervision tree
+func withTerminationDeadline(ctx context.Context, td *terminationDeadline) context.Context {
+	return context.WithValue(ctx, terminationDeadlineKey, td)
+}
+
+// getTerminationDeadline returns the terminationDeadline of the supervision
+// tree, nil if there is none
+func getTerminationDeadline(ctx context.Context) *terminationDeadline {
+	if td, ok := ctx.Value(terminationDeadlineKey).(*terminationDeadline); ok {
+		return td
+	}
+	return nil
+}
+
+// terminateChild terminates the given child, honoring the termination deadline
+// of the supervision tree if there is one
+func (spec SupervisorSpec) terminateChild(ch c.Child) (bool, error) {
+	defer spec.stallWatchdog.enter(stallChildTerminate)()
+	deadline := spec.terminationDeadline.get()
+	if deadline.IsZero() {
+		return ch.Terminate()
+	}
+	return ch.TerminateBefore(deadline)
+}
+
+// TerminateWithTimeout behaves like Terminate, with the difference that the
+// supervisors of the tree stop waiting for their children once the given
+// duration expires, regardless of the children Shutdown settings.
+//
+// The children that didn't terminate on time are abandoned (their goroutines
+// keep running), and they are reported in the returned
+// SupervisorTerminationError with errors that match ErrAbandoned.
+func (sup Supervisor) TerminateWithTimeout(d time.Duration) error {
+	sup.terminationDeadline.set(time.Now().Add(d))
+	return sup.Terminate()
+}
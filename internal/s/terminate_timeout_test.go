@@ -0,0 +1,150 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+// stuckWorker creates a worker that ignores termination for the given duration,
+// and that has a Shutdown setting that waits for it indefinitely
+func stuckWorker(name string, stuck time.Duration) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(stuck)
+			return nil
+		},
+		cap.WithShutdown(cap.Indefinitely),
+	)
+}
+
+func TestTerminateWithTimeout(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(WaitDoneWorker("child2"), stuckWorker("child3", 2*time.Second)),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(stuckWorker("child1", 2*time.Second), cap.Subtree(subtree)),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	terminateStart := time.Now()
+	err = sup.TerminateWithTimeout(100 * time.Millisecond)
+	assert.True(t, time.Since(terminateStart) < 1*time.Second)
+
+	assert.Error(t, err)
+	var terminationErr *cap.SupervisorTerminationError
+	assert.True(t, errors.As(err, &terminationErr))
+
+	kvs := terminationErr.KVs()
+	// the sub-tree is abandoned waiting for child3, and there is no time left
+	// to wait for child1
+	assert.Equal(t, "subtree", kvs["supervisor.termination.node.1.name"])
+	assert.Equal(t, true, kvs["supervisor.termination.node.1.abandoned"])
+	assert.Equal(t, "child1", kvs["supervisor.termination.node.0.name"])
+	assert.Equal(t, true, kvs["supervisor.termination.node.0.abandoned"])
+	assert.True(
+		t,
+		errors.Is(kvs["supervisor.termination.node.0.error"].(error), cap.ErrAbandoned),
+	)
+}
+
+func TestTerminateWithTimeoutHonorsShutdown(t *testing.T) {
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), stuckWorker("child2", 50*time.Millisecond)),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// all children terminate before the deadline
+	assert.NoError(t, sup.TerminateWithTimeout(1*time.Second))
+}
+
+// deadlineWorker creates a worker that reports the deadline of its context
+// once it gets terminated
+func deadlineWorker(name string, shutdown cap.Shutdown, deadlineCh chan<- *time.Time) cap.Node {
+	return cap.NewWorker(
+		name,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			if deadline, ok := ctx.Deadline(); ok {
+				deadlineCh <- &deadline
+			} else {
+				deadlineCh <- nil
+			}
+			return nil
+		},
+		cap.WithShutdown(shutdown),
+	)
+}
+
+func TestTerminateSetsShutdownDeadline(t *testing.T) {
+	t.Run("with timeout shutdown", func(t *testing.T) {
+		deadlineCh := make(chan *time.Time, 1)
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(deadlineWorker("child1", cap.Timeout(2*time.Second), deadlineCh)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		before := time.Now()
+		assert.NoError(t, sup.Terminate())
+		after := time.Now()
+
+		deadline := <-deadlineCh
+		if assert.NotNil(t, deadline) {
+			assert.False(t, deadline.Before(before.Add(2*time.Second)))
+			assert.False(t, deadline.After(after.Add(2*time.Second)))
+		}
+	})
+
+	t.Run("with indefinitely shutdown", func(t *testing.T) {
+		deadlineCh := make(chan *time.Time, 1)
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(deadlineWorker("child1", cap.Indefinitely, deadlineCh)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		assert.NoError(t, sup.Terminate())
+		assert.Nil(t, <-deadlineCh)
+	})
+
+	t.Run("with termination timeout", func(t *testing.T) {
+		deadlineCh := make(chan *time.Time, 1)
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(deadlineWorker("child1", cap.Indefinitely, deadlineCh)),
+		).Start(context.TODO())
+		assert.NoError(t, err)
+
+		before := time.Now()
+		assert.NoError(t, sup.TerminateWithTimeout(time.Second))
+		after := time.Now()
+
+		// the termination deadline applies, even when the worker could take
+		// indefinitely
+		deadline := <-deadlineCh
+		if assert.NotNil(t, deadline) {
+			assert.False(t, deadline.Before(before.Add(time.Second)))
+			assert.False(t, deadline.After(after.Add(time.Second)))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestToleranceExemptChild(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(3, "child1", cap.WithToleranceExempt())
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			cap.WithStrategy(cap.OneForAll),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			// more failures than the default restart tolerance allows
+			for i := 0; i < 3; i++ {
+				failWorker1(i == 2 /* done */)
+				evIt.WaitTill(WorkerFailed("root/child1"))
+				evIt.WaitTill(WorkerStarted("root/child2"))
+			}
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			// an exempt child still restarts its siblings
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorGroupRestarted("root", "root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -0,0 +1,147 @@
+package s
+
+// This file contains the implementation of the supervision tree topology
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// TreeNode represents a node of the topology of a running supervision tree,
+// with the restart and shutdown settings of the node. When the node is a
+// supervisor, it contains the topology of its running children.
+type TreeNode struct {
+	name        string
+	runtimeName string
+	tag         c.ChildTag
+	restart     c.Restart
+	shutdown    c.Shutdown
+	tags        map[string]string
+	root        bool
+	children    []TreeNode
+}
+
+// GetName returns the spec name of the node
+func (tn TreeNode) GetName() string {
+	return tn.name
+}
+
+// GetRuntimeName returns the runtime name of the node
+func (tn TreeNode) GetRuntimeName() string {
+	return tn.runtimeName
+}
+
+// GetTag returns the c.ChildTag of the node
+func (tn TreeNode) GetTag() c.ChildTag {
+	return tn.tag
+}
+
+// GetRestart returns the c.Restart setting of the node; the root supervisor is
+// reported as Permanent
+func (tn TreeNode) GetRestart() c.Restart {
+	return tn.restart
+}
+
+// GetShutdown returns the c.Shutdown setting of the node; the root supervisor
+// is reported with an Indefinitely shutdown
+func (tn TreeNode) GetShutdown() c.Shutdown {
+	return tn.shutdown
+}
+
+// GetTags returns a copy of the tags of the node (see c.WithTags); it is nil
+// when the node has no tags
+func (tn TreeNode) GetTags() map[string]string {
+	if len(tn.tags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(tn.tags))
+	for k, v := range tn.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// GetChildren returns the topology of the running children of the node, in
+// start order
+func (tn TreeNode) GetChildren() []TreeNode {
+	return tn.children
+}
+
+// treeNodeJSON is the JSON representation of a TreeNode
+type treeNodeJSON struct {
+	Name        string            `json:"name"`
+	RuntimeName string            `json:"runtime_name"`
+	Tag         string            `json:"tag"`
+	Restart     string            `json:"restart,omitempty"`
+	Shutdown    string            `json:"shutdown,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Children    []TreeNode        `json:"children,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of the node; the restart and
+// shutdown settings are omitted on the root supervisor, given it doesn't have
+// a parent supervisor
+func (tn TreeNode) MarshalJSON() ([]byte, error) {
+	value := treeNodeJSON{
+		Name:        tn.name,
+		RuntimeName: tn.runtimeName,
+		Tag:         tn.tag.String(),
+		Tags:        tn.tags,
+		Children:    tn.children,
+	}
+	if !tn.root {
+		value.Restart = tn.restart.String()
+		value.Shutdown = tn.shutdown.String()
+	}
+	return json.Marshal(value)
+}
+
+// topologyChildren returns the topology of the given children, sub-trees that
+// are not reachable (e.g. they are restarting) are reported without children
+func topologyChildren(trees []childTree) []TreeNode {
+	nodes := make([]TreeNode, 0, len(trees))
+	for _, tree := range trees {
+		nodes = append(nodes, TreeNode{
+			name:        tree.spec.GetName(),
+			runtimeName: tree.runtimeName,
+			tag:         tree.spec.GetTag(),
+			restart:     tree.spec.GetRestart(),
+			shutdown:    tree.spec.GetShutdown(),
+			tags:        tree.spec.GetTags(),
+			children:    topologyChildren(tree.children),
+		})
+	}
+	return nodes
+}
+
+// Topology returns the TreeNode of the running supervision tree, including the
+// children that were spawned dynamically. It fails when the supervisor is not
+// running, or when the tree doesn't report its topology within a second.
+func (sup Supervisor) Topology() (TreeNode, error) {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	trees, err := walkChildren(ctx, sup.ctrlCh)
+	if err != nil {
+		return TreeNode{}, err
+	}
+	return TreeNode{
+		name:        sup.GetName(),
+		runtimeName: sup.runtimeName,
+		tag:         c.Supervisor,
+		restart:     c.Permanent,
+		shutdown:    c.Indefinitely,
+		root:        true,
+		children:    topologyChildren(trees),
+	}, nil
+}
+
+// Topology returns the TreeNode of the running dynamic supervision tree,
+// including the children that were spawned dynamically.
+func (dyn DynSupervisor) Topology() (TreeNode, error) {
+	return dyn.sup.Topology()
+}
@@ -0,0 +1,103 @@
+package s_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestTopology(t *testing.T) {
+	subtree := cap.NewSupervisorSpec(
+		"subtree",
+		cap.WithNodes(
+			WaitDoneWorker("child1"),
+			cap.NewWorker(
+				"child2",
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				cap.WithRestart(cap.Transient),
+				cap.WithShutdown(cap.Timeout(2*time.Second)),
+			),
+		),
+	)
+
+	dyn, err := cap.NewDynSupervisorWithNodes(
+		context.TODO(),
+		"root",
+		cap.WithNodes(cap.Subtree(subtree)),
+	)
+	assert.NoError(t, err)
+
+	_, err = dyn.Spawn(WaitDoneWorker("dyn1"))
+	assert.NoError(t, err)
+
+	topology, err := dyn.Topology()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "root", topology.GetRuntimeName())
+	if assert.Len(t, topology.GetChildren(), 2) {
+		subtreeNode := topology.GetChildren()[0]
+		assert.Equal(t, "root/subtree", subtreeNode.GetRuntimeName())
+		assert.Equal(t, cap.SupervisorT, subtreeNode.GetTag())
+		assert.Len(t, subtreeNode.GetChildren(), 2)
+		// dynamically spawned children are part of the topology
+		assert.Equal(t, "root/dyn1", topology.GetChildren()[1].GetRuntimeName())
+	}
+
+	output, err := json.Marshal(topology)
+	assert.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{
+		  "name": "root",
+		  "runtime_name": "root",
+		  "tag": "Supervisor",
+		  "children": [
+		    {
+		      "name": "subtree",
+		      "runtime_name": "root/subtree",
+		      "tag": "Supervisor",
+		      "restart": "Permanent",
+		      "shutdown": "Indefinitely",
+		      "children": [
+		        {
+		          "name": "child1",
+		          "runtime_name": "root/subtree/child1",
+		          "tag": "Worker",
+		          "restart": "Permanent",
+		          "shutdown": "Timeout(5s)"
+		        },
+		        {
+		          "name": "child2",
+		          "runtime_name": "root/subtree/child2",
+		          "tag": "Worker",
+		          "restart": "Transient",
+		          "shutdown": "Timeout(2s)"
+		        }
+		      ]
+		    },
+		    {
+		      "name": "dyn1",
+		      "runtime_name": "root/dyn1",
+		      "tag": "Worker",
+		      "restart": "Permanent",
+		      "shutdown": "Timeout(5s)"
+		    }
+		  ]
+		}`,
+		string(output),
+	)
+
+	assert.NoError(t, dyn.Terminate())
+
+	_, err = dyn.Topology()
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestTransientBudgetExceeded(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithRestart(cap.Transient),
+		cap.WithTransientBudget(1, time.Minute),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{
+			// without the budget, the second failure would surpass the
+			// supervisor's tolerance
+			cap.WithRestartTolerance(1, time.Minute),
+		},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			// the first failure is within the budget
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// the second failure exceeds the budget, the child stays down
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestTransientBudgetWindowReset(t *testing.T) {
+	child1, failWorker1 := FailOnSignalWorker(
+		2,
+		"child1",
+		cap.WithRestart(cap.Transient),
+		cap.WithTransientBudget(1, 50*time.Millisecond),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1),
+		[]cap.Opt{cap.WithRestartTolerance(10, time.Minute)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+
+			// once the window is over, the failure count starts again
+			time.Sleep(60 * time.Millisecond)
+
+			failWorker1(false /* done */)
+			evIt.WaitTill(WorkerFailed("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			SupervisorStarted("root"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerFailed("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
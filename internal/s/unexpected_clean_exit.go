@@ -0,0 +1,51 @@
+package s
+
+// This file contains the handling of the clean exits of Transient children
+// (see WithUnexpectedCleanExit)
+
+import (
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// CleanExitAction specifies what a supervisor does when one of its Transient
+// children finishes without an error (see WithUnexpectedCleanExit)
+type CleanExitAction uint32
+
+const (
+	// CleanExitNotify reports a ChildExitedUnexpectedly event, the child is
+	// not restarted
+	CleanExitNotify CleanExitAction = iota
+	// CleanExitRestart reports a ChildExitedUnexpectedly event, and restarts
+	// the child as if it was a Permanent child
+	CleanExitRestart
+	// CleanExitEscalate reports a ChildExitedUnexpectedly event, and the
+	// supervisor gives up and fails with a SupervisorRestartError, regardless
+	// of its restart tolerance
+	CleanExitEscalate
+)
+
+func (a CleanExitAction) String() string {
+	switch a {
+	case CleanExitNotify:
+		return "Notify"
+	case CleanExitRestart:
+		return "Restart"
+	case CleanExitEscalate:
+		return "Escalate"
+	default:
+		return "<Unknown>"
+	}
+}
+
+// reportUnexpectedCleanExit reports the clean exit of the given child when it
+// is unexpected, and returns the action the supervisor must take for it. The
+// second result is false when the supervisor expects the exit.
+func (spec SupervisorSpec) reportUnexpectedCleanExit(sourceCh c.Child) (CleanExitAction, bool) {
+	if spec.cleanExitAction == nil || sourceCh.GetSpec().GetRestart() != c.Transient {
+		return CleanExitNotify, false
+	}
+	spec.getEventNotifier().withTags(sourceCh.GetSpec()).childExitedUnexpectedly(
+		sourceCh.GetTag(), sourceCh.GetRuntimeName(),
+	)
+	return *spec.cleanExitAction, true
+}
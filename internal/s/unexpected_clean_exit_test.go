@@ -0,0 +1,132 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestUnexpectedCleanExitNotify(t *testing.T) {
+	child1, completeWorker1 := CompleteOnSignalWorker(
+		1, "child1", cap.WithRestart(cap.Transient),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{cap.WithUnexpectedCleanExit(cap.CleanExitNotify)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			completeWorker1()
+			evIt.WaitTill(WorkerExitedUnexpectedly("root/child1"))
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerCompleted("root/child1"),
+			WorkerExitedUnexpectedly("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestUnexpectedCleanExitRestart(t *testing.T) {
+	child1, completeWorker1 := CompleteOnSignalWorker(
+		1, "child1", cap.WithRestart(cap.Transient),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{cap.WithUnexpectedCleanExit(cap.CleanExitRestart)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			completeWorker1()
+			evIt.WaitTill(WorkerExitedUnexpectedly("root/child1"))
+			evIt.WaitTill(WorkerStarted("root/child1"))
+			// the restarted worker waits for its termination
+			completeWorker1()
+		},
+	)
+
+	assert.NoError(t, err)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerCompleted("root/child1"),
+			WorkerExitedUnexpectedly("root/child1"),
+			WorkerStarted("root/child1"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestUnexpectedCleanExitEscalate(t *testing.T) {
+	child1, completeWorker1 := CompleteOnSignalWorker(
+		1, "child1", cap.WithRestart(cap.Transient),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(child1, WaitDoneWorker("child2")),
+		[]cap.Opt{cap.WithUnexpectedCleanExit(cap.CleanExitEscalate)},
+		func(em EventManager) {
+			evIt := em.Iterator()
+			evIt.WaitTill(SupervisorStarted("root"))
+			completeWorker1()
+			evIt.WaitTill(WorkerTerminated("root/child2"))
+		},
+	)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrUnexpectedCleanExit))
+	assert.Equal(
+		t,
+		"supervisor 'root' crashed due to an unexpected child exit.\n"+
+			"\tworker node 'root/child1' finished without errors, but it was not expected to finish.",
+		cap.ExplainError(err),
+	)
+
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerCompleted("root/child1"),
+			WorkerExitedUnexpectedly("root/child1"),
+			WorkerTerminated("root/child2"),
+			SupervisorFailed("root"),
+		},
+	)
+
+	supEv := findEvent(t, events, SupervisorFailed("root"))
+	assert.Equal(t, cap.ReasonUnexpectedCleanExit, supEv.ReasonCode())
+	assert.Equal(t, "UNEXPECTED_CLEAN_EXIT", supEv.ReasonCode().String())
+}
@@ -0,0 +1,49 @@
+package s
+
+// This file contains the implementation of the WaitStarted API
+
+import (
+	"context"
+	"fmt"
+)
+
+// waitChildrenStarted blocks until the supervisor that listens to the given
+// ctrlChan, and all its sub-trees, have their children started.
+//
+// A supervisor only handles control messages from its monitor loop once its
+// children are started (or restarted), so a reply to a listChildrenMsg means
+// the running children of that supervisor already acknowledged their start.
+func waitChildrenStarted(ctx context.Context, ctrlChan chan ctrlMsg) error {
+	trees, err := walkChildren(ctx, ctrlChan)
+	if err != nil {
+		return err
+	}
+	return checkChildrenStarted(trees)
+}
+
+// checkChildrenStarted returns an error when any of the given sub-trees didn't
+// report its children
+func checkChildrenStarted(trees []childTree) error {
+	for _, tree := range trees {
+		err := tree.subtreeErr
+		if err == nil {
+			err = checkChildrenStarted(tree.children)
+		}
+		if err != nil {
+			return fmt.Errorf("sub-tree %s is not started: %w", tree.runtimeName, err)
+		}
+	}
+	return nil
+}
+
+// WaitStarted blocks until every node of the supervision tree, including the
+// nodes of nested sub-trees, has notified its start. When a supervisor is in
+// the middle of a restart, this function waits until the restarted children
+// are started again.
+//
+// This function may be called at any point of the supervisor lifecycle (e.g.
+// after spawning workers on a DynSupervisor). It fails when the supervisor is
+// not running, or when the given context is done before the tree is started.
+func (sup Supervisor) WaitStarted(ctx context.Context) error {
+	return waitChildrenStarted(ctx, sup.ctrlCh)
+}
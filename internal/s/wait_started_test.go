@@ -0,0 +1,79 @@
+package s_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestWaitStartedDuringSubtreeRestart(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	var startCount int32
+	failCh := make(chan struct{})
+	child1 := cap.NewWorkerWithNotifyStart(
+		"child1",
+		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
+			if atomic.AddInt32(&startCount, 1) > 1 {
+				// restarts take a while
+				time.Sleep(200 * time.Millisecond)
+			}
+			notifyStart(nil)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-failCh:
+				return errors.New("child1 failed")
+			}
+		},
+	)
+	subtree := cap.NewSupervisorSpec("subtree", cap.WithNodes(child1))
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(WaitDoneWorker("child0"), cap.Subtree(subtree)),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	// the tree is started after the Start call
+	assert.NoError(t, sup.WaitStarted(ctx))
+
+	evIt := evManager.Iterator()
+	failCh <- struct{}{}
+	evIt.WaitTill(WorkerFailed("root/subtree/child1"))
+
+	// the sub-tree is restarting its child
+	shortCtx, shortCancelFn := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer shortCancelFn()
+	err = sup.WaitStarted(shortCtx)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	assert.NoError(t, sup.WaitStarted(ctx))
+
+	// the restarted child notified its start before WaitStarted returned
+	started := 0
+	for _, ev := range evManager.Snapshot() {
+		if WorkerStarted("root/subtree/child1").Call(ev) {
+			started++
+		}
+	}
+	assert.Equal(t, 2, started)
+
+	assert.NoError(t, sup.Terminate())
+
+	// the supervisor is not running
+	assert.Error(t, sup.WaitStarted(ctx))
+}
@@ -0,0 +1,125 @@
+package s
+
+// This file contains the implementation of the WithWatchdog option
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// stallNotifier labels a stall on a call to the event notifier
+	stallNotifier = "notifier"
+	// stallChildStart labels a stall on the start of a child
+	stallChildStart = "child-start"
+	// stallChildTerminate labels a stall on the termination of a child
+	stallChildTerminate = "child-terminate"
+	// stallResourceCleanup labels a stall on the resource cleanup of the
+	// supervisor
+	stallResourceCleanup = "resource-cleanup"
+)
+
+// watchdogSettings contains the settings of WithWatchdog
+type watchdogSettings struct {
+	timeout time.Duration
+	onStall func(string)
+}
+
+// stallWatchdog keeps track of the operation the supervisor loop is running,
+// and reports the operations that take longer than the watchdog timeout.
+type stallWatchdog struct {
+	settings watchdogSettings
+
+	mu       sync.Mutex
+	where    string
+	since    time.Time
+	reported bool
+
+	doneCh chan struct{}
+}
+
+// newStallWatchdog starts the watchdog of a supervisor with the settings of
+// the given spec; it returns nil when the spec has no watchdog.
+func (spec SupervisorSpec) newStallWatchdog() *stallWatchdog {
+	if spec.watchdog == nil || spec.watchdog.onStall == nil {
+		return nil
+	}
+	wd := &stallWatchdog{
+		settings: *spec.watchdog,
+		doneCh:   make(chan struct{}),
+	}
+	go wd.run()
+	return wd
+}
+
+// run checks periodically if the current operation of the supervisor loop
+// takes longer than the watchdog timeout
+func (wd *stallWatchdog) run() {
+	interval := wd.settings.timeout / 4
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wd.doneCh:
+			return
+		case now := <-ticker.C:
+			wd.mu.Lock()
+			stalled := wd.where != "" &&
+				!wd.reported &&
+				now.Sub(wd.since) >= wd.settings.timeout
+			where := wd.where
+			if stalled {
+				// every operation is reported at most once
+				wd.reported = true
+			}
+			wd.mu.Unlock()
+
+			if stalled {
+				wd.settings.onStall(where)
+			}
+		}
+	}
+}
+
+// enter registers the start of an operation of the supervisor loop, the
+// returned function registers its end.
+func (wd *stallWatchdog) enter(where string) func() {
+	if wd == nil {
+		return func() {}
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	prevWhere, prevSince, prevReported := wd.where, wd.since, wd.reported
+	wd.where, wd.since, wd.reported = where, time.Now(), false
+
+	return func() {
+		wd.mu.Lock()
+		defer wd.mu.Unlock()
+		// nested operations (e.g. a notifier call on a child start) resume the
+		// tracking of the outer operation
+		wd.where, wd.since, wd.reported = prevWhere, prevSince, prevReported
+	}
+}
+
+// watchNotifier returns an EventNotifier that registers its calls to the given
+// EventNotifier on the watchdog
+func (wd *stallWatchdog) watchNotifier(en EventNotifier) EventNotifier {
+	return func(ev Event) {
+		defer wd.enter(stallNotifier)()
+		en(ev)
+	}
+}
+
+// stop finishes the watchdog goroutine
+func (wd *stallWatchdog) stop() {
+	if wd == nil {
+		return
+	}
+	close(wd.doneCh)
+}
@@ -0,0 +1,113 @@
+package s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestWatchdogBlockedNotifier(t *testing.T) {
+	stallCh := make(chan string, 10)
+	releaseCh := make(chan struct{})
+
+	notifier := func(ev cap.Event) {
+		if ev.GetTag() == cap.ProcessStarted && ev.GetProcessRuntimeName() == "root" {
+			<-releaseCh
+		}
+	}
+
+	type startResult struct {
+		sup cap.Supervisor
+		err error
+	}
+	startCh := make(chan startResult, 1)
+
+	go func() {
+		sup, err := cap.NewSupervisorSpec(
+			"root",
+			cap.WithNodes(WaitDoneWorker("child1")),
+			cap.WithNotifier(notifier),
+			cap.WithWatchdog(10*time.Millisecond, func(where string) {
+				stallCh <- where
+			}),
+		).Start(context.TODO())
+		startCh <- startResult{sup: sup, err: err}
+	}()
+
+	assert.Equal(t, "notifier", <-stallCh)
+	close(releaseCh)
+
+	result := <-startCh
+	assert.NoError(t, result.err)
+	assert.NoError(t, result.sup.Terminate())
+
+	// the stalled notifier call is reported once
+	assert.Equal(t, 0, len(stallCh))
+}
+
+func TestWatchdogSlowChildTermination(t *testing.T) {
+	stallCh := make(chan string, 10)
+
+	worker := cap.NewWorker(
+		"child1",
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			// the worker takes its time to cleanup
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		},
+		cap.WithShutdown(cap.Timeout(time.Second)),
+	)
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(worker),
+		cap.WithWatchdog(10*time.Millisecond, func(where string) {
+			stallCh <- where
+		}),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	assert.NoError(t, sup.Terminate())
+	assert.Equal(t, "child-terminate", <-stallCh)
+}
+
+func TestWatchdogNoStall(t *testing.T) {
+	stallCh := make(chan string, 10)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(WaitDoneWorker("child1"), WaitDoneWorker("child2")),
+		[]cap.Opt{
+			cap.WithWatchdog(time.Second, func(where string) {
+				stallCh <- where
+			}),
+		},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(stallCh))
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/child1"),
+			WorkerStarted("root/child2"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/child2"),
+			WorkerTerminated("root/child1"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestWithWatchdogInvalidDuration(t *testing.T) {
+	assert.Panics(t, func() {
+		cap.WithWatchdog(0, func(string) {})
+	})
+}
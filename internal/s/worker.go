@@ -14,6 +14,14 @@ import (
 // See the documentation of NewWorkerWithNotifyStart for more details
 type NotifyStartFn = c.NotifyStartFn
 
+// StartFn is the start function of a worker node (see NewWorkerWithNotifyStart)
+type StartFn = func(context.Context, NotifyStartFn) error
+
+// StartMiddleware wraps the start function of a worker node with
+// cross-cutting logic (e.g. logging, timing metrics, context enrichment). See
+// WithStartMiddleware for more details.
+type StartMiddleware = func(next StartFn) StartFn
+
 // childToNode transforms a c.ChildSpec into a Node.
 func childToNode(chSpec c.ChildSpec) Node {
 	return func(_ SupervisorSpec) c.ChildSpec {
@@ -49,6 +57,19 @@ func NewWorker(name string, startFn func(context.Context) error, opts ...c.Opt)
 	return childToNode(c.New(name, startFn, opts...))
 }
 
+// PauseSignal is a value that a supervisor sends to a pausable worker to pause
+// or resume its processing.
+//
+// See the documentation of NewPausableWorker for more details
+type PauseSignal = c.PauseSignal
+
+// PauseRequested indicates a worker must stop its processing (without releasing
+// its resources) until it gets a ResumeRequested signal
+const PauseRequested = c.PauseRequested
+
+// ResumeRequested indicates a paused worker must resume its processing
+const ResumeRequested = c.ResumeRequested
+
 // NewWorkerWithNotifyStart accomplishes the same goal as NewWorker with the
 // addition of passing an extra argument (notifyStart callback) to the startFn
 // function parameter.
@@ -70,6 +91,10 @@ func NewWorker(name string, startFn func(context.Context) error, opts ...c.Opt)
 // connection fails, network is kaput), the node may call the given
 // NotifyStartFn function with the impending error as a parameter. This will
 // cause the whole supervision system start procedure to abort.
+//
+// A startFn function that returns before calling the NotifyStartFn callback
+// fails to start with the returned error, or with an error that matches
+// ErrExitedBeforeStart when it returns nil.
 func NewWorkerWithNotifyStart(
 	name string,
 	startFn func(context.Context, NotifyStartFn) error,
@@ -77,3 +102,44 @@ func NewWorkerWithNotifyStart(
 ) Node {
 	return childToNode(c.NewWithNotifyStart(name, startFn, opts...))
 }
+
+// NewTypedWorker accomplishes the same goal as NewWorkerWithNotifyStart with
+// the addition of passing the given deps value to the startFn function
+// parameter, so that the dependencies of a worker are given explicitly and
+// type-checked, rather than captured in a closure.
+//
+// The same deps value is given to the startFn function on every (re)start of
+// the worker.
+func NewTypedWorker[T any](
+	name string,
+	deps T,
+	startFn func(context.Context, T, NotifyStartFn) error,
+	opts ...c.Opt,
+) Node {
+	return NewWorkerWithNotifyStart(
+		name,
+		func(ctx context.Context, notifyStart NotifyStartFn) error {
+			return startFn(ctx, deps, notifyStart)
+		},
+		opts...,
+	)
+}
+
+// NewPausableWorker accomplishes the same goal as NewWorker with the addition
+// of passing a channel to the startFn function parameter, from where the
+// worker receives the signals sent with Supervisor.PauseChild and
+// Supervisor.ResumeChild.
+//
+// # The pause channel argument
+//
+// When the worker receives a PauseRequested signal, it should stop its
+// processing, keeping the resources it has allocated, until it receives a
+// ResumeRequested signal. A paused worker must keep honoring the given
+// context.Context, so that it can be terminated while it is paused.
+func NewPausableWorker(
+	name string,
+	startFn func(context.Context, <-chan PauseSignal) error,
+	opts ...c.Opt,
+) Node {
+	return childToNode(c.NewWithPause(name, startFn, opts...))
+}
@@ -0,0 +1,270 @@
+package s
+
+// This file contains the implementation of worker pools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/capatazlib/go-capataz/internal/c"
+)
+
+// workerPools keeps track of the builders of the worker pools of a supervisor,
+// so that the supervisor is able to spawn new members at runtime.
+type workerPools struct {
+	mu       sync.Mutex
+	builders map[string]func(int) c.ChildSpec
+}
+
+// newWorkerPools creates an empty workerPools registry
+func newWorkerPools() *workerPools {
+	return &workerPools{builders: make(map[string]func(int) c.ChildSpec)}
+}
+
+// register stores the builder of the worker pool with the given name prefix
+func (wp *workerPools) register(namePrefix string, build func(int) c.ChildSpec) {
+	if wp == nil {
+		return
+	}
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	wp.builders[namePrefix] = build
+}
+
+// get returns the builder of the worker pool with the given name prefix
+func (wp *workerPools) get(namePrefix string) (func(int) c.ChildSpec, bool) {
+	if wp == nil {
+		return nil, false
+	}
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	build, ok := wp.builders[namePrefix]
+	return build, ok
+}
+
+// poolMemberName returns the name of the worker of a pool at the given index
+func poolMemberName(namePrefix string, i int) string {
+	return fmt.Sprintf("%s-%d", namePrefix, i)
+}
+
+// NewWorkerPool returns the given number of worker nodes, named with the given
+// prefix and the index of the worker (e.g. prefix-0, prefix-1, etc.). The
+// build function receives the index of each worker, the name of the node it
+// returns is replaced with the pool member name.
+//
+// The size of the pool may be changed at runtime with Supervisor.ResizePool.
+// When the given size is zero, a single node that doesn't start any worker is
+// returned, so that the pool is known to the supervisor and it can grow later.
+func NewWorkerPool(namePrefix string, size int, build func(i int) Node) []Node {
+	if size <= 0 {
+		return []Node{poolRegistration(namePrefix, build)}
+	}
+	nodes := make([]Node, 0, size)
+	for i := 0; i < size; i++ {
+		nodes = append(nodes, poolMember(namePrefix, i, build))
+	}
+	return nodes
+}
+
+// poolRegistration returns a node that registers the builder of a pool in the
+// supervisor without starting a worker; the supervisor discards the child spec
+// of this node (see isPoolRegistration).
+func poolRegistration(namePrefix string, build func(int) Node) Node {
+	return func(supSpec SupervisorSpec) c.ChildSpec {
+		registerPoolMember(supSpec, namePrefix, build)
+		return c.ChildSpec{}.InPool(namePrefix, -1)
+	}
+}
+
+// isPoolRegistration indicates if the given child spec was returned by a
+// poolRegistration node, in which case it is not a child of the supervisor
+func isPoolRegistration(chSpec c.ChildSpec) bool {
+	return chSpec.GetPoolName() != "" && chSpec.GetPoolIndex() < 0
+}
+
+// poolMember returns the node of the worker of a pool at the given index
+func poolMember(namePrefix string, i int, build func(int) Node) Node {
+	return func(supSpec SupervisorSpec) c.ChildSpec {
+		registerPoolMember(supSpec, namePrefix, build)
+		chSpec := build(i)(supSpec)
+		chSpec.Name = poolMemberName(namePrefix, i)
+		return chSpec.InPool(namePrefix, i)
+	}
+}
+
+// registerPoolMember registers the builder of the workers of a pool in the
+// given supervisor, the workers built at runtime get the same settings as the
+// ones built with the supervisor children (see SupervisorSpec.buildChildSpec).
+func registerPoolMember(supSpec SupervisorSpec, namePrefix string, build func(int) Node) {
+	supSpec.workerPools.register(namePrefix, func(j int) c.ChildSpec {
+		return supSpec.buildChildSpec(poolMember(namePrefix, j, build))
+	})
+}
+
+// resizePoolMsg is a message sent from clients to tell a supervisor to change
+// the number of workers of one of its pools.
+type resizePoolMsg struct {
+	namePrefix string
+	newSize    int
+	resultChan chan<- error
+}
+
+func (rpm resizePoolMsg) processMsg(
+	supCtx context.Context,
+	evNotifier EventNotifier,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+) ([]c.ChildSpec, map[string]c.Child) {
+	// REMEMBER: WE ARE RUNNING THIS CODE IN THE SUPERVISOR THREAD
+
+	var err error
+	build, ok := spec.workerPools.get(rpm.namePrefix)
+	if !ok {
+		err = c.WrapSentinel(ErrNodeNotFound, nil, "worker pool %s not found", rpm.namePrefix)
+	} else {
+		specChildren, err = resizePool(
+			supCtx, spec, specChildren, supRuntimeName, supChildren, supNotifyChan,
+			rpm.namePrefix, rpm.newSize, build,
+		)
+	}
+
+	// do not block waiting for a read
+	select {
+	case rpm.resultChan <- err:
+	default:
+	}
+
+	return specChildren, supChildren
+}
+
+var _ ctrlMsg = resizePoolMsg{}
+
+// resizePool spawns or terminates the workers of a pool until it has the given
+// size. Workers are spawned in index order, and terminated from the highest
+// index to the lowest one.
+func resizePool(
+	supCtx context.Context,
+	spec SupervisorSpec,
+	specChildren []c.ChildSpec,
+	supRuntimeName string,
+	supChildren map[string]c.Child,
+	supNotifyChan chan c.ChildNotification,
+	namePrefix string,
+	newSize int,
+	build func(int) c.ChildSpec,
+) ([]c.ChildSpec, error) {
+	members := make(map[int]c.ChildSpec)
+	for _, chSpec := range specChildren {
+		if chSpec.GetPoolName() == namePrefix {
+			members[chSpec.GetPoolIndex()] = chSpec
+		}
+	}
+
+	// spawn the missing workers
+	for i := 0; i < newSize; i++ {
+		if _, ok := members[i]; ok {
+			continue
+		}
+		chSpec := build(i)
+		// the new worker is validated with its siblings, as the children that
+		// are built when the supervisor starts
+		siblings := append(append([]c.ChildSpec{}, specChildren...), chSpec)
+		if err := validateChildSpecs(siblings); err != nil {
+			return specChildren, err
+		}
+		// spawned workers are not part of a restart
+		startCtx := withRestartMark(supCtx, false)
+		ch, startErr := startChildNode(
+			startCtx, spec, supRuntimeName, supNotifyChan, chSpec, nil,
+		)
+		if startErr != nil {
+			drainStartFailure(startErr, supNotifyChan)
+			return specChildren, startErr
+		}
+		specChildren = append(specChildren, chSpec)
+		supChildren[ch.GetName()] = ch
+	}
+
+	// terminate the extra workers, highest index first; each worker is given
+	// the time of its Shutdown setting to finish its in-flight work
+	indexes := make([]int, 0, len(members))
+	for i := range members {
+		if i >= newSize {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	nodeErrMap := make(map[string]error)
+	for _, i := range indexes {
+		name := members[i].GetName()
+		if ch, ok := supChildren[name]; ok {
+			// a worker that fails to terminate is not part of the pool anymore,
+			// we report it and continue with the others
+			if err := terminateChildNode(spec, ch); err != nil {
+				nodeErrMap[name] = err
+			}
+			delete(supChildren, name)
+		}
+		specChildren = removeChildSpec(specChildren, name)
+	}
+
+	if len(nodeErrMap) > 0 {
+		return specChildren, &SupervisorTerminationError{
+			supRuntimeName: supRuntimeName,
+			nodeSep:        spec.getNameSeparator(),
+			nodeErrMap:     nodeErrMap,
+		}
+	}
+
+	return specChildren, nil
+}
+
+// removeChildSpec returns a copy of the given specs without the spec with the
+// given name; the given slice is not modified, its backing array may be shared
+// with other slices of the supervisor children.
+func removeChildSpec(specChildren []c.ChildSpec, name string) []c.ChildSpec {
+	for i, chSpec := range specChildren {
+		if chSpec.GetName() == name {
+			result := make([]c.ChildSpec, 0, len(specChildren)-1)
+			result = append(result, specChildren[:i]...)
+			return append(result, specChildren[i+1:]...)
+		}
+	}
+	return specChildren
+}
+
+// ResizePool spawns or terminates workers of the pool with the given name
+// prefix (see NewWorkerPool) until the pool has the given number of workers.
+// New workers get the lowest free indexes.
+//
+// When the pool shrinks, the workers with the highest indexes are terminated
+// one at a time, waiting for each worker as specified by its Shutdown setting,
+// so that workers may finish their in-flight work. Workers that fail to
+// terminate are removed from the pool as well, and they are reported in the
+// returned SupervisorTerminationError.
+func (sup Supervisor) ResizePool(namePrefix string, newSize int) error {
+	// REMEMBER: WE ARE RUNNING ON THE CLIENT API THREAD
+	if newSize < 0 {
+		return fmt.Errorf("invalid pool size %d", newSize)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelFn()
+
+	// we initialize the resultChan with a buffer of 1, we may store the result
+	// before the client is ready to read it.
+	resultChan := make(chan error, 1)
+	msg := resizePoolMsg{namePrefix: namePrefix, newSize: newSize, resultChan: resultChan}
+	if err := sendCtrlMsg(ctx, sup.ctrlCh, msg); err != nil {
+		return err
+	}
+
+	return sup.waitCtrlResult(resultChan)
+}
@@ -0,0 +1,238 @@
+package s_test
+
+//
+// NOTE: If you feel it is counter-intuitive to have workers start before
+// supervisors in the assertions bellow, check stest/README.md
+//
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/capatazlib/go-capataz/cap"
+	. "github.com/capatazlib/go-capataz/internal/stest"
+)
+
+func TestWorkerPoolResize(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	pool := cap.NewWorkerPool("worker", 2, func(int) cap.Node {
+		return WaitDoneWorker("ignored")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(append(pool, WaitDoneWorker("other"))...),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	// grow the pool
+	assert.NoError(t, sup.ResizePool("worker", 4))
+	// shrink the pool, the workers with highest index go first
+	assert.NoError(t, sup.ResizePool("worker", 1))
+	// the pool may be empty, and grow again
+	assert.NoError(t, sup.ResizePool("worker", 0))
+	assert.NoError(t, sup.ResizePool("worker", 1))
+
+	err = sup.ResizePool("unknown", 1)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrNodeNotFound))
+	assert.Error(t, sup.ResizePool("worker", -1))
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/worker-0"),
+			WorkerStarted("root/worker-1"),
+			WorkerStarted("root/other"),
+			SupervisorStarted("root"),
+			// grow to 4
+			WorkerStarted("root/worker-2"),
+			WorkerStarted("root/worker-3"),
+			// shrink to 1
+			WorkerTerminated("root/worker-3"),
+			WorkerTerminated("root/worker-2"),
+			WorkerTerminated("root/worker-1"),
+			// shrink to 0
+			WorkerTerminated("root/worker-0"),
+			// grow to 1
+			WorkerStarted("root/worker-0"),
+			WorkerTerminated("root/worker-0"),
+			WorkerTerminated("root/other"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestWorkerPoolResizeDuplicateName(t *testing.T) {
+	pool := cap.NewWorkerPool("worker", 1, func(int) cap.Node {
+		return WaitDoneWorker("ignored")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(append(pool, WaitDoneWorker("worker-1"))...),
+	).Start(context.TODO())
+	assert.NoError(t, err)
+
+	// the new worker would have the name of a sibling
+	err = sup.ResizePool("worker", 2)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, cap.ErrInvalidChildSpec))
+
+	assert.NoError(t, sup.Terminate())
+}
+
+func TestWorkerPoolGrowsFromZero(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	pool := cap.NewWorkerPool("worker", 0, func(int) cap.Node {
+		return WaitDoneWorker("ignored")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(append(pool, WaitDoneWorker("other"))...),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, sup.ResizePool("worker", 2))
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/other"),
+			SupervisorStarted("root"),
+			WorkerStarted("root/worker-0"),
+			WorkerStarted("root/worker-1"),
+			WorkerTerminated("root/worker-1"),
+			WorkerTerminated("root/worker-0"),
+			WorkerTerminated("root/other"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
+
+func TestWorkerPoolResizeDrainsWorkers(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	jobs := make(chan int)
+	picked := make(chan int)
+	var mu sync.Mutex
+	// completed keeps track of the workers that completed their job
+	completed := make(map[int]bool)
+
+	pool := cap.NewWorkerPool("worker", 3, func(i int) cap.Node {
+		return cap.NewWorker(
+			"ignored",
+			func(ctx context.Context) error {
+				for {
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-jobs:
+						picked <- i
+						// the in-flight job finishes even when the worker is
+						// asked to terminate
+						time.Sleep(50 * time.Millisecond)
+						mu.Lock()
+						completed[i] = true
+						mu.Unlock()
+					}
+				}
+			},
+			cap.WithShutdown(cap.Timeout(1*time.Second)),
+		)
+	})
+
+	sup, err := cap.NewSupervisorSpec("root", cap.WithNodes(pool...)).Start(ctx)
+	assert.NoError(t, err)
+
+	// every worker gets a job
+	for i := 0; i < 3; i++ {
+		jobs <- i
+		<-picked
+	}
+
+	assert.NoError(t, sup.ResizePool("worker", 1))
+
+	// the workers that got terminated completed their jobs
+	mu.Lock()
+	assert.True(t, completed[1])
+	assert.True(t, completed[2])
+	mu.Unlock()
+
+	assert.NoError(t, sup.Terminate())
+
+	mu.Lock()
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, completed)
+	mu.Unlock()
+}
+
+func TestWorkerPoolResizeReportsStuckWorkers(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+
+	evManager := NewEventManager()
+	evManager.StartCollector(ctx)
+
+	pool := cap.NewWorkerPool("worker", 3, func(i int) cap.Node {
+		if i == 2 {
+			return NeverTerminateWorker("ignored")
+		}
+		return WaitDoneWorker("ignored")
+	})
+
+	sup, err := cap.NewSupervisorSpec(
+		"root",
+		cap.WithNodes(pool...),
+		cap.WithNotifier(evManager.EventCollector(ctx)),
+	).Start(ctx)
+	assert.NoError(t, err)
+
+	err = sup.ResizePool("worker", 1)
+	assert.Error(t, err)
+
+	var terminationErr *cap.SupervisorTerminationError
+	assert.True(t, errors.As(err, &terminationErr))
+	kvs := terminationErr.KVs()
+	assert.Equal(t, "worker-2", kvs["supervisor.termination.node.0.name"])
+	assert.True(
+		t,
+		errors.Is(kvs["supervisor.termination.node.0.error"].(error), cap.ErrShutdownTimeout),
+	)
+
+	assert.NoError(t, sup.Terminate())
+
+	AssertExactMatch(t, evManager.Snapshot(),
+		[]EventP{
+			WorkerStarted("root/worker-0"),
+			WorkerStarted("root/worker-1"),
+			WorkerStarted("root/worker-2"),
+			SupervisorStarted("root"),
+			WorkerAbandonedOnShutdown("root/worker-2"),
+			WorkerFailed("root/worker-2"),
+			// the resize continues after the failure
+			WorkerTerminated("root/worker-1"),
+			WorkerTerminated("root/worker-0"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
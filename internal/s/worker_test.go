@@ -111,6 +111,9 @@ func TestWorkerContextCanGetNameOnNestedSubtree(t *testing.T) {
 			name, ok := cap.GetWorkerName(ctx)
 			assert.True(t, ok)
 			assert.Equal(t, fmt.Sprintf("%s/%s", prefix, expected), name)
+			supName, ok := cap.SupervisorNameFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, prefix, supName)
 			<-ctx.Done()
 			return nil
 		})
@@ -156,3 +159,43 @@ func TestWorkerContextCanGetNameOnNestedSubtree(t *testing.T) {
 		},
 	)
 }
+
+type typedWorkerDeps struct {
+	greeting string
+	out      chan<- string
+}
+
+func TestTypedWorkerReceivesDeps(t *testing.T) {
+	out := make(chan string, 1)
+
+	worker := cap.NewTypedWorker(
+		"one",
+		typedWorkerDeps{greeting: "hello", out: out},
+		func(ctx context.Context, deps typedWorkerDeps, notifyStart cap.NotifyStartFn) error {
+			deps.out <- deps.greeting
+			notifyStart(nil)
+			<-ctx.Done()
+			return nil
+		},
+		cap.WithRestart(cap.Transient),
+	)
+
+	events, err := ObserveSupervisor(
+		context.TODO(),
+		"root",
+		cap.WithNodes(worker),
+		[]cap.Opt{},
+		func(EventManager) {},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", <-out)
+	AssertExactMatch(t, events,
+		[]EventP{
+			WorkerStarted("root/one"),
+			SupervisorStarted("root"),
+			WorkerTerminated("root/one"),
+			SupervisorTerminated("root"),
+		},
+	)
+}
@@ -57,6 +57,22 @@ func (p ProcessNodeTagP) String() string {
 	return fmt.Sprintf("nodeTag == %s", p.nodeTag)
 }
 
+// TriggeringChildP is a predicate that asserts the triggering child of a
+// GroupRestarted event is the one specified
+type TriggeringChildP struct {
+	name string
+}
+
+// Call will execute predicate that checks the runtime name of the child that
+// triggered a group restart
+func (p TriggeringChildP) Call(ev cap.Event) bool {
+	return ev.GetTriggeringChild() == p.name
+}
+
+func (p TriggeringChildP) String() string {
+	return fmt.Sprintf("triggeringChild == %s", p.name)
+}
+
 // ErrorMsgP is a predicate that asserts the message of an error is the one
 // specified
 type ErrorMsgP struct {
@@ -202,3 +218,258 @@ func WorkerStartFailed(name string) smtest.EventP[cap.Event] {
 		},
 	}
 }
+
+// WorkerPaused is a predicate to assert an event represents a worker process
+// that got paused by its supervisor
+func WorkerPaused(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ProcessPaused},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerResumed is a predicate to assert an event represents a worker process
+// that got resumed by its supervisor
+func WorkerResumed(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ProcessResumed},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerRetired is a predicate to assert an event represents a worker process
+// that is not restarted because its retirement time passed
+func WorkerRetired(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ProcessRetired},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// DynChildrenDiscarded is a predicate to assert an event represents a
+// supervisor that discarded its spawned children on a restart
+func DynChildrenDiscarded(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.DynChildrenDiscarded},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Supervisor},
+		},
+	}
+}
+
+// WorkerRestartedManually is a predicate to assert an event represents a
+// worker process that was restarted on request of a client
+func WorkerRestartedManually(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildRestartedManually},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerRestartedPeriodically is a predicate to assert an event represents a
+// worker process that was restarted because its periodic restart interval
+// expired
+func WorkerRestartedPeriodically(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildRestartedPeriodically},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerSwapStarted is a predicate to assert an event represents a worker
+// process with a new version running next to it
+func WorkerSwapStarted(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildSwapStarted},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerSwapCompleted is a predicate to assert an event represents a worker
+// process that was replaced by its new version
+func WorkerSwapCompleted(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildSwapCompleted},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerAbandonedOnShutdown is a predicate to assert an event represents a
+// worker process that didn't finish before its shutdown timeout expired
+func WorkerAbandonedOnShutdown(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildAbandonedOnShutdown},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerRestartRequested is a predicate to assert an event represents a worker
+// process that was restarted because it requested its own restart
+func WorkerRestartRequested(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildRestartRequested},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// SupervisorGroupRestarted is a predicate to assert an event represents a
+// supervisor that restarted all its children because the given child finished
+func SupervisorGroupRestarted(name string, triggering string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.GroupRestarted},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Supervisor},
+			TriggeringChildP{name: triggering},
+		},
+	}
+}
+
+// WorkerExitedUnexpectedly is a predicate to assert an event represents a
+// Transient worker process that finished without errors unexpectedly
+func WorkerExitedUnexpectedly(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildExitedUnexpectedly},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// SubtreeEscalated is a predicate to assert an event represents a sub-tree
+// that gave up on its children, and its supervisor applied an escalation
+// policy
+func SubtreeEscalated(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.SubtreeEscalated},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Supervisor},
+		},
+	}
+}
+
+// WorkerCircuitOpened is a predicate to assert an event represents a worker
+// process with an open circuit breaker
+func WorkerCircuitOpened(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildCircuitOpened},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerCircuitHalfOpened is a predicate to assert an event represents a worker
+// process that is started as the trial of its circuit breaker
+func WorkerCircuitHalfOpened(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildCircuitHalfOpened},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerCircuitClosed is a predicate to assert an event represents a worker
+// process that passed the trial of its circuit breaker
+func WorkerCircuitClosed(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildCircuitClosed},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerSwitchedToFallback is a predicate to assert an event represents a
+// worker process that is restarted with its fallback start function
+func WorkerSwitchedToFallback(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildSwitchedToFallback},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerEnteredBackoff is a predicate to assert an event represents a worker
+// process that is waiting for a restart dampening window to be over
+func WorkerEnteredBackoff(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildEnteredBackoff},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// SupervisorEnteredBackoff is a predicate to assert an event represents a
+// sub-tree that waits for the restart dampening window of its parent
+func SupervisorEnteredBackoff(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildEnteredBackoff},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Supervisor},
+		},
+	}
+}
+
+// WorkerExitedBackoff is a predicate to assert an event represents a worker
+// process that is no longer waiting for a restart dampening window
+func WorkerExitedBackoff(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ChildExitedBackoff},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
+
+// WorkerUnhealthy is a predicate to assert an event represents a worker process
+// with a failing health probe
+func WorkerUnhealthy(name string) EventP {
+	return AndP{
+		Preds: []EventP{
+			EventTagP{tag: cap.ProcessUnhealthy},
+			ProcessNameP{name: name},
+			ProcessNodeTagP{nodeTag: c.Worker},
+		},
+	}
+}
@@ -21,6 +21,11 @@ type AndP = smtest.AndP[cap.Event]
 // predicate match 1 to 1 with a given list of supervision system events.
 var AssertExactMatch = smtest.AssertExactMatch[cap.Event]
 
+// AssertPartialMatch is an assertion that checks the input slice of EventP
+// predicates match in order with a given list of supervision system events,
+// skipping the events that do not match.
+var AssertPartialMatch = smtest.AssertPartialMatch[cap.Event]
+
 // NewEventManager returns an EventManager instance that can be used to wait for
 // events to happen on the observed supervision system
 var NewEventManager = smtest.NewEventManager[cap.Event]
@@ -126,14 +126,11 @@ func PanicStartWorker(name string) cap.Node {
 	cspec := cap.NewWorkerWithNotifyStart(
 		name,
 		func(ctx context.Context, notifyStart cap.NotifyStartFn) error {
-			err := fmt.Errorf("PanicStartWorker %s", name)
-			panic(err)
-			// NOTE: Even though we return the err value here, this err will never be
-			// caught by our supervisor restart logic. If we invoke notifyStart with a
-			// non-nil err, the supervisor will never get to the supervision loop, but
-			// instead is going to terminate all started children and abort the
-			// bootstrap of the supervision tree.
-			return err
+			// NOTE: The panic error will be reported as a start error, the
+			// supervisor will never get to the supervision loop, but instead is
+			// going to terminate all started children and abort the bootstrap of
+			// the supervision tree.
+			panic(fmt.Errorf("PanicStartWorker %s", name))
 		})
 	return cspec
 }